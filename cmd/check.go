@@ -16,7 +16,11 @@ var checkCmd = &cobra.Command{
 	Use:   "check [file]",
 	Short: "Check configuration file for errors",
 	Long: `Checks a configuration file for errors.  If no filename is given,
-it will check the default configuration file.`,
+it will check the default configuration file.
+
+In addition to checking the structure of the file, this will compile every
+style and template, and try out every template with example data for the
+module, to catch problems that would otherwise only show up in your prompt.`,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		log.SetVerbose(true)
@@ -39,7 +43,7 @@ it will check the default configuration file.`,
 			os.Exit(1)
 		}
 
-		err = config.ValidateConfiguration(contents)
+		err = config.ValidateConfigurationFile(configFile, contents)
 		if err != nil {
 			log.Error(err.Error())
 			os.Exit(1)
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/jwalton/gchalk"
+	"github.com/jwalton/kitsch/internal/kitsch/daemon"
+	"github.com/jwalton/kitsch/internal/kitsch/env"
+	"github.com/jwalton/kitsch/internal/kitsch/log"
+	"github.com/jwalton/kitsch/internal/kitsch/modules"
+	"github.com/jwalton/kitsch/internal/perf"
+	"github.com/spf13/cobra"
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run a background process which renders prompts",
+	Long: heredoc.Doc(`
+		Run a background process which keeps your configuration loaded in memory,
+		and renders prompts on behalf of "` + programName + ` prompt".  This avoids
+		reading and parsing your configuration every time the prompt is shown.
+
+		If the daemon isn't running, "` + programName + ` prompt" will render the
+		prompt itself.  The daemon will automatically reload your configuration
+		when the configuration file changes.
+
+		Example:
+
+		  # Start the daemon in the background
+		  ` + programName + ` daemon &
+	`),
+	Run: func(cmd *cobra.Command, args []string) {
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		if verbose {
+			log.SetVerbose(true)
+		}
+
+		setupPromptColorLevel()
+
+		server := newPromptDaemon()
+		_, err := server.getRenderer()
+		if err != nil {
+			log.Error("Error loading configuration:", err)
+			os.Exit(1)
+		}
+
+		socketPath := daemon.SocketPath(userConfigDir)
+		listener, err := daemon.Listen(socketPath)
+		if err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
+
+		// Remove the socket when we're asked to exit.
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-signals
+			listener.Close()
+		}()
+
+		log.Info("Listening on " + socketPath)
+		err = daemon.Serve(listener, server.handle)
+		if err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// promptDaemon renders prompts on behalf of `kitsch prompt`.
+type promptDaemon struct {
+	mutex sync.Mutex
+	// renderer is the renderer for the currently loaded configuration.
+	renderer *promptRenderer
+	// configFiles are the configuration files `renderer` was loaded from,
+	// and the files it might have been loaded from if they existed.
+	configFiles []string
+	// configModTimes are the modification times of each file in `configFiles`
+	// when `renderer` was created.
+	configModTimes []time.Time
+}
+
+// newPromptDaemon creates a new promptDaemon.  The configuration is loaded
+// on the first request.
+func newPromptDaemon() *promptDaemon {
+	return &promptDaemon{}
+}
+
+// handle renders a single prompt.
+func (server *promptDaemon) handle(request daemon.PromptRequest) (string, error) {
+	if request.ConfigFile != cfgFile {
+		return "", errors.New("daemon is using a different configuration file")
+	}
+	if request.ColorLevel != int(gchalk.GetLevel()) {
+		return "", errors.New("daemon is using a different color level")
+	}
+
+	renderer, err := server.getRenderer()
+	if err != nil {
+		return "", err
+	}
+
+	globals := modules.NewGlobals(
+		request.Shell,
+		request.CWD,
+		request.LogicalCWD,
+		request.TerminalWidth,
+		request.Status,
+		request.Jobs,
+		request.PreviousCommandDuration,
+		request.Keymap,
+	)
+	environment := env.NewFromMap(request.Env)
+	renderer = renderer.withBackground(request.Background, environment.Getenv)
+	renderer = renderer.withProjectConfig(globals.CWD)
+	context := renderer.newContext(globals)
+	context.Environment = environment
+
+	return renderer.render(context, request, perf.New(0)), nil
+}
+
+// getRenderer returns the renderer for the current configuration, reloading
+// the configuration if it has changed since it was last loaded.  Project
+// configuration files are read for every request, so don't need to be watched.
+func (server *promptDaemon) getRenderer() (*promptRenderer, error) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if server.renderer != nil &&
+		equalTimes(getModTimes(server.configFiles), server.configModTimes) {
+		return server.renderer, nil
+	}
+
+	if server.renderer != nil {
+		log.Info("Configuration changed, reloading")
+	}
+
+	// Read the mod times of the main configuration files before loading, so
+	// if one changes while we're loading, we'll reload again on the next request.
+	configFiles := []string{cfgFile, defaultConfigFile}
+	modTimes := getModTimes(configFiles)
+
+	// Use the same file cache as `kitsch prompt`, so results from async
+	// refreshes and diagnostics are shared with prompts the client renders
+	// itself when the daemon is slow or not running.
+	renderer, err := newPromptRenderer(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Watch every file the configuration extends, too.
+	extended := renderer.configuration.Files
+	configFiles = append(configFiles, extended...)
+	modTimes = append(modTimes, getModTimes(extended)...)
+
+	server.renderer = renderer
+	server.configFiles = configFiles
+	server.configModTimes = modTimes
+	return renderer, nil
+}
+
+// getModTimes returns the modification time of each file in `files`.  Files
+// which don't exist have a zero time.
+func getModTimes(files []string) []time.Time {
+	result := make([]time.Time, len(files))
+	for index, file := range files {
+		if file == "" {
+			continue
+		}
+		if stat, err := os.Stat(file); err == nil {
+			result[index] = stat.ModTime()
+		}
+	}
+	return result
+}
+
+func equalTimes(a []time.Time, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for index := range a {
+		if !a[index].Equal(b[index]) {
+			return false
+		}
+	}
+	return true
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+}
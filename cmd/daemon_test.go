@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/jwalton/gchalk"
+	"github.com/jwalton/kitsch/internal/kitsch/daemon"
+	"github.com/jwalton/kitsch/internal/kitsch/modules"
+	"github.com/jwalton/kitsch/internal/perf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useTestConfig points the configuration folder at a temporary folder with
+// the given configuration file in it.
+func useTestConfig(t *testing.T, contents string) {
+	oldUserConfigDir, oldCfgFile, oldDefaultConfigFile := userConfigDir, cfgFile, defaultConfigFile
+	t.Cleanup(func() {
+		userConfigDir, cfgFile, defaultConfigFile = oldUserConfigDir, oldCfgFile, oldDefaultConfigFile
+	})
+
+	userConfigDir = t.TempDir()
+	defaultConfigFile = filepath.Join(userConfigDir, "kitsch.yaml")
+	cfgFile = defaultConfigFile
+	require.NoError(t, os.WriteFile(cfgFile, []byte(contents), 0600))
+}
+
+// renderInProcess renders a prompt the same way `kitsch prompt` does when
+// there is no daemon.
+func renderInProcess(t *testing.T, request daemon.PromptRequest) string {
+	renderer, err := newPromptRenderer(nil)
+	require.NoError(t, err)
+	context := renderer.newContext(modules.NewGlobals("", request.CWD, "", 80, 0, 0, 0, ""))
+	return renderer.render(context, request, perf.New(0))
+}
+
+func TestDaemonSharesAsyncResults(t *testing.T) {
+	useTestConfig(t, heredoc.Doc(`
+		prompt:
+		  type: block
+		  modules:
+		    - type: text
+		      text: hello
+		    - type: text
+		      text: world
+		      async: true
+		      asyncPlaceholder: "..."
+	`))
+
+	server := newPromptDaemon()
+	request := daemon.PromptRequest{
+		ConfigFile: cfgFile,
+		ColorLevel: int(gchalk.GetLevel()),
+		CWD:        t.TempDir(),
+		Async:      true,
+	}
+
+	// Refresh through the daemon, then render without it.
+	refresh := request
+	refresh.AsyncRefresh = true
+	output, err := server.handle(refresh)
+	require.NoError(t, err)
+	assert.Equal(t, "redraw\n", output)
+	assert.Contains(t, renderInProcess(t, request), "hello world")
+
+	// Refresh without the daemon, then render through it.
+	request.CWD = t.TempDir()
+	refresh.CWD = request.CWD
+	output, err = server.handle(request)
+	require.NoError(t, err)
+	assert.Contains(t, output, "hello ...")
+
+	assert.Equal(t, "redraw\n", renderInProcess(t, refresh))
+	output, err = server.handle(request)
+	require.NoError(t, err)
+	assert.Contains(t, output, "hello world")
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jwalton/kitsch/internal/cache"
+	"github.com/jwalton/kitsch/internal/kitsch/log"
+	"github.com/jwalton/kitsch/internal/kitsch/modules"
+	"github.com/spf13/cobra"
+)
+
+// sessionKeyEnv is the environment variable the init scripts use to identify
+// the current shell session.
+const sessionKeyEnv = "KITSCH_SESSION_KEY"
+
+// diagnosticsCacheKey returns the key used to store diagnostics for the given
+// prompt in the given shell session.
+func diagnosticsCacheKey(sessionKey string, prompt string) string {
+	return "diagnostics:" + sessionKey + ":" + prompt
+}
+
+// diagnosticsCache returns the cache used to store diagnostics.  This is always
+// a file cache, even in the daemon, so `kitsch diagnostics` can read it.
+func diagnosticsCache() cache.Cache {
+	return cache.NewFileCache(filepath.Join(userConfigDir, "cache"))
+}
+
+// saveDiagnostics stores the problems found while rendering the given prompt,
+// so they can be displayed by `kitsch diagnostics`.
+func saveDiagnostics(context *modules.Context, prompt string) {
+	sessionKey := context.Environment.Getenv(sessionKeyEnv)
+	if sessionKey == "" {
+		return
+	}
+
+	key := diagnosticsCacheKey(sessionKey, prompt)
+	diagnostics := context.Diagnostics()
+	if len(diagnostics) == 0 {
+		diagnosticsCache().Delete(key)
+		return
+	}
+
+	data, err := json.Marshal(diagnostics)
+	if err != nil {
+		return
+	}
+	diagnosticsCache().Set(key, data)
+}
+
+// loadDiagnostics loads the problems found the last time the given prompt was
+// rendered in the given shell session.
+func loadDiagnostics(sessionKey string, prompt string) []modules.Diagnostic {
+	data := diagnosticsCache().Get(diagnosticsCacheKey(sessionKey, prompt))
+	if data == nil {
+		return nil
+	}
+
+	var diagnostics []modules.Diagnostic
+	err := json.Unmarshal(data, &diagnostics)
+	if err != nil {
+		return nil
+	}
+	return diagnostics
+}
+
+var diagnosticsCmd = &cobra.Command{
+	Use:   "diagnostics",
+	Short: "Show problems found while rendering the prompt",
+	Long: `Shows any problems, such as modules which timed out or templates which
+could not be executed, found the last time the prompt was rendered in the
+current shell.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		sessionKey := os.Getenv(sessionKeyEnv)
+		if sessionKey == "" {
+			log.Error(sessionKeyEnv + " is not set.  Make sure " + programName + " is set up in your shell.")
+			os.Exit(1)
+		}
+
+		prompt := loadDiagnostics(sessionKey, "prompt")
+		rightPrompt := loadDiagnostics(sessionKey, "rightPrompt")
+
+		if len(prompt) == 0 && len(rightPrompt) == 0 {
+			fmt.Println("No problems found rendering the prompt.")
+			return
+		}
+
+		if len(prompt) > 0 {
+			fmt.Println("Prompt:")
+			for _, diagnostic := range prompt {
+				fmt.Println("  " + diagnostic.String())
+			}
+		}
+		if len(rightPrompt) > 0 {
+			fmt.Println("Right prompt:")
+			for _, diagnostic := range rightPrompt {
+				fmt.Println("  " + diagnostic.String())
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diagnosticsCmd)
+}
@@ -0,0 +1,282 @@
+package cmd
+
+import (
+	"encoding"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/jwalton/gchalk"
+	"github.com/jwalton/kitsch/internal/kitsch/modules"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain",
+	Short: "Show how each module in the prompt was rendered",
+	Long: heredoc.Doc(`
+		Renders the prompt once, and then shows every module in the prompt along
+		with whether its conditions matched, whether it timed out, the default
+		text and data it produced, the style that was applied to it, and the
+		final text it rendered.
+	`),
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs, _ := cmd.Flags().GetInt("jobs")
+		status, _ := cmd.Flags().GetInt("status")
+		terminalWidth, _ := cmd.Flags().GetInt("terminal-width")
+		keymap, _ := cmd.Flags().GetString("keymap")
+		cmdDuration, _ := cmd.Flags().GetInt64("cmd-duration")
+		right, _ := cmd.Flags().GetBool("right")
+		background, _ := cmd.Flags().GetString("background")
+		if background == "" {
+			background = os.Getenv("KITSCH_BACKGROUND")
+		}
+
+		var renderer *promptRenderer
+		var err error
+		if cfgFile != "" {
+			// If we were asked to use a specific configuration, don't fall
+			// back to the installed configuration if it can't be loaded.
+			renderer, err = newPromptRendererFromFile(cfgFile, nil)
+		} else {
+			renderer, err = newPromptRenderer(nil)
+		}
+		if err != nil {
+			fmt.Println(gchalk.Red("Error parsing configuration: ", err.Error()))
+			os.Exit(1)
+		}
+
+		globals := modules.NewGlobals("", "", "", terminalWidth, status, jobs, cmdDuration, keymap)
+		renderer = renderer.withBackground(background, os.Getenv)
+		renderer = renderer.withProjectConfig(globals.CWD)
+		context := renderer.newContext(globals)
+
+		root := renderer.configuration.Prompt
+		if right {
+			root = renderer.configuration.RightPrompt
+		}
+		if root.Module == nil {
+			fmt.Println("Nothing to explain - prompt is empty.")
+			return
+		}
+
+		explanation, text := modules.ExplainPrompt(context, root)
+		printExplanation(explanation, 0)
+
+		fmt.Println()
+		fmt.Println(gchalk.Bold("Prompt:"))
+		fmt.Println(text)
+
+		diagnostics := context.Diagnostics()
+		if len(diagnostics) > 0 {
+			fmt.Println()
+			fmt.Println(gchalk.Bold("Problems:"))
+			for _, diagnostic := range diagnostics {
+				fmt.Println("  " + diagnostic.String())
+			}
+		}
+	},
+}
+
+// printExplanation prints an explanation of how a module was rendered, and
+// all of its children.
+func printExplanation(explanation *modules.Explanation, indent int) {
+	prefix := strings.Repeat(" ", indent)
+	detailPrefix := prefix + "  "
+
+	name := explanation.Type
+	if explanation.ID != "" {
+		name = name + "#" + explanation.ID
+	}
+	fmt.Printf("%s%s (%d:%d) - %s\n",
+		prefix,
+		gchalk.Bold(name),
+		explanation.Line,
+		explanation.Column,
+		explanation.Duration,
+	)
+
+	switch {
+	case !explanation.HasConditions:
+		fmt.Println(detailPrefix + "conditions: none")
+	case explanation.ConditionsMatched:
+		fmt.Println(detailPrefix + "conditions: " + gchalk.Green("matched"))
+	default:
+		fmt.Println(detailPrefix + "conditions: " + gchalk.Yellow("did not match"))
+		return
+	}
+
+	if explanation.TimedOut {
+		fmt.Println(detailPrefix + gchalk.Red("timed out"))
+		return
+	}
+
+	if explanation.Async {
+		fmt.Println(detailPrefix + "async: rendered from cache")
+	} else {
+		fmt.Printf("%sdefault text: %q\n", detailPrefix, explanation.DefaultText)
+	}
+
+	// Blocks have children, which we print below, so no need to dump all
+	// their data.
+	if explanation.Data != nil && len(explanation.Children) == 0 {
+		data, err := dataToYAML(explanation.Data)
+		if err == nil {
+			fmt.Println(detailPrefix + "data:")
+			for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+				fmt.Println(detailPrefix + "  " + line)
+			}
+		}
+	}
+
+	fmt.Printf("%sstyle: %q\n", detailPrefix, explanation.Style)
+	fmt.Printf("%stext: %q\n", detailPrefix, explanation.Text)
+
+	for _, child := range explanation.Children {
+		printExplanation(child, indent+4)
+	}
+}
+
+// dataToYAML converts template data to YAML.  Struct fields are written with
+// their Go field names (ignoring any json or yaml tags), since those are the
+// names used to access them from a template (e.g. `.Data.Ahead`).
+func dataToYAML(data interface{}) ([]byte, error) {
+	node, err := templateDataNode(reflect.ValueOf(data))
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(node)
+}
+
+// templateDataNode converts a value into a YAML node, using Go field names for
+// the fields of structs.
+func templateDataNode(value reflect.Value) (*yaml.Node, error) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Invalid:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+
+	case reflect.Struct:
+		if _, ok := value.Interface().(time.Time); ok {
+			break
+		}
+		if _, ok := value.Interface().(encoding.TextMarshaler); ok {
+			break
+		}
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		err := addStructFields(node, value)
+		if err != nil {
+			return nil, err
+		}
+		return node, nil
+
+	case reflect.Map:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, key := range keys {
+			child, err := templateDataNode(value.MapIndex(key))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: fmt.Sprint(key.Interface())},
+				child,
+			)
+		}
+		return node, nil
+
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+		}
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for index := 0; index < value.Len(); index++ {
+			child, err := templateDataNode(value.Index(index))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		return node, nil
+
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		// These can't be displayed.
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+
+	node := &yaml.Node{}
+	err := node.Encode(value.Interface())
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// addStructFields adds each exported field in the given struct to a YAML
+// mapping node.  Fields of embedded structs are added as if they were fields
+// of the outer struct, since that's how templates access them.
+func addStructFields(node *yaml.Node, value reflect.Value) error {
+	valueType := value.Type()
+	for index := 0; index < valueType.NumField(); index++ {
+		field := valueType.Field(index)
+		fieldValue := value.Field(index)
+
+		if field.Anonymous {
+			embedded := fieldValue
+			if embedded.Kind() == reflect.Ptr {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				err := addStructFields(node, embedded)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if field.PkgPath != "" {
+			// Unexported field.
+			continue
+		}
+
+		child, err := templateDataNode(fieldValue)
+		if err != nil {
+			return err
+		}
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: field.Name},
+			child,
+		)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+	explainCmd.Flags().Bool("right", false, "Explain the right prompt instead of the prompt")
+	explainCmd.Flags().StringP("keymap", "k", "", "The keymap of fish/zsh")
+	explainCmd.Flags().IntP("jobs", "j", 0, "The number of currently running jobs")
+	explainCmd.Flags().IntP("status", "s", 0, "The status code of the previously run command")
+	explainCmd.Flags().Int64P("cmd-duration", "d", 0, "The execution duration of the last command, in milliseconds")
+	explainCmd.Flags().Int("terminal-width", 0, "The width of the terminal")
+	explainCmd.Flags().String("background", "", "The terminal's background color, used to pick light or dark colors (defaults to $KITSCH_BACKGROUND)")
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEmbeddedData struct {
+	Branch string `json:"branch"`
+}
+
+type testTemplateData struct {
+	testEmbeddedData
+	Ahead   int               `json:"ahead"`
+	Files   []string          `json:"files,omitempty"`
+	Labels  map[string]string `yaml:"labels"`
+	Next    *testTemplateData
+	private int
+}
+
+func TestDataToYAML(t *testing.T) {
+	data := testTemplateData{
+		testEmbeddedData: testEmbeddedData{Branch: "main"},
+		Ahead:            2,
+		Labels:           map[string]string{"b": "2", "a": "1"},
+	}
+
+	result, err := dataToYAML(data)
+	require.NoError(t, err)
+	assert.Equal(t, heredoc.Doc(`
+		Branch: main
+		Ahead: 2
+		Files: null
+		Labels:
+		    a: "1"
+		    b: "2"
+		Next: null
+	`), string(result))
+
+	result, err = dataToYAML(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "null\n", string(result))
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/jwalton/kitsch/internal/kitsch/log"
+	"github.com/jwalton/kitsch/internal/kitsch/starship"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import configuration from another prompt",
+}
+
+var importStarshipCmd = &cobra.Command{
+	Use:   "starship <file>",
+	Short: "Convert a starship configuration file",
+	Long: heredoc.Doc(`
+		Converts a starship configuration file (usually ~/.config/starship.toml)
+		into a ` + programName + ` configuration file, and prints the result.
+		Anything that can't be converted will be reported as a warning.
+
+		Example:
+
+		  ` + programName + ` import starship ~/.config/starship.toml > ./kitsch.yaml
+	`),
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		log.SetVerbose(true)
+
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			log.Error("Could not read " + args[0] + ": " + err.Error())
+			os.Exit(1)
+		}
+
+		result, warnings, err := starship.Convert(data)
+		if err != nil {
+			log.Error("Could not convert " + args[0] + ": " + err.Error())
+			os.Exit(1)
+		}
+
+		for _, warning := range warnings {
+			log.Warn(warning)
+		}
+
+		fmt.Print(string(result))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.AddCommand(importStarshipCmd)
+}
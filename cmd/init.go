@@ -38,7 +38,15 @@ var initCmd = &cobra.Command{
 
 			fmt.Println(shortScript)
 		} else {
-			script, err := initscripts.InitScript(shell, cfgFile)
+			options := initscripts.Options{}
+			configuration, _ := readConfig()
+			if configuration != nil {
+				options.TransientPrompt = configuration.TransientPrompt.Module != nil
+				options.ContinuationPrompt = configuration.ContinuationPrompt.Module != nil
+				options.Async = configuration.Prompt.HasAsyncModules() || configuration.RightPrompt.HasAsyncModules()
+			}
+
+			script, err := initscripts.InitScript(shell, cfgFile, options)
 			if err != nil {
 				cmd.PrintErrln(err.Error())
 				os.Exit(1)
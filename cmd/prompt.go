@@ -2,20 +2,29 @@ package cmd
 
 import (
 	"fmt"
+	"image/color"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/jwalton/gchalk"
 	"github.com/jwalton/go-supportscolor"
+	"github.com/jwalton/kitsch/internal/cache"
+	"github.com/jwalton/kitsch/internal/fileutils"
+	"github.com/jwalton/kitsch/internal/kitsch/config"
+	"github.com/jwalton/kitsch/internal/kitsch/daemon"
 	"github.com/jwalton/kitsch/internal/kitsch/log"
 	"github.com/jwalton/kitsch/internal/kitsch/modules"
 	"github.com/jwalton/kitsch/internal/kitsch/styling"
+	"github.com/jwalton/kitsch/internal/kitsch/theme"
 	"github.com/jwalton/kitsch/internal/perf"
 	"github.com/jwalton/kitsch/internal/shellprompt"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var promptCmd = &cobra.Command{
@@ -24,8 +33,6 @@ var promptCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		performance := perf.New(4)
 
-		cacheDir := filepath.Join(userConfigDir, "cache")
-
 		jobs, _ := cmd.Flags().GetInt("jobs")
 		status, _ := cmd.Flags().GetInt("status")
 		terminalWidth, _ := cmd.Flags().GetInt("terminal-width")
@@ -35,6 +42,13 @@ var promptCmd = &cobra.Command{
 		demo, _ := cmd.Flags().GetString("demo")
 		cwd, _ := cmd.Flags().GetString("path")
 		logicalCWD, _ := cmd.Flags().GetString("logical-path")
+		right, _ := cmd.Flags().GetBool("right")
+		transient, _ := cmd.Flags().GetBool("transient")
+		continuation, _ := cmd.Flags().GetBool("continuation")
+		async, _ := cmd.Flags().GetBool("async")
+		asyncRefresh, _ := cmd.Flags().GetBool("async-refresh")
+		noDaemon, _ := cmd.Flags().GetBool("no-daemon")
+		background, _ := cmd.Flags().GetString("background")
 
 		verbose, _ := cmd.Flags().GetBool("verbose")
 		if verbose {
@@ -47,40 +61,54 @@ var promptCmd = &cobra.Command{
 			cmdDuration, _ = strconv.ParseInt(cmdDurationStr, 10, 64)
 		}
 
-		if runtime.GOOS == "windows" {
-			// Ugly hack - always enable colors on Windows.  The problem here is that
-			// on Windows, we're not running in the shell directly, so stdout isn't
-			// the TTY, and we can't enable colors in the OS if they aren't supported.
-			// In Windows Terminal, this isn't an issue. We should try to enable color
-			// in the top level of the setup script to get around this...
-			gchalk.SetLevel(gchalk.LevelAnsi16m)
-			gchalk.Stderr.SetLevel(gchalk.LevelAnsi16m)
-		} else {
-			// Because the prompt is shown from the shell, when it is run, it
-			// will not be in a TTY.  Disable TTY detection in gchalk.
-			stdoutFd := os.Stdout.Fd()
-			level := supportscolor.SupportsColor(stdoutFd, supportscolor.IsTTYOption(true))
-			gchalk.SetLevel(level.Level)
-			gchalk.Stderr.SetLevel(level.Level)
+		setupPromptColorLevel()
+
+		request := daemon.PromptRequest{
+			ConfigFile:              cfgFile,
+			ColorLevel:              int(gchalk.GetLevel()),
+			Shell:                   shell,
+			CWD:                     cwd,
+			LogicalCWD:              logicalCWD,
+			TerminalWidth:           terminalWidth,
+			Status:                  status,
+			Jobs:                    jobs,
+			PreviousCommandDuration: cmdDuration,
+			Keymap:                  keymap,
+			Right:                   right,
+			Transient:               transient,
+			Continuation:            continuation,
+			Async:                   async,
+			AsyncRefresh:            asyncRefresh,
+			Background:              background,
 		}
 
 		performance.End("Option parsing")
 
+		// If there's a daemon running, let it render the prompt for us.  We
+		// never use the daemon for demo mode, or when the user is trying to
+		// debug their prompt.
+		if !noDaemon && demo == "" && !perf && !verbose {
+			output, err := renderWithDaemon(request)
+			if err == nil {
+				fmt.Print(output)
+				return
+			}
+		}
+
 		// Read configuration
-		configuration, err := readConfig()
+		renderer, err := newPromptRenderer(nil)
 		if err != nil {
 			println(gchalk.Red("Fatal error parsing configuration: ", err.Error()))
 			fmt.Print("$ ")
 			os.Exit(1)
 		}
 
-		styles := styling.Registry{}
-		styles.AddCustomColors(configuration.Colors)
+		renderer = renderer.withBackground(background, os.Getenv)
 
 		performance.End("Config parsing")
 
 		// Create our context.
-		var context modules.Context
+		var context *modules.Context
 		if demo != "" {
 			demoConfig := &modules.DemoConfig{}
 			err := demoConfig.Load(demo)
@@ -88,33 +116,399 @@ var promptCmd = &cobra.Command{
 				log.Error("Failed to load demo config:", err)
 				os.Exit(1)
 			}
-			context = modules.NewDemoContext(*demoConfig, &styles)
+			demoContext := modules.NewDemoContext(*demoConfig, renderer.styles)
+			context = &demoContext
 		} else {
 			globals := modules.NewGlobals(shell, cwd, logicalCWD, terminalWidth, status, jobs, cmdDuration, keymap)
-			context = modules.NewContext(
-				globals,
-				configuration.ProjectsTypes,
-				time.Duration(configuration.Timeout)*time.Millisecond,
-				time.Duration(configuration.ScanTimeout)*time.Millisecond,
-				cacheDir,
-				&styles,
-			)
+			renderer = renderer.withProjectConfig(globals.CWD)
+			context = renderer.newContext(globals)
 		}
 		performance.End("Context setup")
 
-		// Execute the prompt.
-		moduleResult, promptTest := modules.RenderPrompt(&context, configuration.Prompt)
-		performance.Add("Prompt", moduleResult.Duration, moduleResult.Performance)
+		output := renderer.render(context, request, performance)
 
 		if perf {
 			performance.Print()
 		}
 
-		withEscapes := shellprompt.AddZeroWidthCharacterEscapes(context.Globals.Shell, promptTest)
-		fmt.Print(withEscapes)
+		fmt.Print(output)
 	},
 }
 
+// setupPromptColorLevel sets the color level used to render the prompt.
+func setupPromptColorLevel() {
+	if runtime.GOOS == "windows" {
+		// Ugly hack - always enable colors on Windows.  The problem here is that
+		// on Windows, we're not running in the shell directly, so stdout isn't
+		// the TTY, and we can't enable colors in the OS if they aren't supported.
+		// In Windows Terminal, this isn't an issue. We should try to enable color
+		// in the top level of the setup script to get around this...
+		gchalk.SetLevel(gchalk.LevelAnsi16m)
+		gchalk.Stderr.SetLevel(gchalk.LevelAnsi16m)
+	} else {
+		// Because the prompt is shown from the shell, when it is run, it
+		// will not be in a TTY.  Disable TTY detection in gchalk.
+		stdoutFd := os.Stdout.Fd()
+		level := supportscolor.SupportsColor(stdoutFd, supportscolor.IsTTYOption(true))
+		gchalk.SetLevel(level.Level)
+		gchalk.Stderr.SetLevel(level.Level)
+	}
+}
+
+// renderWithDaemon asks a running daemon to render the prompt.  Returns an
+// error if there is no daemon, or if the daemon could not render the prompt.
+func renderWithDaemon(request daemon.PromptRequest) (string, error) {
+	// Fill in any values the daemon can't work out for itself.
+	if request.CWD == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		request.CWD = cwd
+	}
+
+	if request.TerminalWidth <= 0 {
+		width, _, err := term.GetSize(int(os.Stdout.Fd()))
+		if err != nil {
+			return "", err
+		}
+		request.TerminalWidth = width
+	}
+
+	request.Env = make(map[string]string)
+	for _, entry := range os.Environ() {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) == 2 {
+			request.Env[parts[0]] = parts[1]
+		}
+	}
+
+	return daemon.Render(daemon.SocketPath(userConfigDir), request)
+}
+
+// promptRenderer holds everything needed to render a prompt that doesn't change
+// from one prompt to the next.
+type promptRenderer struct {
+	configuration *config.Config
+	// theme is the terminal theme `styles` was created for.
+	theme theme.Theme
+	// styles is the style registry for `theme`.
+	styles *styling.Registry
+	// background is the terminal's background color `styles` was created for.
+	background color.RGBA
+	// themeStyles holds a style registry for every theme and background color,
+	// so switching between them doesn't throw away compiled styles.
+	themeStyles *themeStyleCache
+	// projectConfigs caches the user's configuration merged with each project
+	// configuration.
+	projectConfigs *projectConfigCache
+	// valueCache is the cache to use for rendering prompts.  If nil, each
+	// context will use a file cache in the configuration folder.
+	valueCache cache.Cache
+	// projectConfigWarning is a problem found while loading the project
+	// configuration, to be reported in each new context.
+	projectConfigWarning string
+}
+
+// newPromptRenderer reads the configuration and creates a new promptRenderer.
+func newPromptRenderer(valueCache cache.Cache) (*promptRenderer, error) {
+	configuration, err := readConfig()
+	if err != nil {
+		return nil, err
+	}
+	return newPromptRendererForConfig(configuration, valueCache), nil
+}
+
+// newPromptRendererFromFile creates a new promptRenderer for the given
+// configuration file.  Unlike newPromptRenderer, this returns an error if the
+// file can't be loaded, instead of falling back to another configuration.
+func newPromptRendererFromFile(configFile string, valueCache cache.Cache) (*promptRenderer, error) {
+	configuration, err := readConfigFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+	return newPromptRendererForConfig(configuration, valueCache), nil
+}
+
+// newPromptRendererForConfig creates a new promptRenderer for the given
+// configuration.
+func newPromptRendererForConfig(configuration *config.Config, valueCache cache.Cache) *promptRenderer {
+	themeStyles := newThemeStyleCache(configuration)
+
+	return &promptRenderer{
+		configuration:  configuration,
+		theme:          theme.Unknown,
+		styles:         themeStyles.get(theme.Unknown, color.RGBA{}),
+		themeStyles:    themeStyles,
+		projectConfigs: newProjectConfigCache(),
+		valueCache:     valueCache,
+	}
+}
+
+// themeStyleCache creates and caches a style registry for each terminal theme
+// and background color, using the custom colors from a configuration.
+type themeStyleCache struct {
+	mutex         sync.Mutex
+	configuration *config.Config
+	registries    map[themeStyleKey]*styling.Registry
+}
+
+type themeStyleKey struct {
+	theme      theme.Theme
+	background color.RGBA
+}
+
+// newThemeStyleCache creates a new themeStyleCache for the given configuration.
+func newThemeStyleCache(configuration *config.Config) *themeStyleCache {
+	return &themeStyleCache{
+		configuration: configuration,
+		registries:    map[themeStyleKey]*styling.Registry{},
+	}
+}
+
+// get returns the style registry for the given theme and background color.
+func (styleCache *themeStyleCache) get(t theme.Theme, background color.RGBA) *styling.Registry {
+	styleCache.mutex.Lock()
+	defer styleCache.mutex.Unlock()
+
+	key := themeStyleKey{theme: t, background: background}
+	if styles, ok := styleCache.registries[key]; ok {
+		return styles
+	}
+
+	// Background is used by "fg:auto" for text without a background color.
+	styles := &styling.Registry{Background: background}
+	styles.AddCustomColorsWithFallbacks(styleCache.configuration.Colors.ForTheme(t))
+	styleCache.registries[key] = styles
+	return styles
+}
+
+// withBackground returns a renderer which uses the custom colors for the
+// terminal theme, and which picks "fg:auto" colors that contrast with the
+// terminal's background.  `background` is the terminal's background color
+// (e.g. the response to an OSC 11 query), or "" if it isn't known.  The theme
+// is worked out with `theme.Detect()`, using `getenv` to read the environment.
+func (renderer *promptRenderer) withBackground(background string, getenv func(string) string) *promptRenderer {
+	t := theme.Detect(background, getenv)
+
+	backgroundColor, ok := theme.ParseBackgroundColor(background)
+	if !ok {
+		// Assume black for dark terminals and white for light ones.
+		backgroundColor = color.RGBA{}
+		if t == theme.Light {
+			backgroundColor = color.RGBA{255, 255, 255, 255}
+		}
+	}
+
+	result := *renderer
+	result.theme = t
+	result.background = backgroundColor
+	result.styles = renderer.themeStyles.get(t, backgroundColor)
+	return &result
+}
+
+// projectConfigCache caches the user's configuration merged with each
+// project configuration, keyed by the path to the project configuration.
+type projectConfigCache struct {
+	mutex   sync.Mutex
+	entries map[string]*projectConfigCacheEntry
+}
+
+// projectConfigCacheEntry is a merged configuration stored in a
+// projectConfigCache.
+type projectConfigCacheEntry struct {
+	// hash is the hash of the project configuration and the files it extends
+	// when `configuration` was created.
+	hash          string
+	configuration *config.Config
+	themeStyles   *themeStyleCache
+}
+
+// newProjectConfigCache creates a new, empty projectConfigCache.
+func newProjectConfigCache() *projectConfigCache {
+	return &projectConfigCache{entries: map[string]*projectConfigCacheEntry{}}
+}
+
+// get returns the merged configuration for the given project configuration,
+// if the project configuration hasn't changed since it was cached.
+func (projectConfigs *projectConfigCache) get(projectConfig *config.ProjectConfig) (*projectConfigCacheEntry, bool) {
+	projectConfigs.mutex.Lock()
+	defer projectConfigs.mutex.Unlock()
+
+	entry, ok := projectConfigs.entries[projectConfig.File]
+	if !ok || entry.hash != projectConfig.Hash {
+		return nil, false
+	}
+	return entry, true
+}
+
+// set stores the merged configuration for the given project configuration,
+// and returns the new cache entry.
+func (projectConfigs *projectConfigCache) set(
+	projectConfig *config.ProjectConfig,
+	configuration *config.Config,
+) *projectConfigCacheEntry {
+	entry := &projectConfigCacheEntry{
+		hash:          projectConfig.Hash,
+		configuration: configuration,
+		themeStyles:   newThemeStyleCache(configuration),
+	}
+
+	projectConfigs.mutex.Lock()
+	defer projectConfigs.mutex.Unlock()
+	projectConfigs.entries[projectConfig.File] = entry
+	return entry
+}
+
+// withProjectConfig returns a renderer for rendering prompts in the given
+// directory.  If there is a project configuration file in the directory or
+// one of its ancestors, and the user has trusted it, the returned renderer
+// will use the project configuration merged over the user's configuration.
+// Otherwise this returns a renderer which uses the user's configuration.
+func (renderer *promptRenderer) withProjectConfig(cwd string) *promptRenderer {
+	projectConfigFile := config.FindProjectConfig(fileutils.NewDirectory(cwd, 0))
+	if projectConfigFile == "" {
+		return renderer
+	}
+
+	result := *renderer
+
+	projectConfig, err := config.ReadProjectConfig(projectConfigFile)
+	if err != nil {
+		result.projectConfigWarning = "Unable to read project configuration " + projectConfigFile + ": " + err.Error()
+		return &result
+	}
+
+	trustStore, err := config.LoadTrustStore(trustStoreFile())
+	if err != nil {
+		result.projectConfigWarning = "Unable to load trusted project configurations: " + err.Error()
+		return &result
+	}
+	if !trustStore.IsTrusted(projectConfig) {
+		result.projectConfigWarning = "Ignoring untrusted project configuration " + projectConfigFile +
+			`.  Run "` + programName + ` trust" to use it.`
+		return &result
+	}
+
+	// The daemon renders many prompts in the same project, so reuse the
+	// merged configuration and its compiled styles until the project
+	// configuration changes.
+	cached, ok := renderer.projectConfigs.get(projectConfig)
+	if !ok {
+		configuration, err := renderer.configuration.WithProjectConfig(projectConfig)
+		if err != nil {
+			result.projectConfigWarning = "Error loading project configuration " + projectConfigFile + ": " + err.Error()
+			return &result
+		}
+		cached = renderer.projectConfigs.set(projectConfig, configuration)
+	}
+
+	result.configuration = cached.configuration
+	result.themeStyles = cached.themeStyles
+	result.styles = result.themeStyles.get(renderer.theme, renderer.background)
+	return &result
+}
+
+// newContext creates a new context for rendering a prompt.
+func (renderer *promptRenderer) newContext(globals modules.Globals) *modules.Context {
+	configuration := renderer.configuration
+
+	context := modules.NewContext(
+		globals,
+		configuration.ProjectsTypes,
+		time.Duration(configuration.Timeout)*time.Millisecond,
+		time.Duration(configuration.ScanTimeout)*time.Millisecond,
+		filepath.Join(userConfigDir, "cache"),
+		renderer.styles,
+	)
+	if renderer.valueCache != nil {
+		context.ValueCache = renderer.valueCache
+	}
+	if renderer.projectConfigWarning != "" {
+		context.AddDiagnostic(nil, modules.DiagnosticConfig, renderer.projectConfigWarning)
+	}
+
+	return &context
+}
+
+// render renders the prompt requested by `request`, and returns the text to
+// print for the shell.
+func (renderer *promptRenderer) render(
+	context *modules.Context,
+	request daemon.PromptRequest,
+	performance *perf.Performance,
+) string {
+	configuration := renderer.configuration
+
+	if request.AsyncRefresh {
+		// Refresh async modules, and let the shell know if it needs to redraw
+		// the prompt.
+		changed := modules.RefreshAsyncModules(context, configuration.Prompt)
+		changed = modules.RefreshAsyncModules(context, configuration.RightPrompt) || changed
+		if changed {
+			return "redraw\n"
+		}
+		return ""
+	}
+
+	context.Async = request.Async
+
+	prompt := configuration.Prompt
+	rightPrompt := configuration.RightPrompt
+	if request.Transient {
+		// The transient prompt replaces the prompt, and there is no right
+		// prompt in transient mode.
+		context.Globals.Transient = true
+		if configuration.TransientPrompt.Module != nil {
+			prompt = configuration.TransientPrompt
+		}
+		rightPrompt = modules.ModuleWrapper{}
+	} else if request.Continuation {
+		// There is no right prompt for continuation lines.
+		prompt = configuration.ContinuationPrompt
+		rightPrompt = modules.ModuleWrapper{}
+	}
+
+	shell := context.Globals.Shell
+	hasRightPrompt := rightPrompt.Module != nil
+	saveProblems := !request.Transient && !request.Continuation
+
+	var output string
+	switch {
+	case prompt.Module == nil:
+		// Nothing to render (e.g. there's no continuation prompt configured).
+	case request.Right:
+		// Execute the right prompt.
+		if hasRightPrompt {
+			moduleResult, rightPromptText := modules.RenderPrompt(context, rightPrompt)
+			performance.Add("Right prompt", moduleResult.Duration, moduleResult.Performance)
+			output = shellprompt.AddZeroWidthCharacterEscapes(shell, rightPromptText)
+			if saveProblems {
+				saveDiagnostics(context, "rightPrompt")
+			}
+		}
+	default:
+		// Execute the prompt.
+		moduleResult, promptText := modules.RenderPrompt(context, prompt)
+		performance.Add("Prompt", moduleResult.Duration, moduleResult.Performance)
+		output = shellprompt.AddZeroWidthCharacterEscapes(shell, promptText)
+
+		// If the shell doesn't have a right prompt, draw the right prompt
+		// as part of the prompt.
+		if hasRightPrompt && !shellprompt.SupportsRightPrompt(shell) {
+			moduleResult, rightPromptText := modules.RenderPrompt(context, rightPrompt)
+			performance.Add("Right prompt", moduleResult.Duration, moduleResult.Performance)
+			output = shellprompt.AddRightPrompt(shell, output, rightPromptText, context.Globals.TerminalWidth)
+		}
+
+		if saveProblems {
+			saveDiagnostics(context, "prompt")
+		}
+	}
+
+	return output
+}
+
 func init() {
 	rootCmd.AddCommand(promptCmd)
 	promptCmd.Flags().String("shell", "", "The type of shell")
@@ -125,6 +519,13 @@ func init() {
 	promptCmd.Flags().IntP("jobs", "j", 0, "The number of currently running jobs")
 	promptCmd.Flags().IntP("status", "s", 0, "The status code of the previously run command")
 	promptCmd.Flags().Int("terminal-width", 0, "The width of the terminal")
+	promptCmd.Flags().Bool("right", false, "Show the right prompt instead of the prompt")
+	promptCmd.Flags().Bool("transient", false, "Show the transient prompt instead of the prompt")
+	promptCmd.Flags().Bool("continuation", false, "Show the continuation prompt instead of the prompt")
+	promptCmd.Flags().Bool("async", false, "Render async modules from the cache instead of executing them")
+	promptCmd.Flags().Bool("async-refresh", false, "Execute async modules and update the cache, and print \"redraw\" if the prompt needs to be redrawn")
+	promptCmd.Flags().String("background", "", "The terminal's background color, used to pick light or dark colors (e.g. the response to an OSC 11 query).  Set by the bash, zsh, and fish init scripts - PowerShell doesn't pass this")
+	promptCmd.Flags().Bool("no-daemon", false, "Always render the prompt in-process, even if a daemon is running")
 	promptCmd.Flags().Bool("perf", false, "Print performance information about each module")
 	promptCmd.Flags().Bool("verbose", false, "Print verbose output")
 	promptCmd.Flags().String("demo", "", "If present, "+programName+" will run in demo mode, loading values from the specified file.")
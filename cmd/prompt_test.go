@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/jwalton/kitsch/internal/kitsch/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithProjectConfigCachesConfiguration(t *testing.T) {
+	useTestConfig(t, heredoc.Doc(`
+		prompt:
+		  type: text
+		  text: "$ "
+	`))
+
+	projectDir := t.TempDir()
+	projectConfigFile := filepath.Join(projectDir, config.ProjectConfigFile)
+	writeProjectConfig := func(contents string) {
+		require.NoError(t, os.WriteFile(projectConfigFile, []byte(contents), 0600))
+		projectConfig, err := config.ReadProjectConfig(projectConfigFile)
+		require.NoError(t, err)
+		trustStore := loadTrustStore()
+		trustStore.Trust(projectConfig)
+		require.NoError(t, trustStore.Save())
+	}
+	writeProjectConfig("colors:\n  $fg: red\n")
+
+	renderer, err := newPromptRenderer(nil)
+	require.NoError(t, err)
+
+	first := renderer.withProjectConfig(projectDir)
+	assert.Equal(t, "", first.projectConfigWarning)
+	assert.Equal(t, "red", first.configuration.Colors.Default["$fg"].Truecolor)
+
+	// Rendering another prompt in the same project should reuse the
+	// configuration and the compiled styles.
+	second := renderer.withProjectConfig(projectDir)
+	assert.Same(t, first.configuration, second.configuration)
+	assert.Same(t, first.styles, second.styles)
+
+	// Changing the project configuration should load it again.
+	writeProjectConfig("colors:\n  $fg: blue\n")
+	third := renderer.withProjectConfig(projectDir)
+	assert.NotSame(t, first.configuration, third.configuration)
+	assert.Equal(t, "blue", third.configuration.Colors.Default["$fg"].Truecolor)
+}
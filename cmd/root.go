@@ -84,14 +84,33 @@ func readConfig() (*config.Config, error) {
 		}
 	}
 
-	// Merge in default project types.
+	mergeDefaultProjectTypes(configuration)
+
+	return configuration, err
+}
+
+// readConfigFile reads the given configuration file.  Unlike readConfig(),
+// this returns an error if the file can't be loaded, instead of falling back
+// to another configuration.
+func readConfigFile(file string) (*config.Config, error) {
+	configuration, err := config.LoadConfigFromFile(file, false)
+	if err != nil {
+		return nil, err
+	}
+
+	mergeDefaultProjectTypes(configuration)
+
+	return configuration, nil
+}
+
+// mergeDefaultProjectTypes merges the default project types into the given
+// configuration.
+func mergeDefaultProjectTypes(configuration *config.Config) {
 	configuration.ProjectsTypes = projects.MergeProjectTypes(
 		configuration.ProjectsTypes,
 		projects.DefaultProjectTypes,
 		true,
 	)
-
-	return configuration, err
 }
 
 // getConfigFolder returns the folder that contains configuration
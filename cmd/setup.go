@@ -27,6 +27,8 @@ var setupCmd = &cobra.Command{
 				shell = "zsh"
 			} else if strings.HasSuffix(shellType, "/bash") {
 				shell = "bash"
+			} else if strings.HasSuffix(shellType, "/fish") {
+				shell = "fish"
 			} else {
 				shell = "unknown"
 			}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/jwalton/gchalk"
+	"github.com/jwalton/kitsch/internal/cache"
+	"github.com/jwalton/kitsch/internal/kitsch/log"
+	"github.com/jwalton/kitsch/internal/kitsch/modules"
+	"github.com/spf13/cobra"
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show what the prompt would look like",
+	Long: heredoc.Doc(`
+		Renders the prompt and prints it to the terminal.  This can be used to
+		preview a configuration file without installing it, or to see what a
+		prompt looks like in different situations.
+
+		Examples:
+
+		  # Preview a configuration file in the current directory
+		  ` + programName + ` show --config ./config.yaml --dry-run
+
+		  # Preview how the prompt looks after a failed command
+		  ` + programName + ` show --config ./config.yaml --status 1
+
+		  # Preview a configuration file using a demo file
+		  ` + programName + ` show --config ./config.yaml --demo ./demo.yaml
+	`),
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		width, _ := cmd.Flags().GetInt("width")
+		status, _ := cmd.Flags().GetInt("status")
+		jobs, _ := cmd.Flags().GetInt("jobs")
+		keymap, _ := cmd.Flags().GetString("keymap")
+		demo, _ := cmd.Flags().GetString("demo")
+		right, _ := cmd.Flags().GetBool("right")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		background, _ := cmd.Flags().GetString("background")
+		if background == "" {
+			background = os.Getenv("KITSCH_BACKGROUND")
+		}
+
+		var valueCache cache.Cache
+		if dryRun {
+			// Don't write anything to the cache folder.
+			valueCache = cache.NewMemoryCache()
+		}
+
+		var renderer *promptRenderer
+		var err error
+		if cfgFile != "" {
+			// If we were asked to use a specific configuration, don't fall
+			// back to the installed configuration if it can't be loaded.
+			renderer, err = newPromptRendererFromFile(cfgFile, valueCache)
+		} else {
+			renderer, err = newPromptRenderer(valueCache)
+		}
+		if err != nil {
+			fmt.Println(gchalk.Red("Error parsing configuration: ", err.Error()))
+			os.Exit(1)
+		}
+		renderer = renderer.withBackground(background, os.Getenv)
+
+		var context *modules.Context
+		if demo != "" {
+			demoConfig := &modules.DemoConfig{}
+			err := demoConfig.Load(demo)
+			if err != nil {
+				log.Error("Failed to load demo config:", err)
+				os.Exit(1)
+			}
+
+			// Apply any overrides from the command line.
+			if cmd.Flags().Changed("width") {
+				demoConfig.Globals.TerminalWidth = width
+			}
+			if cmd.Flags().Changed("status") {
+				demoConfig.Globals.Status = status
+			}
+			if cmd.Flags().Changed("jobs") {
+				demoConfig.Globals.Jobs = jobs
+			}
+			if cmd.Flags().Changed("keymap") {
+				demoConfig.Globals.Keymap = keymap
+			}
+
+			demoContext := modules.NewDemoContext(*demoConfig, renderer.styles)
+			context = &demoContext
+		} else {
+			globals := modules.NewGlobals("", "", "", width, status, jobs, 0, keymap)
+			renderer = renderer.withProjectConfig(globals.CWD)
+			context = renderer.newContext(globals)
+		}
+
+		prompt := renderer.configuration.Prompt
+		if right {
+			prompt = renderer.configuration.RightPrompt
+		}
+		if prompt.Module == nil {
+			return
+		}
+
+		_, text := modules.RenderPrompt(context, prompt)
+		fmt.Println(text)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(showCmd)
+	showCmd.Flags().Int("width", 0, "The width of the terminal (defaults to the current terminal width)")
+	showCmd.Flags().IntP("status", "s", 0, "The status code of the previously run command")
+	showCmd.Flags().IntP("jobs", "j", 0, "The number of currently running jobs")
+	showCmd.Flags().StringP("keymap", "k", "", "The keymap of fish/zsh")
+	showCmd.Flags().String("demo", "", "If present, load values from the specified demo file instead of the current directory")
+	showCmd.Flags().Bool("right", false, "Show the right prompt instead of the prompt")
+	showCmd.Flags().Bool("dry-run", false, "Don't write anything to the cache while rendering the prompt")
+	showCmd.Flags().String("background", "", "The terminal's background color, used to pick light or dark colors (defaults to $KITSCH_BACKGROUND)")
+}
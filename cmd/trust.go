@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/jwalton/kitsch/internal/fileutils"
+	"github.com/jwalton/kitsch/internal/kitsch/config"
+	"github.com/jwalton/kitsch/internal/kitsch/log"
+	"github.com/spf13/cobra"
+)
+
+// trustStoreFile returns the path to the file which records trusted project
+// configuration files.
+func trustStoreFile() string {
+	return filepath.Join(userConfigDir, "trusted.yaml")
+}
+
+// projectConfigFileFromArgs returns the project configuration file named by
+// the command line arguments.  The argument may be a project configuration
+// file, or a folder to search for one.  If there is no argument, this searches
+// from the current folder.
+func projectConfigFileFromArgs(args []string) string {
+	folder := "."
+	if len(args) > 0 {
+		if stat, err := os.Stat(args[0]); err != nil || !stat.IsDir() {
+			return args[0]
+		}
+		folder = args[0]
+	}
+
+	absFolder, err := filepath.Abs(folder)
+	if err != nil {
+		absFolder = folder
+	}
+
+	configFile := config.FindProjectConfig(fileutils.NewDirectory(absFolder, 0))
+	if configFile == "" {
+		log.Error("No " + config.ProjectConfigFile + " found in " + absFolder + " or any parent folder.")
+		os.Exit(1)
+	}
+	return configFile
+}
+
+// loadTrustStore loads the trust store, or exits if it can't be loaded.
+func loadTrustStore() *config.TrustStore {
+	trustStore, err := config.LoadTrustStore(trustStoreFile())
+	if err != nil {
+		log.Error("Unable to load " + trustStoreFile() + ": " + err.Error())
+		os.Exit(1)
+	}
+	return trustStore
+}
+
+var trustCmd = &cobra.Command{
+	Use:   "trust [file-or-folder]",
+	Short: "Allow a project configuration file to be used",
+	Long: heredoc.Doc(`
+		A project can include a "` + config.ProjectConfigFile + `" file, which will be merged
+		over your configuration whenever you are in the project's folder.  Because
+		a configuration file can run arbitrary commands, project configuration files
+		are ignored until you trust them.
+
+		If the project configuration file or any file it extends changes, you will
+		need to trust it again.
+
+		If no file is given, this will trust the project configuration file for the
+		current folder.
+	`),
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		configFile := projectConfigFileFromArgs(args)
+
+		projectConfig, err := config.ReadProjectConfig(configFile)
+		if err != nil {
+			log.Error("Could not read " + configFile + ": " + err.Error())
+			os.Exit(1)
+		}
+
+		trustStore := loadTrustStore()
+		trustStore.Trust(projectConfig)
+		err = trustStore.Save()
+		if err != nil {
+			log.Error("Unable to save " + trustStoreFile() + ": " + err.Error())
+			os.Exit(1)
+		}
+
+		fmt.Println("Trusted " + configFile)
+	},
+}
+
+var untrustCmd = &cobra.Command{
+	Use:   "untrust [file-or-folder]",
+	Short: "Stop using a project configuration file",
+	Long: heredoc.Doc(`
+		Removes a project configuration file from the list of trusted files.  See
+		"` + programName + ` trust --help" for details.
+
+		If no file is given, this will untrust the project configuration file for
+		the current folder.
+	`),
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		configFile := projectConfigFileFromArgs(args)
+
+		trustStore := loadTrustStore()
+		if !trustStore.Untrust(configFile) {
+			fmt.Println(configFile + " is not trusted")
+			return
+		}
+
+		err := trustStore.Save()
+		if err != nil {
+			log.Error("Unable to save " + trustStoreFile() + ": " + err.Error())
+			os.Exit(1)
+		}
+
+		fmt.Println("Untrusted " + configFile)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(trustCmd)
+	rootCmd.AddCommand(untrustCmd)
+}
@@ -0,0 +1,108 @@
+package ansigradient
+
+import (
+	"image/color"
+
+	"github.com/jwalton/kitsch/internal/colortools"
+)
+
+// blackAndWhite are the colors AutoForeground picks from by default.
+var blackAndWhite = []color.RGBA{
+	{0, 0, 0, 255},
+	{255, 255, 255, 255},
+}
+
+// AutoForeground picks a foreground color for each character which contrasts
+// with that character's background color.
+type AutoForeground struct {
+	// Candidates are the colors to pick from.  If empty, black or white is
+	// used.
+	Candidates []color.RGBA
+	// Algorithm is used to measure contrast.
+	Algorithm colortools.ContrastAlgorithm
+	// Background is the background color to assume for characters which
+	// don't have a background color.
+	Background color.RGBA
+}
+
+// ColorFor returns the candidate color with the most contrast against the
+// given background.  On terminals which don't support truecolor, contrast is
+// measured between the nearest colors in the terminal's palette, since
+// those are the colors that will actually be shown.
+func (auto *AutoForeground) ColorFor(background color.RGBA, level ColorLevel) color.RGBA {
+	candidates := auto.Candidates
+	if len(candidates) == 0 {
+		candidates = blackAndWhite
+	}
+
+	background = paletteColor(level, background)
+
+	best := candidates[0]
+	bestContrast := -1.0
+	for _, candidate := range candidates {
+		contrast := auto.Algorithm.Contrast(paletteColor(level, candidate), background)
+		if contrast > bestContrast {
+			best = candidate
+			bestContrast = contrast
+		}
+	}
+	return best
+}
+
+// generator returns a ColorGenerator which picks foreground colors for the
+// given token.  The background for each character comes from `bgColors` if
+// it will be applied to the token, from the token's own background color
+// otherwise, and from `auto.Background` if the token has no background.
+func (auto *AutoForeground) generator(
+	token gradientToken,
+	bgColors ColorGenerator,
+	level ColorLevel,
+	override bool,
+) ColorGenerator {
+	var background ColorGenerator
+	if bgColors != nil && (override || token.bg == "") {
+		background = bgColors
+	} else if c, ok := colortools.ParseSGRColor(token.bg); ok {
+		background = solidColor(c)
+	} else {
+		background = solidColor(auto.Background)
+	}
+
+	return &contrastGenerator{auto: auto, background: background, level: level}
+}
+
+// contrastGenerator is a ColorGenerator which generates colors that contrast
+// with the colors from another ColorGenerator.
+type contrastGenerator struct {
+	auto       *AutoForeground
+	background ColorGenerator
+	level      ColorLevel
+}
+
+func (generator *contrastGenerator) ColorAt(position float64) color.RGBA {
+	return generator.auto.ColorFor(generator.background.ColorAt(position), generator.level)
+}
+
+// solidColor is a ColorGenerator which always generates the same color.
+type solidColor color.RGBA
+
+func (c solidColor) ColorAt(position float64) color.RGBA {
+	return color.RGBA(c)
+}
+
+// paletteColor returns the color that will be shown for `c` at the given
+// color level.
+func paletteColor(level ColorLevel, c color.RGBA) color.RGBA {
+	switch level {
+	case LevelBasic:
+		code := colortools.NearestAnsi(c)
+		if code >= 90 {
+			return colortools.Ansi16Colors[code-90+8]
+		}
+		return colortools.Ansi16Colors[code-30]
+	case LevelAnsi256:
+		return colortools.Ansi256ToRGBA(colortools.NearestAnsi256(c))
+	default:
+		return c
+	}
+}
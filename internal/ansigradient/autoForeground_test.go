@@ -0,0 +1,87 @@
+package ansigradient
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/jwalton/gchalk"
+	"github.com/jwalton/kitsch/internal/colortools"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	black = color.RGBA{0, 0, 0, 255}
+	white = color.RGBA{255, 255, 255, 255}
+)
+
+func TestAutoForegroundColorFor(t *testing.T) {
+	for _, algorithm := range []colortools.ContrastAlgorithm{colortools.WCAG, colortools.APCA} {
+		auto := &AutoForeground{Algorithm: algorithm}
+		assert.Equal(t, black, auto.ColorFor(white, LevelAnsi16m))
+		assert.Equal(t, white, auto.ColorFor(black, LevelAnsi16m))
+		assert.Equal(t, black, auto.ColorFor(color.RGBA{255, 208, 0, 255}, LevelAnsi16m))
+		assert.Equal(t, white, auto.ColorFor(color.RGBA{0, 0, 255, 255}, LevelAnsi16m))
+	}
+
+	// Should pick from the candidates.
+	dark := color.RGBA{0x33, 0x33, 0x33, 255}
+	light := color.RGBA{0xee, 0xee, 0xee, 255}
+	auto := &AutoForeground{Candidates: []color.RGBA{dark, light}}
+	assert.Equal(t, light, auto.ColorFor(color.RGBA{0x20, 0x20, 0x20, 255}, LevelAnsi16m))
+	assert.Equal(t, dark, auto.ColorFor(color.RGBA{0xf0, 0xe0, 0xc0, 255}, LevelAnsi16m))
+}
+
+func TestAutoForegroundColorForPalette(t *testing.T) {
+	// WCAG and APCA disagree about white or black on this shade of blue.
+	auto := &AutoForeground{Algorithm: colortools.WCAG}
+	blue := color.RGBA{0x20, 0x80, 0xff, 255}
+	assert.Equal(t, black, auto.ColorFor(blue, LevelAnsi16m))
+	auto.Algorithm = colortools.APCA
+	assert.Equal(t, white, auto.ColorFor(blue, LevelAnsi16m))
+
+	// In 16 color mode, this dark teal is shown as brightBlack, so contrast
+	// should be measured against brightBlack.
+	auto.Algorithm = colortools.WCAG
+	teal := color.RGBA{0x00, 0x40, 0x40, 255}
+	assert.Equal(t, white, auto.ColorFor(teal, LevelAnsi16m))
+	assert.Equal(t, black, auto.ColorFor(teal, LevelBasic))
+}
+
+func TestApplyAutoForeground(t *testing.T) {
+	gradient := CSSLinearGradientMust("#000, #fff")
+	auto := &AutoForeground{}
+
+	// Foreground should switch from white to black as the background
+	// gets lighter.
+	result, len := ApplyAutoForegroundRawLen("ABCD", auto, gradient, LevelAnsi16m)
+	assert.Equal(t,
+		"\u001b[38;2;255;255;255m\u001b[48;2;31;31;31mA\u001b[48;2;95;95;95mB"+
+			"\u001b[38;2;0;0;0m\u001b[48;2;159;159;159mC\u001b[48;2;223;223;223mD\u001b[39m\u001b[49m",
+		result,
+	)
+	assert.Equal(t, 4, len)
+
+	// Should use the per-character backgrounds from ApplyGradientsRawLen.
+	background, _ := ApplyGradientsRawLen("ABCD", nil, gradient, LevelAnsi16m)
+	result, len = ApplyAutoForegroundRawLen(background, auto, nil, LevelAnsi16m)
+	assert.Equal(t,
+		"\u001b[48;2;31;31;31m\u001b[38;2;255;255;255mA\u001b[48;2;95;95;95mB"+
+			"\u001b[48;2;159;159;159m\u001b[38;2;0;0;0mC\u001b[48;2;223;223;223mD\u001b[49m\u001b[39m",
+		result,
+	)
+	assert.Equal(t, 4, len)
+}
+
+func TestApplyAutoForegroundPreColoredText(t *testing.T) {
+	g := gchalk.New(gchalk.ForceLevel(gchalk.LevelAnsi16m))
+	auto := &AutoForeground{Background: white}
+
+	// Text with no background uses auto.Background, and text with its own
+	// foreground color is left alone.
+	message := "A" + g.BgBlue("B") + g.Green("C")
+	result, _ := ApplyAutoForegroundRawLen(message, auto, nil, LevelAnsi16m)
+	assert.Equal(t,
+		"\u001b[38;2;0;0;0mA\u001b[44m\u001b[38;2;255;255;255mB\u001b[49m\u001b[32mC\u001b[39m",
+		result,
+	)
+}
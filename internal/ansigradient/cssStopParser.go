@@ -105,7 +105,19 @@ func (parser *cssStopParser) getNextToken() string {
 	str := parser.str
 	tokenStart := parser.index
 
-	for parser.index < len(str) && !parser.isWhitespace(str[parser.index]) && str[parser.index] != ',' {
+	// Whitespace and commas inside a color function like "rgb(0, 0, 0)" are
+	// part of the token.
+	depth := 0
+	for parser.index < len(str) {
+		c := str[parser.index]
+		if depth == 0 && (parser.isWhitespace(c) || c == ',') {
+			break
+		}
+		if c == '(' {
+			depth++
+		} else if c == ')' && depth > 0 {
+			depth--
+		}
 		parser.index++
 	}
 
@@ -113,7 +125,6 @@ func (parser *cssStopParser) getNextToken() string {
 }
 
 func (parser *cssStopParser) parseColor() (c color.RGBA, err error) {
-	// TODO: Add support for `rgb()`, for named colors, and for a color dictionary of custom colors.
 	colorStartIndex := parser.index
 
 	token := parser.getNextToken()
@@ -151,3 +151,24 @@ func TestCssStopParser_BadStops(t *testing.T) {
 		err.Error(),
 	)
 }
+
+func TestCssStopParser_ColorFunctions(t *testing.T) {
+	result, err := parseCSSStops(
+		map[string]string{"$blue": "rgb(0, 0, 255)"},
+		"rgb(255, 0, 0) 10%, hsl(120 100% 50% / 50%) 20px 30px,$blue",
+	)
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		[]gradientStop{
+			{Color: color.RGBA{255, 0, 0, 255}, ColorUnset: false, Offset: 0.1, OffsetType: gradientStopRelative},
+			{Color: color.RGBA{0, 255, 0, 128}, ColorUnset: false, Offset: 20, OffsetType: gradientStopAbsolute},
+			{Color: color.RGBA{0, 255, 0, 128}, ColorUnset: false, Offset: 30, OffsetType: gradientStopAbsolute},
+			{Color: color.RGBA{0, 0, 255, 255}, ColorUnset: false, Offset: 0, OffsetType: gradientStopUnspecified},
+		},
+		result,
+	)
+
+	_, err = parseCSSStops(nil, "rgb(255 0), #fff")
+	assert.EqualError(t, err, `invalid color "rgb(255 0)" at 0`)
+}
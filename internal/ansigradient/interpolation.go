@@ -0,0 +1,204 @@
+package ansigradient
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/jwalton/kitsch/internal/colortools"
+)
+
+// colorSpace is a color space that a gradient can be interpolated in.
+type colorSpace int
+
+const (
+	colorSpaceSRGB colorSpace = iota
+	colorSpaceOKLab
+	colorSpaceOKLCh
+	colorSpaceHSL
+)
+
+var colorSpaces = map[string]colorSpace{
+	"srgb":  colorSpaceSRGB,
+	"oklab": colorSpaceOKLab,
+	"oklch": colorSpaceOKLCh,
+	"hsl":   colorSpaceHSL,
+}
+
+// isPolar returns true if the color space has a hue.
+func (space colorSpace) isPolar() bool {
+	return space == colorSpaceOKLCh || space == colorSpaceHSL
+}
+
+// hueInterpolation is the method used to interpolate between two hues in a
+// polar color space.  See https://www.w3.org/TR/css-color-4/#hue-interpolation.
+type hueInterpolation int
+
+const (
+	hueShorter hueInterpolation = iota
+	hueLonger
+	hueIncreasing
+	hueDecreasing
+)
+
+var hueInterpolations = map[string]hueInterpolation{
+	"shorter":    hueShorter,
+	"longer":     hueLonger,
+	"increasing": hueIncreasing,
+	"decreasing": hueDecreasing,
+}
+
+// interpolation describes how to interpolate between two colors in a gradient.
+// The zero value interpolates in sRGB.
+type interpolation struct {
+	space colorSpace
+	hue   hueInterpolation
+}
+
+// achromaticChroma is the OKLCh chroma below which a color is considered to be
+// a gray, with no meaningful hue.
+const achromaticChroma = 0.0001
+
+// parseInterpolation parses a CSS color interpolation method, like
+// "in oklch longer hue".
+func parseInterpolation(str string) (interpolation, error) {
+	result := interpolation{}
+
+	words := strings.Fields(strings.ToLower(str))
+	if len(words) < 2 || words[0] != "in" {
+		return result, fmt.Errorf("invalid color interpolation method %q", str)
+	}
+
+	space, ok := colorSpaces[words[1]]
+	if !ok {
+		return result, fmt.Errorf("unknown color space %q", words[1])
+	}
+	result.space = space
+
+	switch len(words) {
+	case 2:
+		return result, nil
+	case 4:
+		hue, ok := hueInterpolations[words[2]]
+		if !ok || words[3] != "hue" {
+			return result, fmt.Errorf("invalid hue interpolation method %q", strings.Join(words[2:], " "))
+		}
+		if !space.isPolar() {
+			return result, fmt.Errorf("hue interpolation method can't be used with %q", words[1])
+		}
+		result.hue = hue
+		return result, nil
+	default:
+		return result, fmt.Errorf("invalid color interpolation method %q", str)
+	}
+}
+
+// splitInterpolation splits a leading color interpolation method (e.g.
+// "in oklch,") off of a list of CSS gradient stops.  Returns the interpolation
+// method (or "" if there is none) and the remaining stops.
+func splitInterpolation(css string) (method string, stops string) {
+	trimmed := strings.TrimLeft(css, " \t")
+	if len(trimmed) < 3 || !strings.EqualFold(trimmed[:3], "in ") {
+		return "", css
+	}
+
+	comma := strings.IndexByte(trimmed, ',')
+	if comma == -1 {
+		return trimmed, ""
+	}
+	return trimmed[:comma], trimmed[comma+1:]
+}
+
+// lerp returns the color `s` of the way from `c1` to `c2`.
+func (interp interpolation) lerp(c1 color.RGBA, c2 color.RGBA, s float64) color.RGBA {
+	var result color.RGBA
+
+	switch interp.space {
+	case colorSpaceOKLab:
+		lab1 := colortools.ToOKLab(c1)
+		lab2 := colortools.ToOKLab(c2)
+		result = colortools.OKLab{
+			L: lerpFloat(lab1.L, lab2.L, s),
+			A: lerpFloat(lab1.A, lab2.A, s),
+			B: lerpFloat(lab1.B, lab2.B, s),
+		}.ToRGBA()
+
+	case colorSpaceOKLCh:
+		lch1 := colortools.ToOKLab(c1).ToOKLCh()
+		lch2 := colortools.ToOKLab(c2).ToOKLCh()
+		h1, h2 := lch1.H, lch2.H
+		// Grays have no hue, so use the hue of the other color.
+		if lch1.C < achromaticChroma {
+			h1 = h2
+		}
+		if lch2.C < achromaticChroma {
+			h2 = h1
+		}
+		result = colortools.OKLCh{
+			L: lerpFloat(lch1.L, lch2.L, s),
+			C: lerpFloat(lch1.C, lch2.C, s),
+			H: interp.lerpHue(h1, h2, s),
+		}.ToOKLab().ToRGBA()
+
+	case colorSpaceHSL:
+		hsl1 := colortools.ToHSL(c1)
+		hsl2 := colortools.ToHSL(c2)
+		h1, h2 := hsl1.H, hsl2.H
+		if hsl1.S == 0 {
+			h1 = h2
+		}
+		if hsl2.S == 0 {
+			h2 = h1
+		}
+		result = colortools.HSL{
+			H: interp.lerpHue(h1, h2, s),
+			S: lerpFloat(hsl1.S, hsl2.S, s),
+			L: lerpFloat(hsl1.L, hsl2.L, s),
+		}.ToRGBA()
+
+	default:
+		return lerpColor(c1, c2, s)
+	}
+
+	result.A = lerp(c1.A, c2.A, s)
+	return result
+}
+
+// lerpHue interpolates between two hues, in degrees.
+func (interp interpolation) lerpHue(h1 float64, h2 float64, s float64) float64 {
+	delta := h2 - h1
+
+	switch interp.hue {
+	case hueShorter:
+		if delta > 180 {
+			h1 += 360
+		} else if delta < -180 {
+			h2 += 360
+		}
+	case hueLonger:
+		if delta > 0 && delta < 180 {
+			h1 += 360
+		} else if delta > -180 && delta <= 0 {
+			h2 += 360
+		}
+	case hueIncreasing:
+		if delta < 0 {
+			h2 += 360
+		}
+	case hueDecreasing:
+		if delta > 0 {
+			h1 += 360
+		}
+	}
+
+	hue := math.Mod(lerpFloat(h1, h2, s), 360)
+	if hue < 0 {
+		hue += 360
+	}
+	return hue
+}
+
+func lerpFloat(start float64, end float64, s float64) float64 {
+	return start + s*(end-start)
+}
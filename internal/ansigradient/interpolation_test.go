@@ -0,0 +1,93 @@
+package ansigradient
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinearGradientInterpolation(t *testing.T) {
+	assert.Equal(t,
+		[]color.RGBA{
+			{R: 212, G: 42, B: 0, A: 255},
+			{R: 127, G: 127, B: 0, A: 255},
+			{R: 42, G: 212, B: 0, A: 255},
+		},
+		CSSLinearGradientMust("in srgb, #f00, #0f0").Colors(3),
+		"srgb should be the same as no interpolation method",
+	)
+
+	assert.Equal(t,
+		[]color.RGBA{
+			{R: 244, G: 93, B: 0, A: 255},
+			{R: 208, G: 168, B: 0, A: 255},
+			{R: 135, G: 227, B: 0, A: 255},
+		},
+		CSSLinearGradientMust("in oklab, #f00, #0f0").Colors(3),
+		"oklab should not go brown in the middle",
+	)
+
+	assert.Equal(t,
+		[]color.RGBA{
+			{R: 243, G: 0, B: 96, A: 255},
+			{R: 186, G: 0, B: 194, A: 255},
+			{R: 97, G: 0, B: 253, A: 255},
+		},
+		CSSLinearGradientMust("in oklch, #f00, #00f").Colors(3),
+		"oklch should take the shorter hue by default",
+	)
+
+	assert.Equal(t,
+		[]color.RGBA{
+			{R: 222, G: 72, B: 0, A: 255},
+			{R: 0, G: 147, B: 0, A: 255},
+			{R: 0, G: 107, B: 219, A: 255},
+		},
+		CSSLinearGradientMust("in oklch longer hue, #f00, #00f").Colors(3),
+		"oklch longer hue",
+	)
+
+	assert.Equal(t,
+		[]color.RGBA{
+			{R: 255, G: 170, B: 0, A: 255},
+			{R: 0, G: 255, B: 0, A: 255},
+			{R: 0, G: 170, B: 255, A: 255},
+		},
+		CSSLinearGradientMust("IN HSL LONGER HUE, #f00, #00f").Colors(3),
+		"hsl longer hue",
+	)
+
+	assert.Equal(t,
+		[]color.RGBA{
+			{R: 207, G: 225, B: 255, A: 255},
+			{R: 116, G: 163, B: 255, A: 255},
+			{R: 25, G: 87, B: 255, A: 255},
+		},
+		CSSLinearGradientMust("in oklch, #fff, #00f").Colors(3),
+		"should use the hue of the other color when interpolating from white",
+	)
+
+	// ColorAt and Generator should use the same color space as Colors.
+	gradient := CSSLinearGradientMust("in oklch, #f00 10%, 30%, #00f")
+	colors := gradient.Colors(10)
+	generator := gradient.Generator(10)
+	for index := 0; index < 10; index++ {
+		assert.Equal(t, colors[index], generator.ColorAt(float64(index)+0.5))
+		assert.Equal(t, gradient.ColorAt(10, index), generator.ColorAt(float64(index)))
+	}
+}
+
+func TestLinearGradientInterpolationErrors(t *testing.T) {
+	_, err := CSSLinearGradient("in lab, #f00, #00f")
+	assert.EqualError(t, err, `unknown color space "lab"`)
+
+	_, err = CSSLinearGradient("in oklab longer hue, #f00, #00f")
+	assert.EqualError(t, err, `hue interpolation method can't be used with "oklab"`)
+
+	_, err = CSSLinearGradient("in oklch sideways hue, #f00, #00f")
+	assert.EqualError(t, err, `invalid hue interpolation method "sideways hue"`)
+
+	_, err = CSSLinearGradient("in oklch")
+	assert.EqualError(t, err, "Can't create CSSLinearGradient with no stops")
+}
@@ -3,11 +3,17 @@ package ansigradient
 import (
 	"fmt"
 	"image/color"
+	"math"
 )
 
 // LinearGradient represents a linear gradient.
 type LinearGradient struct {
 	stops []gradientStop
+	// interpolation is the method used to interpolate between stops.
+	interpolation interpolation
+	// repeating is true if the stops should be repeated to fill the length of
+	// the gradient.
+	repeating bool
 }
 
 // CSSLinearGradient constructs a linear gradient from CSS stops.
@@ -35,34 +41,59 @@ type LinearGradient struct {
 //	   CSSLinearGradient("0xf00 0 10px, 30%, 0x00f")
 //
 func CSSLinearGradient(stops string) (Gradient, error) {
-	gradientStops, err := parseCSSStops(nil, stops)
-	if err != nil {
-		return nil, err
-	}
-
-	result := LinearGradient{
-		stops: gradientStops,
-	}
+	return CSSLinearGradientWithMap(nil, stops)
+}
 
-	if len(result.stops) == 0 {
-		return nil, fmt.Errorf("Can't create CSSLinearGradient with no stops")
-	}
+// CSSLinearGradientWithMap creates a gradient, and allows supplying a map of custom colors.
+//
+// The stops may be preceded by a CSS color interpolation method, which
+// controls the color space the gradient is interpolated in:
+//
+//     CSSLinearGradientWithMap(nil, "in oklch longer hue, #f00, #0f0")
+//
+// Supported color spaces are "srgb" (the default), "oklab", "oklch", and
+// "hsl".  Polar color spaces ("oklch" and "hsl") may also specify a hue
+// interpolation method of "shorter" (the default), "longer", "increasing",
+// or "decreasing".
+func CSSLinearGradientWithMap(colorMap map[string]string, stops string) (Gradient, error) {
+	return cssLinearGradient(colorMap, stops, false)
+}
 
-	result.cleanStops()
+// CSSRepeatingLinearGradient constructs a repeating linear gradient from CSS
+// stops, like the CSS `repeating-linear-gradient()` function.  The stops are
+// specified exactly as for CSSLinearGradient, but the pattern they make is
+// repeated from the last stop to fill the length of the string:
+//
+//     CSSRepeatingLinearGradient("#f00, #00f 4px")
+//
+// would create a gradient that goes from red to blue every four characters.
+func CSSRepeatingLinearGradient(stops string) (Gradient, error) {
+	return CSSRepeatingLinearGradientWithMap(nil, stops)
+}
 
-	return result, nil
+// CSSRepeatingLinearGradientWithMap creates a repeating gradient, and allows
+// supplying a map of custom colors.
+func CSSRepeatingLinearGradientWithMap(colorMap map[string]string, stops string) (Gradient, error) {
+	return cssLinearGradient(colorMap, stops, true)
 }
 
-// CSSLinearGradientWithMap creates a gradient, and allows supplying a map of custom colors.
-func CSSLinearGradientWithMap(colorMap map[string]string, stops string) (Gradient, error) {
+func cssLinearGradient(colorMap map[string]string, stops string, repeating bool) (Gradient, error) {
+	result := LinearGradient{repeating: repeating}
+
+	method, stops := splitInterpolation(stops)
+	if method != "" {
+		var err error
+		result.interpolation, err = parseInterpolation(method)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	gradientStops, err := parseCSSStops(colorMap, stops)
 	if err != nil {
 		return nil, err
 	}
-
-	result := LinearGradient{
-		stops: gradientStops,
-	}
+	result.stops = gradientStops
 
 	if len(result.stops) == 0 {
 		return nil, fmt.Errorf("Can't create CSSLinearGradient with no stops")
@@ -122,7 +153,7 @@ func (gradient *LinearGradient) cleanStops() {
 			if lastDefinedColorIndex != i-1 {
 				missingColorCount := float64(i - lastDefinedColorIndex - 1)
 				for missingIndex := lastDefinedColorIndex + 1; missingIndex < i; missingIndex++ {
-					gradient.stops[missingIndex].Color = lerpColor(
+					gradient.stops[missingIndex].Color = gradient.interpolation.lerp(
 						gradient.stops[lastDefinedColorIndex].Color,
 						gradient.stops[i].Color,
 						float64(missingIndex-lastDefinedColorIndex)/(missingColorCount+1),
@@ -226,14 +257,18 @@ func (gradient LinearGradient) Colors(length int) []color.RGBA {
 // Generator returns an object that generates colors for a string of a particular length.
 func (gradient LinearGradient) Generator(length int) ColorGenerator {
 	return &linearGradientColorizer{
-		stops:        gradient.stops,
-		length:       length,
-		lastPosition: 0,
+		stops:         gradient.stops,
+		interpolation: gradient.interpolation,
+		repeating:     gradient.repeating,
+		length:        length,
+		lastPosition:  0,
 	}
 }
 
 type linearGradientColorizer struct {
 	stops             []gradientStop
+	interpolation     interpolation
+	repeating         bool
 	length            int
 	currentStop       int
 	lastPosition      float64
@@ -243,6 +278,10 @@ type linearGradientColorizer struct {
 
 // ColorAt returns the color at the position along the gradient.
 func (colorizer *linearGradientColorizer) ColorAt(position float64) color.RGBA {
+	if colorizer.repeating {
+		position = colorizer.repeatPosition(position)
+	}
+
 	// If we go backwards along the list, start over from the first stop.
 	if colorizer.lastPosition == 0 || position < colorizer.lastPosition {
 		colorizer.currentStop = 0
@@ -265,10 +304,27 @@ func (colorizer *linearGradientColorizer) ColorAt(position float64) color.RGBA {
 		// If we're at or after the last stop, use the last stop as the color.
 		return colorizer.stops[colorizer.currentStop].Color
 	} else {
-		return lerpColor(
+		return colorizer.interpolation.lerp(
 			colorizer.stops[colorizer.currentStop].Color,
 			colorizer.stops[colorizer.currentStop+1].Color,
 			(position-colorizer.currentStopOffset)/float64(colorizer.nextStopOffset-colorizer.currentStopOffset),
 		)
 	}
 }
+
+// repeatPosition maps a position along a repeating gradient to the equivalent
+// position between the first and last stops.
+func (colorizer *linearGradientColorizer) repeatPosition(position float64) float64 {
+	first := getStopOffset(colorizer.stops, 0, colorizer.length)
+	last := getStopOffset(colorizer.stops, len(colorizer.stops)-1, colorizer.length)
+	period := last - first
+	if period <= 0 {
+		return position
+	}
+
+	offset := math.Mod(position-first, period)
+	if offset < 0 {
+		offset += period
+	}
+	return first + offset
+}
@@ -193,3 +193,48 @@ func TestLinearGradientOneStop(t *testing.T) {
 		grad.Colors(2),
 	)
 }
+
+func TestRepeatingLinearGradient(t *testing.T) {
+	grad, err := CSSRepeatingLinearGradient("#000, #fff 2px")
+	assert.NoError(t, err)
+	assert.Equal(t,
+		[]color.RGBA{
+			{R: 63, G: 63, B: 63, A: 255},
+			{R: 191, G: 191, B: 191, A: 255},
+			{R: 63, G: 63, B: 63, A: 255},
+			{R: 191, G: 191, B: 191, A: 255},
+			{R: 63, G: 63, B: 63, A: 255},
+			{R: 191, G: 191, B: 191, A: 255},
+		},
+		grad.Colors(6),
+		"Should repeat absolute stops",
+	)
+
+	grad, err = CSSRepeatingLinearGradient("#f00 0 25%, #00f 25% 50%")
+	assert.NoError(t, err)
+	assert.Equal(t,
+		[]color.RGBA{
+			{R: 255, G: 0, B: 0, A: 255},
+			{R: 255, G: 0, B: 0, A: 255},
+			{R: 0, G: 0, B: 255, A: 255},
+			{R: 0, G: 0, B: 255, A: 255},
+			{R: 255, G: 0, B: 0, A: 255},
+			{R: 255, G: 0, B: 0, A: 255},
+			{R: 0, G: 0, B: 255, A: 255},
+			{R: 0, G: 0, B: 255, A: 255},
+		},
+		grad.Colors(8),
+		"Should repeat relative stops",
+	)
+
+	grad, err = CSSRepeatingLinearGradient("#000, #fff")
+	assert.NoError(t, err)
+	assert.Equal(t,
+		CSSLinearGradientMust("#000, #fff").Colors(9),
+		grad.Colors(9),
+		"Should be the same as a linear gradient if the stops cover the whole string",
+	)
+
+	_, err = CSSRepeatingLinearGradient("")
+	assert.EqualError(t, err, "Can't create CSSLinearGradient with no stops")
+}
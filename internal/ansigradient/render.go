@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/jwalton/gchalk/pkg/ansistyles"
+	"github.com/jwalton/kitsch/internal/colortools"
 )
 
 // ApplyGradients will apply the given background and foreground gradients to the given string.
@@ -37,7 +38,54 @@ func ApplyGradientsLen(str string, foreground Gradient, background Gradient) (st
 // and return the "print width" of the string in columns.
 //
 // Unlike ApplyGradients, this will not attempt to automatically detect the current color support level.
+//
+// If the string contains multiple lines, the gradients are stretched across the
+// widest line, and every line starts from the beginning of the gradients, so
+// the colors in each column line up.  The returned print width is the width
+// of the widest line.
 func ApplyGradientsRawLen(str string, foreground Gradient, background Gradient, level ColorLevel) (string, int) {
+	return applyGradients(str, foreground, background, level, false)
+}
+
+// ApplyGradientsOverRawLen is like ApplyGradientsRawLen, but the gradients
+// will replace any foreground or background colors already present in the
+// string, instead of leaving text with its own colors alone.  This lets a
+// gradient run continuously across a string made up of many differently
+// colored pieces.
+func ApplyGradientsOverRawLen(str string, foreground Gradient, background Gradient, level ColorLevel) (string, int) {
+	return applyGradients(str, foreground, background, level, true)
+}
+
+// ApplyAutoForegroundRawLen will apply the given background gradient to the
+// given string, and give every character a foreground color picked by `auto`
+// which contrasts with the character's background.  If `background` is nil,
+// the background of each character comes from any background colors already
+// in the string (such as those added by ApplyGradientsRawLen), or from
+// `auto.Background` for characters which don't have a background color.
+// Characters which already have their own foreground and background colors
+// are left alone.  Returns the colored string and its print width.
+func ApplyAutoForegroundRawLen(str string, auto *AutoForeground, background Gradient, level ColorLevel) (string, int) {
+	return applyAutoForeground(str, auto, background, level, false)
+}
+
+// ApplyAutoForegroundOverRawLen is like ApplyAutoForegroundRawLen, but
+// replaces any foreground or background colors already present in the string.
+func ApplyAutoForegroundOverRawLen(str string, auto *AutoForeground, background Gradient, level ColorLevel) (string, int) {
+	return applyAutoForeground(str, auto, background, level, true)
+}
+
+func applyAutoForeground(str string, auto *AutoForeground, background Gradient, level ColorLevel, override bool) (string, int) {
+	parsed, printWidth := tokenize(str)
+
+	var bgColors ColorGenerator
+	if background != nil {
+		bgColors = background.Generator(printWidth)
+	}
+
+	return renderRGBAs(parsed, printWidth, nil, bgColors, auto, level, override), printWidth
+}
+
+func applyGradients(str string, foreground Gradient, background Gradient, level ColorLevel, override bool) (string, int) {
 	parsed, printWidth := tokenize(str)
 
 	if foreground == nil && background == nil {
@@ -55,17 +103,20 @@ func ApplyGradientsRawLen(str string, foreground Gradient, background Gradient,
 	}
 
 	// TODO: Rename this
-	return renderRGBAs(parsed, printWidth, fgColors, bgColors, level), printWidth
+	return renderRGBAs(parsed, printWidth, fgColors, bgColors, nil, level, override), printWidth
 }
 
+// compareColors returns true if two colors will be rendered as the same color
+// at a given color level.  Colors are downgraded to the nearest color in the
+// ANSI palettes by perceptual distance, which works much better for gradients
+// than picking the nearest color by RGB value.
 var compareColors = [](func(color.RGBA, color.RGBA) bool){
 	LevelNone: func(a, b color.RGBA) bool { return true },
 	LevelBasic: func(a, b color.RGBA) bool {
-		return ansistyles.Ansi256ToAnsi(ansistyles.RGBToAnsi256(a.R, a.G, a.B)) ==
-			ansistyles.Ansi256ToAnsi(ansistyles.RGBToAnsi256(b.R, b.G, b.B))
+		return colortools.NearestAnsi(a) == colortools.NearestAnsi(b)
 	},
 	LevelAnsi256: func(a, b color.RGBA) bool {
-		return ansistyles.RGBToAnsi256(a.R, a.G, a.B) == ansistyles.RGBToAnsi256(b.R, b.G, b.B)
+		return colortools.NearestAnsi256(a) == colortools.NearestAnsi256(b)
 	},
 	LevelAnsi16m: func(a, b color.RGBA) bool {
 		return a.R == b.R && a.G == b.G && a.B == b.B
@@ -75,10 +126,10 @@ var compareColors = [](func(color.RGBA, color.RGBA) bool){
 var fgColorize = [](func(out *strings.Builder, c color.RGBA)){
 	LevelNone: func(out *strings.Builder, c color.RGBA) {},
 	LevelBasic: func(out *strings.Builder, c color.RGBA) {
-		ansistyles.WriteStringAnsi(out, ansistyles.Ansi256ToAnsi(ansistyles.RGBToAnsi256(c.R, c.G, c.B)))
+		ansistyles.WriteStringAnsi(out, colortools.NearestAnsi(c))
 	},
 	LevelAnsi256: func(out *strings.Builder, c color.RGBA) {
-		ansistyles.WriteStringAnsi256(out, ansistyles.RGBToAnsi256(c.R, c.G, c.B))
+		ansistyles.WriteStringAnsi256(out, colortools.NearestAnsi256(c))
 	},
 	LevelAnsi16m: func(out *strings.Builder, c color.RGBA) {
 		ansistyles.WriteStringAnsi16m(out, c.R, c.G, c.B)
@@ -88,10 +139,10 @@ var fgColorize = [](func(out *strings.Builder, c color.RGBA)){
 var bgColorize = [](func(out *strings.Builder, c color.RGBA)){
 	LevelNone: func(out *strings.Builder, c color.RGBA) {},
 	LevelBasic: func(out *strings.Builder, c color.RGBA) {
-		ansistyles.WriteStringBgAnsi(out, ansistyles.Ansi256ToAnsi(ansistyles.RGBToAnsi256(c.R, c.G, c.B)))
+		ansistyles.WriteStringBgAnsi(out, colortools.NearestAnsi(c))
 	},
 	LevelAnsi256: func(out *strings.Builder, c color.RGBA) {
-		ansistyles.WriteStringBgAnsi256(out, ansistyles.RGBToAnsi256(c.R, c.G, c.B))
+		ansistyles.WriteStringBgAnsi256(out, colortools.NearestAnsi256(c))
 	},
 	LevelAnsi16m: func(out *strings.Builder, c color.RGBA) {
 		ansistyles.WriteStringBgAnsi16m(out, c.R, c.G, c.B)
@@ -105,14 +156,20 @@ type colorizeContext struct {
 }
 
 // renderRGBAs will take a parsed input string and colors, and write the colorized
-// version to the `out` writer.
+// version to the `out` writer.  If `auto` is not nil, it is used to pick the
+// foreground colors instead of `fgColors`.  If `override` is true, the colors
+// will be applied even to text that already has its own colors.
 func renderRGBAs(
 	parsed []gradientToken,
 	printWidth int,
 	fgColors ColorGenerator,
 	bgColors ColorGenerator,
+	auto *AutoForeground,
 	level ColorLevel,
+	override bool,
 ) string {
+	hasFg := fgColors != nil || auto != nil
+
 	out := strings.Builder{}
 	worstCaseLength := printWidth * 20
 	out.Grow(worstCaseLength)
@@ -124,28 +181,45 @@ func renderRGBAs(
 	prevBg := ""
 
 	for _, token := range parsed {
+		fg := fgColors
+		if auto != nil && (token.t == tokenString || token.t == tokenComplexChar) {
+			fg = auto.generator(token, bgColors, level, override)
+		}
+
 		switch token.t {
 		case tokenString:
-			if (fgColors == nil || token.fg != "") && (bgColors == nil || token.bg != "") {
+			if !override && hasOwnColors(token, fg, bgColors) {
 				// Don't color this string.
 				out.Write([]byte(token.content))
 			} else {
-				colorizeASCIIString(&context, token.content, column, fgColors, bgColors, &out)
+				colorizeASCIIString(&context, token.content, column, fg, bgColors, &out)
 			}
 			column += len(token.content)
 
 		case tokenComplexChar:
-			if (bgColors == nil || token.fg != "") && (bgColors == nil || token.bg != "") {
+			if !override && hasOwnColors(token, fg, bgColors) {
 				// Don't color this string.
 			} else {
-				renderColorCodes(&context, float64(column)+(float64(token.printWidth)/2), fgColors, bgColors, &out)
+				renderColorCodes(&context, float64(column)+(float64(token.printWidth)/2), fg, bgColors, &out)
 			}
 			out.WriteString(token.content)
 
 			column += token.printWidth
 
+		case tokenNewline:
+			// Close the background before the newline, so the terminal
+			// doesn't fill the rest of the line with the background color.
+			if bgColors != nil && context.lastBgColor.A != 0 {
+				out.WriteString(ansistyles.BgClose)
+				context.lastBgColor = color.RGBA{}
+			}
+			out.WriteString(token.content)
+
+			// Every line starts from the beginning of the gradient.
+			column = 0
+
 		case tokenEscapeCode:
-			if fgColors != nil && token.content == ansistyles.Close {
+			if hasFg && token.content == ansistyles.Close {
 				// Skip closing the forground color.
 				context.lastFgColor = color.RGBA{}
 			} else if bgColors != nil && token.content == ansistyles.BgClose {
@@ -173,7 +247,7 @@ func renderRGBAs(
 		}
 	}
 
-	if fgColors != nil {
+	if hasFg {
 		out.WriteString(ansistyles.Close)
 	}
 
@@ -184,6 +258,12 @@ func renderRGBAs(
 	return out.String()
 }
 
+// hasOwnColors returns true if a token already has its own color for every
+// channel we have a gradient for.
+func hasOwnColors(token gradientToken, fgColors ColorGenerator, bgColors ColorGenerator) bool {
+	return (fgColors == nil || token.fg != "") && (bgColors == nil || token.bg != "")
+}
+
 // colorizeASCIIString will colorize a string consisting of ASCII characters,
 // where each character is a single byte long.
 //
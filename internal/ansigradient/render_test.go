@@ -50,6 +50,41 @@ func TestRenderPreColoredText(t *testing.T) {
 	assert.Equal(t, "\u001b[38;2;255;0;0mAB\u001b[42mC\u001b[49mDE\u001b[39m", output)
 }
 
+func TestRenderMultiLine(t *testing.T) {
+	gradient := CSSLinearGradientMust("#f00, #00f")
+
+	// Each line should start from the beginning of the gradient, and the
+	// background should be closed before each newline.
+	result, len := ApplyGradientsRawLen("AB\nCDEF", nil, gradient, LevelAnsi16m)
+	assert.Equal(t,
+		"\u001b[48;2;223;0;31mA\u001b[48;2;159;0;95mB\u001b[49m\n"+
+			"\u001b[48;2;223;0;31mC\u001b[48;2;159;0;95mD\u001b[48;2;95;0;159mE\u001b[48;2;31;0;223mF\u001b[49m",
+		result,
+	)
+	assert.Equal(t, 4, len)
+}
+
+func TestRenderOverPreColoredText(t *testing.T) {
+	g := gchalk.New(gchalk.ForceLevel(gchalk.LevelAnsi16m))
+	gradient := CSSLinearGradientMust("#ff0000, #ff0000")
+	message := "A" + g.BgGreen("B") + g.Green("C") + "D"
+
+	// Background gradient should replace the background of "B".
+	output, len := ApplyGradientsOverRawLen(message, nil, gradient, LevelAnsi16m)
+	assert.Equal(t,
+		"\u001b[48;2;255;0;0mA\u001b[42m\u001b[48;2;255;0;0mB\u001b[32m\u001b[48;2;255;0;0mC\u001b[39mD\u001b[49m",
+		output,
+	)
+	assert.Equal(t, 4, len)
+
+	// Foreground gradient should replace the foreground of "C".
+	output, _ = ApplyGradientsOverRawLen(message, gradient, nil, LevelAnsi16m)
+	assert.Equal(t,
+		"\u001b[38;2;255;0;0mA\u001b[42mB\u001b[49m\u001b[32m\u001b[38;2;255;0;0mC\u001b[38;2;255;0;0mD\u001b[39m",
+		output,
+	)
+}
+
 func TestRenderGradientsOverGradients(t *testing.T) {
 	bgGradient := CSSLinearGradientMust("#f00, #000")
 	fgGradient := CSSLinearGradientMust("#000, #0ff")
@@ -103,13 +138,13 @@ func Test256ColorMode(t *testing.T) {
 
 	result := ApplyGradientsRaw("Hello World!", gradient, nil, LevelAnsi256)
 	assert.Equal(t,
-		"\u001b[38;5;197mH\u001b[38;5;161me\u001b[38;5;162mll\u001b[38;5;126mo\u001b[38;5;127m W\u001b[38;5;91mo\u001b[38;5;92mrl\u001b[38;5;56md\u001b[38;5;57m!\u001b[39m",
+		"\u001b[38;5;196mH\u001b[38;5;160me\u001b[38;5;161ml\u001b[38;5;125ml\u001b[38;5;126mo\u001b[38;5;90m W\u001b[38;5;91mo\u001b[38;5;55mr\u001b[38;5;56ml\u001b[38;5;20md\u001b[38;5;21m!\u001b[39m",
 		result,
 	)
 
 	result = ApplyGradientsRaw("Hello World!", nil, gradient, LevelAnsi256)
 	assert.Equal(t,
-		"\u001b[48;5;197mH\u001b[48;5;161me\u001b[48;5;162mll\u001b[48;5;126mo\u001b[48;5;127m W\u001b[48;5;91mo\u001b[48;5;92mrl\u001b[48;5;56md\u001b[48;5;57m!\u001b[49m",
+		"\u001b[48;5;196mH\u001b[48;5;160me\u001b[48;5;161ml\u001b[48;5;125ml\u001b[48;5;126mo\u001b[48;5;90m W\u001b[48;5;91mo\u001b[48;5;55mr\u001b[48;5;56ml\u001b[48;5;20md\u001b[48;5;21m!\u001b[49m",
 		result,
 	)
 }
@@ -1,6 +1,8 @@
 package ansigradient
 
 import (
+	"strings"
+
 	"github.com/jwalton/go-ansiparser"
 	"github.com/mattn/go-runewidth"
 	"github.com/rivo/uniseg"
@@ -13,6 +15,7 @@ const (
 	tokenString      tokenType = 0
 	tokenEscapeCode  tokenType = 1
 	tokenComplexChar tokenType = 2
+	tokenNewline     tokenType = 3
 )
 
 type gradientToken struct {
@@ -24,7 +27,7 @@ type gradientToken struct {
 	printWidth int
 }
 
-// tokenize returns the set of tokens, and the sum of their print widths.
+// tokenize returns the set of tokens, and the print width of the widest line.
 func tokenize(s string) ([]gradientToken, int) {
 	tokens := []gradientToken{}
 
@@ -36,13 +39,7 @@ func tokenize(s string) ([]gradientToken, int) {
 		case ansiparser.String:
 			if t.IsASCII {
 				// If the string is all ASCII, just copy it to a string token.
-				tokens = append(tokens, gradientToken{
-					t:          tokenString,
-					content:    t.Content,
-					fg:         t.FG,
-					bg:         t.BG,
-					printWidth: len(t.Content),
-				})
+				tokens = appendStringTokens(tokens, t.Content, t.FG, t.BG)
 			} else {
 				// If there are unicode characters, find them and work out
 				// their print widths.
@@ -61,26 +58,69 @@ func tokenize(s string) ([]gradientToken, int) {
 	}
 
 	printWidth := 0
+	lineWidth := 0
 	for _, token := range tokens {
-		printWidth += token.printWidth
+		if token.t == tokenNewline {
+			lineWidth = 0
+		} else {
+			lineWidth += token.printWidth
+		}
+		if lineWidth > printWidth {
+			printWidth = lineWidth
+		}
 	}
 
 	return tokens, printWidth
 }
 
+// appendStringTokens appends string tokens for an ASCII string to `tokens`,
+// splitting the string into a separate token at each newline.
+func appendStringTokens(tokens []gradientToken, str string, fg string, bg string) []gradientToken {
+	for len(str) > 0 {
+		end := strings.IndexByte(str, '\n')
+		if end == -1 {
+			end = len(str)
+		}
+
+		// Treat "\r\n" as a single newline.
+		lineEnd := end
+		if end < len(str) && end > 0 && str[end-1] == '\r' {
+			lineEnd = end - 1
+		}
+
+		if lineEnd > 0 {
+			tokens = append(tokens, gradientToken{
+				t:          tokenString,
+				content:    str[:lineEnd],
+				fg:         fg,
+				bg:         bg,
+				printWidth: lineEnd,
+			})
+		}
+
+		if end < len(str) {
+			end++
+			tokens = append(tokens, gradientToken{
+				t:       tokenNewline,
+				content: str[lineEnd:end],
+				fg:      fg,
+				bg:      bg,
+			})
+		}
+
+		str = str[end:]
+	}
+
+	return tokens
+}
+
 func tokenizeStringTokenWithUnicodeCharacters(ansiToken ansiparser.AnsiToken) []gradientToken {
 	tokens := []gradientToken{}
 	str := ansiToken.Content
 	position := 0
 
 	makeStringToken := func(str string) {
-		tokens = append(tokens, gradientToken{
-			t:          tokenString,
-			content:    str,
-			fg:         ansiToken.FG,
-			bg:         ansiToken.BG,
-			printWidth: len(str),
-		})
+		tokens = appendStringTokens(tokens, str, ansiToken.FG, ansiToken.BG)
 	}
 
 	// Grab any non-unicode characters at the start of the string.
@@ -113,8 +153,12 @@ func tokenizeStringTokenWithUnicodeCharacters(ansiToken ansiparser.AnsiToken) []
 			}
 
 			// Add the grapheme token.
+			t := tokenComplexChar
+			if grapheme == "\r\n" {
+				t = tokenNewline
+			}
 			tokens = append(tokens, gradientToken{
-				t:          tokenComplexChar,
+				t:          t,
 				content:    grapheme,
 				fg:         ansiToken.FG,
 				bg:         ansiToken.BG,
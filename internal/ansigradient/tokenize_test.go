@@ -44,3 +44,16 @@ func TestTokenizeZwjEmoji(t *testing.T) {
 	}, tokens)
 	assert.Equal(t, 3, printWidth)
 }
+
+func TestTokenizeMultiLine(t *testing.T) {
+	tokens, printWidth := tokenize("ab\ncdef\r\ng")
+	assert.Equal(t, []gradientToken{
+		{t: tokenString, content: "ab", printWidth: 2},
+		{t: tokenNewline, content: "\n"},
+		{t: tokenString, content: "cdef", printWidth: 4},
+		{t: tokenNewline, content: "\r\n"},
+		{t: tokenString, content: "g", printWidth: 1},
+	}, tokens)
+	// Print width should be the width of the widest line.
+	assert.Equal(t, 4, printWidth)
+}
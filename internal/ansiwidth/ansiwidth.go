@@ -0,0 +1,30 @@
+// Package ansiwidth works out how much space text containing ANSI escape
+// codes will take up when printed to a terminal.
+package ansiwidth
+
+import (
+	"github.com/jwalton/go-ansiparser"
+	"github.com/mattn/go-runewidth"
+)
+
+// StringWidth returns the number of columns `str` will occupy when printed
+// to a terminal.  ANSI escape codes are ignored, and wide characters (such as
+// emoji and CJK characters) count as two columns.
+func StringWidth(str string) int {
+	width := 0
+	tokenizer := ansiparser.NewStringTokenizer(str)
+
+	for tokenizer.Next() {
+		token := tokenizer.Token()
+
+		if token.Type == ansiparser.String {
+			if token.IsASCII {
+				width += len(token.Content)
+			} else {
+				width += runewidth.StringWidth(token.Content)
+			}
+		}
+	}
+
+	return width
+}
@@ -0,0 +1,15 @@
+package ansiwidth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringWidth(t *testing.T) {
+	assert.Equal(t, 0, StringWidth(""))
+	assert.Equal(t, 5, StringWidth("hello"))
+	assert.Equal(t, 5, StringWidth("\u001b[31mhello\u001b[39m"))
+	assert.Equal(t, 4, StringWidth("\u001b[1m日本\u001b[22m"))
+	assert.Equal(t, 3, StringWidth("a🌱"))
+}
@@ -1,10 +1,14 @@
 package cache
 
+import "sync"
+
 type memoryCache struct {
+	mutex sync.RWMutex
 	cache map[string][]byte
 }
 
-// NewMemoryCache creates an in-memory Cache.
+// NewMemoryCache creates an in-memory Cache.  The returned cache is safe to
+// use from multiple goroutines.
 func NewMemoryCache() Cache {
 	return &memoryCache{
 		cache: map[string][]byte{},
@@ -14,6 +18,8 @@ func NewMemoryCache() Cache {
 // Get returns the value for the given key.  If the value is not found,
 // returns nil.
 func (cache *memoryCache) Get(key string) []byte {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
 	return cache.cache[key]
 }
 
@@ -21,10 +27,15 @@ func (cache *memoryCache) Get(key string) []byte {
 func (cache *memoryCache) Set(key string, value []byte) {
 	cacheValue := make([]byte, len(value))
 	copy(cacheValue, value)
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
 	cache.cache[key] = cacheValue
 }
 
 // Delete deletes the value for the given key.
 func (cache *memoryCache) Delete(key string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
 	delete(cache.cache, key)
 }
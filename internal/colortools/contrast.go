@@ -0,0 +1,138 @@
+package colortools
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ContrastAlgorithm is a way of measuring how readable text of one color is
+// on a background of another color.
+type ContrastAlgorithm int
+
+const (
+	// WCAG measures contrast with the WCAG 2 contrast ratio.  See
+	// https://www.w3.org/TR/WCAG21/#dfn-contrast-ratio.
+	WCAG ContrastAlgorithm = iota
+	// APCA measures contrast with the "Accessible Perceptual Contrast
+	// Algorithm" from the WCAG 3 drafts.  See https://github.com/Myndex/apca-w3.
+	APCA
+)
+
+// Contrast returns the contrast between text of color `text` on a background
+// of color `background`.  Higher values are more readable.  For WCAG this is
+// the contrast ratio, from 1 to 21.  For APCA this is the absolute value of
+// the lightness contrast (Lc), from 0 to about 108.  Alpha is ignored.
+func (algorithm ContrastAlgorithm) Contrast(text color.RGBA, background color.RGBA) float64 {
+	if algorithm == APCA {
+		return math.Abs(APCAContrast(text, background))
+	}
+	return WCAGContrast(text, background)
+}
+
+// RelativeLuminance returns the WCAG relative luminance of a color, from 0
+// for black to 1 for white.
+func RelativeLuminance(c color.RGBA) float64 {
+	return 0.2126*srgbToLinear(c.R) + 0.7152*srgbToLinear(c.G) + 0.0722*srgbToLinear(c.B)
+}
+
+// WCAGContrast returns the WCAG 2 contrast ratio between two colors.  The
+// result is the same no matter which order the colors are passed in.
+func WCAGContrast(a color.RGBA, b color.RGBA) float64 {
+	l1 := RelativeLuminance(a)
+	l2 := RelativeLuminance(b)
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+	return (l1 + 0.05) / (l2 + 0.05)
+}
+
+// APCA 0.0.98G constants.
+const (
+	apcaBlackThreshold = 0.022
+	apcaBlackClamp     = 1.414
+	apcaNormBG         = 0.56
+	apcaNormText       = 0.57
+	apcaRevBG          = 0.65
+	apcaRevText        = 0.62
+	apcaScale          = 1.14
+	apcaLowOffset      = 0.027
+	apcaLowClip        = 0.1
+	apcaDeltaYMin      = 0.0005
+)
+
+// apcaLuminance returns the screen luminance of a color, as used by APCA.
+func apcaLuminance(c color.RGBA) float64 {
+	channel := func(value uint8) float64 {
+		return math.Pow(float64(value)/255, 2.4)
+	}
+	y := 0.2126729*channel(c.R) + 0.7151522*channel(c.G) + 0.0721750*channel(c.B)
+
+	// Soft clamp near black.
+	if y < apcaBlackThreshold {
+		y += math.Pow(apcaBlackThreshold-y, apcaBlackClamp)
+	}
+	return y
+}
+
+// APCAContrast returns the APCA lightness contrast (Lc) of text of color
+// `text` on a background of color `background`.  Dark text on a light
+// background gives a positive value, and light text on a dark background
+// gives a negative value.
+func APCAContrast(text color.RGBA, background color.RGBA) float64 {
+	textY := apcaLuminance(text)
+	backgroundY := apcaLuminance(background)
+
+	if math.Abs(backgroundY-textY) < apcaDeltaYMin {
+		return 0
+	}
+
+	if backgroundY > textY {
+		// Dark text on a light background.
+		contrast := (math.Pow(backgroundY, apcaNormBG) - math.Pow(textY, apcaNormText)) * apcaScale
+		if contrast < apcaLowClip {
+			return 0
+		}
+		return (contrast - apcaLowOffset) * 100
+	}
+
+	// Light text on a dark background.
+	contrast := (math.Pow(backgroundY, apcaRevBG) - math.Pow(textY, apcaRevText)) * apcaScale
+	if contrast > -apcaLowClip {
+		return 0
+	}
+	return (contrast + apcaLowOffset) * 100
+}
+
+// ParseSGRColor converts the color from an SGR escape code (without the
+// leading "\x1b[" or trailing "m") into an RGB color.  `code` can set the
+// foreground or background color, and can be a basic ANSI color (e.g. "31" or
+// "101"), an ANSI 256 color (e.g. "48;5;39"), or an RGB color (e.g.
+// "38;2;255;128;0").  Basic ANSI colors are converted using `Ansi16Colors`.
+// Returns false if `code` does not set a color.
+func ParseSGRColor(code string) (color.RGBA, bool) {
+	parts := strings.Split(code, ";")
+	values := make([]int, len(parts))
+	for index, part := range parts {
+		value, err := strconv.Atoi(part)
+		if err != nil || value < 0 {
+			return color.RGBA{}, false
+		}
+		values[index] = value
+	}
+
+	switch {
+	case len(values) == 1 && (values[0] >= 30 && values[0] <= 37 || values[0] >= 40 && values[0] <= 47):
+		return Ansi16Colors[values[0]%10], true
+	case len(values) == 1 && (values[0] >= 90 && values[0] <= 97 || values[0] >= 100 && values[0] <= 107):
+		return Ansi16Colors[values[0]%10+8], true
+	case len(values) == 3 && (values[0] == 38 || values[0] == 48) && values[1] == 5 && values[2] <= 255:
+		return Ansi256ToRGBA(uint8(values[2])), true
+	case len(values) == 5 && (values[0] == 38 || values[0] == 48) && values[1] == 2 &&
+		values[2] <= 255 && values[3] <= 255 && values[4] <= 255:
+		return color.RGBA{uint8(values[2]), uint8(values[3]), uint8(values[4]), 255}, true
+	default:
+		return color.RGBA{}, false
+	}
+}
@@ -0,0 +1,59 @@
+package colortools
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	black = color.RGBA{0, 0, 0, 255}
+	white = color.RGBA{255, 255, 255, 255}
+	gray  = color.RGBA{0x88, 0x88, 0x88, 255}
+)
+
+func TestWCAGContrast(t *testing.T) {
+	assert.InDelta(t, 21, WCAGContrast(black, white), 0.001)
+	assert.InDelta(t, 21, WCAGContrast(white, black), 0.001)
+	assert.InDelta(t, 1, WCAGContrast(gray, gray), 0.001)
+	assert.InDelta(t, 3.54, WCAGContrast(gray, white), 0.01)
+}
+
+func TestAPCAContrast(t *testing.T) {
+	assert.InDelta(t, 106.04, APCAContrast(black, white), 0.01)
+	assert.InDelta(t, -107.88, APCAContrast(white, black), 0.01)
+	assert.InDelta(t, 63.06, APCAContrast(gray, white), 0.01)
+	assert.InDelta(t, -68.54, APCAContrast(white, gray), 0.01)
+	assert.Equal(t, 0.0, APCAContrast(gray, gray))
+}
+
+func TestContrastAlgorithm(t *testing.T) {
+	assert.InDelta(t, 107.88, APCA.Contrast(white, black), 0.01)
+	assert.InDelta(t, 21, WCAG.Contrast(white, black), 0.001)
+}
+
+func TestParseSGRColor(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected color.RGBA
+		ok       bool
+	}{
+		{"31", Ansi16Colors[1], true},
+		{"44", Ansi16Colors[4], true},
+		{"97", Ansi16Colors[15], true},
+		{"100", Ansi16Colors[8], true},
+		{"48;5;39", color.RGBA{0, 175, 255, 255}, true},
+		{"38;2;255;128;0", color.RGBA{255, 128, 0, 255}, true},
+		{"49", color.RGBA{}, false},
+		{"", color.RGBA{}, false},
+		{"48;2;256;0;0", color.RGBA{}, false},
+		{"1", color.RGBA{}, false},
+	}
+
+	for _, test := range tests {
+		c, ok := ParseSGRColor(test.code)
+		assert.Equal(t, test.ok, ok, test.code)
+		assert.Equal(t, test.expected, c, test.code)
+	}
+}
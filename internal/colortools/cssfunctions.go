@@ -0,0 +1,265 @@
+package colortools
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// isColorFunction returns true if the given string looks like a CSS color
+// function (e.g. "rgb(255 0 0)").
+func isColorFunction(str string) bool {
+	open := strings.IndexByte(str, '(')
+	return open > 0 && strings.HasSuffix(str, ")")
+}
+
+// parseColorFunction parses one of the CSS Color Level 4 functional notations:
+// `rgb()`, `rgba()`, `hsl()`, `hsla()`, `hwb()`, `oklab()`, or `oklch()`.
+// Both the modern space separated syntax (e.g. "rgb(255 0 0 / 50%)") and
+// the legacy comma separated syntax (e.g. "rgba(255, 0, 0, 0.5)") are accepted.
+//
+// See https://www.w3.org/TR/css-color-4/.
+func parseColorFunction(str string) (color.RGBA, error) {
+	if !isColorFunction(str) {
+		return color.RGBA{}, errUnrecognized
+	}
+
+	open := strings.IndexByte(str, '(')
+	name := strings.ToLower(strings.TrimSpace(str[:open]))
+	components, alpha, err := splitColorFunctionArgs(str[open+1 : len(str)-1])
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid color %q: %w", str, err)
+	}
+
+	var result color.RGBA
+	switch name {
+	case "rgb", "rgba":
+		result, err = rgbFunction(components)
+	case "hsl", "hsla":
+		result, err = hslFunction(components)
+	case "hwb":
+		result, err = hwbFunction(components)
+	case "oklab":
+		result, err = oklabFunction(components)
+	case "oklch":
+		result, err = oklchFunction(components)
+	default:
+		return color.RGBA{}, errUnrecognized
+	}
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid color %q: %w", str, err)
+	}
+
+	result.A = 255
+	if alpha != "" {
+		value, percent, err := parseComponent(alpha)
+		if err != nil {
+			return color.RGBA{}, fmt.Errorf("invalid color %q: %w", str, err)
+		}
+		if percent {
+			value /= 100
+		}
+		result.A = clampChannel(value * 255)
+	}
+
+	return result, nil
+}
+
+// splitColorFunctionArgs splits the arguments to a color function into three
+// color components and an optional alpha value.
+func splitColorFunctionArgs(args string) (components []string, alpha string, err error) {
+	if strings.Contains(args, ",") {
+		// Legacy syntax.
+		components = strings.Split(args, ",")
+		for index := range components {
+			components[index] = strings.TrimSpace(components[index])
+		}
+		if len(components) == 4 {
+			alpha = components[3]
+			components = components[:3]
+		}
+	} else {
+		parts := strings.Split(args, "/")
+		if len(parts) > 2 {
+			return nil, "", fmt.Errorf("too many \"/\"s")
+		}
+		components = strings.Fields(parts[0])
+		if len(parts) == 2 {
+			alpha = strings.TrimSpace(parts[1])
+			if alpha == "" {
+				return nil, "", fmt.Errorf("missing alpha")
+			}
+		}
+	}
+
+	if len(components) != 3 {
+		return nil, "", fmt.Errorf("expected 3 components, found %d", len(components))
+	}
+	return components, alpha, nil
+}
+
+// parseComponent parses a number or a percentage.  If the value is a
+// percentage, `percent` will be true and the returned value will be the
+// percentage (e.g. 50 for "50%").  The keyword "none" is treated as 0.
+func parseComponent(str string) (value float64, percent bool, err error) {
+	if strings.EqualFold(str, "none") {
+		return 0, false, nil
+	}
+
+	if strings.HasSuffix(str, "%") {
+		percent = true
+		str = str[:len(str)-1]
+	}
+
+	value, err = strconv.ParseFloat(str, 64)
+	if err != nil || math.IsInf(value, 0) || math.IsNaN(value) {
+		return 0, false, fmt.Errorf("invalid value %q", str)
+	}
+	return value, percent, nil
+}
+
+// parseFraction parses a number or a percentage, and returns a value where
+// 100% is `scale`.  Bare numbers are divided by `numberScale`.
+func parseFraction(str string, scale float64, numberScale float64) (float64, error) {
+	value, percent, err := parseComponent(str)
+	if err != nil {
+		return 0, err
+	}
+	if percent {
+		return value / 100 * scale, nil
+	}
+	return value / numberScale, nil
+}
+
+// parseHue parses a CSS hue, and returns the hue in degrees from 0 to 360.
+func parseHue(str string) (float64, error) {
+	units := []struct {
+		suffix  string
+		degrees float64
+	}{
+		{"deg", 1},
+		{"grad", 360.0 / 400},
+		{"rad", 180 / math.Pi},
+		{"turn", 360},
+	}
+
+	scale := 1.0
+	lower := strings.ToLower(str)
+	for _, unit := range units {
+		if strings.HasSuffix(lower, unit.suffix) {
+			str = str[:len(str)-len(unit.suffix)]
+			scale = unit.degrees
+			break
+		}
+	}
+
+	value, percent, err := parseComponent(str)
+	if err != nil || percent {
+		return 0, fmt.Errorf("invalid hue %q", str)
+	}
+
+	hue := math.Mod(value*scale, 360)
+	if hue < 0 {
+		hue += 360
+	}
+	return hue, nil
+}
+
+func rgbFunction(components []string) (color.RGBA, error) {
+	var channels [3]uint8
+	for index, component := range components {
+		value, err := parseFraction(component, 255, 1)
+		if err != nil {
+			return color.RGBA{}, err
+		}
+		channels[index] = clampChannel(value)
+	}
+	return color.RGBA{R: channels[0], G: channels[1], B: channels[2]}, nil
+}
+
+func hslFunction(components []string) (color.RGBA, error) {
+	hue, err := parseHue(components[0])
+	if err != nil {
+		return color.RGBA{}, err
+	}
+	saturation, err := parseFraction(components[1], 1, 100)
+	if err != nil {
+		return color.RGBA{}, err
+	}
+	lightness, err := parseFraction(components[2], 1, 100)
+	if err != nil {
+		return color.RGBA{}, err
+	}
+
+	return HSL{H: hue, S: saturation, L: lightness}.ToRGBA(), nil
+}
+
+func hwbFunction(components []string) (color.RGBA, error) {
+	hue, err := parseHue(components[0])
+	if err != nil {
+		return color.RGBA{}, err
+	}
+	whiteness, err := parseFraction(components[1], 1, 100)
+	if err != nil {
+		return color.RGBA{}, err
+	}
+	blackness, err := parseFraction(components[2], 1, 100)
+	if err != nil {
+		return color.RGBA{}, err
+	}
+
+	whiteness = clampUnit(whiteness)
+	blackness = clampUnit(blackness)
+	if whiteness+blackness >= 1 {
+		gray := clampChannel(whiteness / (whiteness + blackness) * 255)
+		return color.RGBA{R: gray, G: gray, B: gray}, nil
+	}
+
+	r, g, b := hslToRGB(hue, 1, 0.5)
+	scale := 1 - whiteness - blackness
+	return color.RGBA{
+		R: clampChannel((r*scale + whiteness) * 255),
+		G: clampChannel((g*scale + whiteness) * 255),
+		B: clampChannel((b*scale + whiteness) * 255),
+	}, nil
+}
+
+func oklabFunction(components []string) (color.RGBA, error) {
+	lightness, err := parseFraction(components[0], 1, 1)
+	if err != nil {
+		return color.RGBA{}, err
+	}
+	a, err := parseFraction(components[1], 0.4, 1)
+	if err != nil {
+		return color.RGBA{}, err
+	}
+	b, err := parseFraction(components[2], 0.4, 1)
+	if err != nil {
+		return color.RGBA{}, err
+	}
+
+	return OKLab{L: clampUnit(lightness), A: a, B: b}.ToRGBA(), nil
+}
+
+func oklchFunction(components []string) (color.RGBA, error) {
+	lightness, err := parseFraction(components[0], 1, 1)
+	if err != nil {
+		return color.RGBA{}, err
+	}
+	chroma, err := parseFraction(components[1], 0.4, 1)
+	if err != nil {
+		return color.RGBA{}, err
+	}
+	hue, err := parseHue(components[2])
+	if err != nil {
+		return color.RGBA{}, err
+	}
+
+	return OKLCh{L: clampUnit(lightness), C: math.Max(chroma, 0), H: hue}.ToOKLab().ToRGBA(), nil
+}
+
+func clampUnit(value float64) float64 {
+	return math.Max(0, math.Min(1, value))
+}
@@ -0,0 +1,61 @@
+package colortools
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseColorFunctions(t *testing.T) {
+	tests := map[string]color.RGBA{
+		"rgb(255 0 0)":              {255, 0, 0, 255},
+		"rgb(100% 50% 0% / 25%)":    {255, 128, 0, 64},
+		"rgba(255, 0, 0, 0.5)":      {255, 0, 0, 128},
+		"rgb(none 255 none)":        {0, 255, 0, 255},
+		"hsl(120 100% 50%)":         {0, 255, 0, 255},
+		"hsl(120deg, 100%, 50%)":    {0, 255, 0, 255},
+		"hsla(0.5turn 100% 25%)":    {0, 128, 128, 255},
+		"hsl(200 80% 50% / 0.5)":    {25, 161, 230, 128},
+		"hwb(120 20% 20%)":          {51, 204, 51, 255},
+		"hwb(0 60% 60%)":            {128, 128, 128, 255},
+		"oklch(62.8% 0.2577 29.23)": {255, 0, 0, 255},
+		"oklch(1 0 0)":              {255, 255, 255, 255},
+		"oklch(70% 0.1 250 / 50%)":  {109, 163, 218, 128},
+		"oklab(0 0 0)":              {0, 0, 0, 255},
+	}
+
+	for str, expected := range tests {
+		c, err := ParseColor(str)
+		if assert.NoError(t, err, str) {
+			assert.Equal(t, expected, c, str)
+		}
+		assert.True(t, ValidateColor(str), str)
+	}
+}
+
+func TestParseColorFunctionErrors(t *testing.T) {
+	_, err := ParseColor("rgb(1 2)")
+	assert.EqualError(t, err, `invalid color "rgb(1 2)": expected 3 components, found 2`)
+
+	_, err = ParseColor("hsl(10% 50% 50%)")
+	assert.EqualError(t, err, `invalid color "hsl(10% 50% 50%)": invalid hue "10%"`)
+
+	_, err = ParseColor("rgb(a b c)")
+	assert.EqualError(t, err, `invalid color "rgb(a b c)": invalid value "a"`)
+
+	_, err = ParseColor("rgb(1 2 3 / )")
+	assert.EqualError(t, err, `invalid color "rgb(1 2 3 / )": missing alpha`)
+
+	_, err = ParseColor("foo(1 2 3)")
+	assert.Error(t, err)
+
+	assert.False(t, ValidateColor("rgb(1 2)"))
+	assert.False(t, ValidateColor("foo(1 2 3)"))
+}
+
+func TestOKLabRoundTrip(t *testing.T) {
+	for _, c := range []color.RGBA{{0, 0, 0, 255}, {255, 255, 255, 255}, {32, 160, 255, 255}, {200, 30, 90, 255}} {
+		assert.Equal(t, c, ToOKLab(c).ToRGBA())
+	}
+}
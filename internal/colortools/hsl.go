@@ -0,0 +1,68 @@
+package colortools
+
+import (
+	"image/color"
+	"math"
+)
+
+// HSL is a color in the HSL color space.
+type HSL struct {
+	// H is the hue, in degrees.
+	H float64
+	// S is the saturation, from 0 to 1.
+	S float64
+	// L is the lightness, from 0 to 1.
+	L float64
+}
+
+// ToHSL converts an RGB color to HSL.  Alpha is ignored.  For grays, the
+// hue is 0.
+func ToHSL(c color.RGBA) HSL {
+	r := float64(c.R) / 255
+	g := float64(c.G) / 255
+	b := float64(c.B) / 255
+
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	lightness := (max + min) / 2
+	delta := max - min
+
+	if delta == 0 {
+		return HSL{H: 0, S: 0, L: lightness}
+	}
+
+	saturation := delta / (1 - math.Abs(2*lightness-1))
+
+	var hue float64
+	switch max {
+	case r:
+		hue = math.Mod((g-b)/delta, 6)
+	case g:
+		hue = (b-r)/delta + 2
+	default:
+		hue = (r-g)/delta + 4
+	}
+	hue *= 60
+	if hue < 0 {
+		hue += 360
+	}
+
+	return HSL{H: hue, S: saturation, L: lightness}
+}
+
+// ToRGBA converts an HSL color to RGB.  The returned color is fully opaque.
+func (hsl HSL) ToRGBA() color.RGBA {
+	r, g, b := hslToRGB(hsl.H, clampUnit(hsl.S), clampUnit(hsl.L))
+	return color.RGBA{R: clampChannel(r * 255), G: clampChannel(g * 255), B: clampChannel(b * 255), A: 255}
+}
+
+// hslToRGB converts a color from HSL to RGB.  `hue` is in degrees, and
+// all other values are from 0 to 1.
+func hslToRGB(hue float64, saturation float64, lightness float64) (r float64, g float64, b float64) {
+	f := func(n float64) float64 {
+		k := math.Mod(n+hue/30, 12)
+		a := saturation * math.Min(lightness, 1-lightness)
+		return lightness - a*math.Max(-1, math.Min(math.Min(k-3, 9-k), 1))
+	}
+	return f(0), f(8), f(4)
+}
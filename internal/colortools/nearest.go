@@ -0,0 +1,103 @@
+package colortools
+
+import (
+	"image/color"
+	"sync"
+)
+
+// Ansi16Colors are the RGB values of the 16 basic ANSI colors.  Every terminal
+// has its own idea of what these colors should be, so these are the defaults
+// from xterm.
+var Ansi16Colors = [16]color.RGBA{
+	{0, 0, 0, 255},       // black
+	{205, 0, 0, 255},     // red
+	{0, 205, 0, 255},     // green
+	{205, 205, 0, 255},   // yellow
+	{0, 0, 238, 255},     // blue
+	{205, 0, 205, 255},   // magenta
+	{0, 205, 205, 255},   // cyan
+	{229, 229, 229, 255}, // white
+	{127, 127, 127, 255}, // brightBlack
+	{255, 0, 0, 255},     // brightRed
+	{0, 255, 0, 255},     // brightGreen
+	{255, 255, 0, 255},   // brightYellow
+	{92, 92, 255, 255},   // brightBlue
+	{255, 0, 255, 255},   // brightMagenta
+	{0, 255, 255, 255},   // brightCyan
+	{255, 255, 255, 255}, // brightWhite
+}
+
+var paletteOnce sync.Once
+var ansi16Lab [16]OKLab
+var ansi256Lab [256]OKLab
+
+func initPalettes() {
+	paletteOnce.Do(func() {
+		for index, c := range Ansi16Colors {
+			ansi16Lab[index] = ToOKLab(c)
+		}
+		for index := 0; index < 256; index++ {
+			ansi256Lab[index] = ToOKLab(Ansi256ToRGBA(uint8(index)))
+		}
+	})
+}
+
+// Ansi256ToRGBA returns the RGB value of a color from the ANSI 256 color
+// palette.
+func Ansi256ToRGBA(code uint8) color.RGBA {
+	if code < 16 {
+		return Ansi16Colors[code]
+	}
+
+	if code >= 232 {
+		// Grayscale ramp.
+		gray := 8 + (code-232)*10
+		return color.RGBA{gray, gray, gray, 255}
+	}
+
+	// 6x6x6 color cube.
+	levels := [6]uint8{0, 95, 135, 175, 215, 255}
+	index := code - 16
+	return color.RGBA{levels[index/36], levels[(index/6)%6], levels[index%6], 255}
+}
+
+// NearestAnsi256 returns the code of the color in the ANSI 256 color palette
+// which looks most like the given color.
+//
+// This only considers the color cube and grayscale ramp (codes 16 to 255),
+// since the first 16 colors are often changed by terminal themes.
+func NearestAnsi256(c color.RGBA) uint8 {
+	initPalettes()
+	lab := ToOKLab(c)
+
+	best := 16
+	bestDistance := lab.DistanceSquared(ansi256Lab[best])
+	for index := 17; index < 256; index++ {
+		if distance := lab.DistanceSquared(ansi256Lab[index]); distance < bestDistance {
+			best = index
+			bestDistance = distance
+		}
+	}
+	return uint8(best)
+}
+
+// NearestAnsi returns the basic ANSI color code (30-37 or 90-97) for the
+// color which looks most like the given color.
+func NearestAnsi(c color.RGBA) uint8 {
+	initPalettes()
+	lab := ToOKLab(c)
+
+	best := 0
+	bestDistance := lab.DistanceSquared(ansi16Lab[best])
+	for index := 1; index < 16; index++ {
+		if distance := lab.DistanceSquared(ansi16Lab[index]); distance < bestDistance {
+			best = index
+			bestDistance = distance
+		}
+	}
+
+	if best < 8 {
+		return uint8(30 + best)
+	}
+	return uint8(90 + best - 8)
+}
@@ -0,0 +1,32 @@
+package colortools
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnsi256ToRGBA(t *testing.T) {
+	assert.Equal(t, color.RGBA{255, 0, 0, 255}, Ansi256ToRGBA(196))
+	assert.Equal(t, color.RGBA{0, 175, 255, 255}, Ansi256ToRGBA(39))
+	assert.Equal(t, color.RGBA{8, 8, 8, 255}, Ansi256ToRGBA(232))
+	assert.Equal(t, color.RGBA{238, 238, 238, 255}, Ansi256ToRGBA(255))
+}
+
+func TestNearestAnsi256(t *testing.T) {
+	// Exact matches.
+	assert.Equal(t, uint8(196), NearestAnsi256(color.RGBA{255, 0, 0, 255}))
+	assert.Equal(t, uint8(16), NearestAnsi256(color.RGBA{0, 0, 0, 255}))
+	assert.Equal(t, uint8(231), NearestAnsi256(color.RGBA{255, 255, 255, 255}))
+
+	// Dark grays should map to the grayscale ramp, not the color cube.
+	assert.Equal(t, uint8(236), NearestAnsi256(color.RGBA{0x30, 0x30, 0x30, 255}))
+}
+
+func TestNearestAnsi(t *testing.T) {
+	assert.Equal(t, uint8(30), NearestAnsi(color.RGBA{0, 0, 0, 255}))
+	assert.Equal(t, uint8(91), NearestAnsi(color.RGBA{255, 0, 32, 255}))
+	assert.Equal(t, uint8(97), NearestAnsi(color.RGBA{250, 250, 250, 255}))
+	assert.Equal(t, uint8(32), NearestAnsi(color.RGBA{20, 180, 20, 255}))
+}
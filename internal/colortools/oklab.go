@@ -0,0 +1,128 @@
+package colortools
+
+import (
+	"image/color"
+	"math"
+)
+
+// OKLab is a color in the OKLab color space.  OKLab is a perceptual color
+// space, so the distance between two colors in OKLab is a good approximation
+// of how different the colors look.  See https://bottosson.github.io/posts/oklab/.
+type OKLab struct {
+	// L is the perceived lightness, from 0 to 1.
+	L float64
+	// A is how green (negative) or red (positive) the color is.
+	A float64
+	// B is how blue (negative) or yellow (positive) the color is.
+	B float64
+}
+
+// srgbToLinear converts an sRGB channel into a linear value from 0 to 1.
+func srgbToLinear(channel uint8) float64 {
+	value := float64(channel) / 255
+	if value <= 0.04045 {
+		return value / 12.92
+	}
+	return math.Pow((value+0.055)/1.055, 2.4)
+}
+
+// ToOKLab converts an RGB color to OKLab.  Alpha is ignored.
+func ToOKLab(c color.RGBA) OKLab {
+	r := srgbToLinear(c.R)
+	g := srgbToLinear(c.G)
+	b := srgbToLinear(c.B)
+
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+
+	return OKLab{
+		L: 0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		A: 1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		B: 0.0259040371*l + 0.7827717662*m - 0.8086757660*s,
+	}
+}
+
+// DistanceSquared returns the square of the euclidean distance between two
+// OKLab colors.
+func (lab OKLab) DistanceSquared(other OKLab) float64 {
+	dL := lab.L - other.L
+	dA := lab.A - other.A
+	dB := lab.B - other.B
+	return dL*dL + dA*dA + dB*dB
+}
+
+// linearToSrgb converts a linear value from 0 to 1 into an sRGB channel.
+// Values outside of the sRGB gamut are clipped.
+func linearToSrgb(value float64) uint8 {
+	if value <= 0.0031308 {
+		value *= 12.92
+	} else {
+		value = 1.055*math.Pow(value, 1/2.4) - 0.055
+	}
+	return clampChannel(value * 255)
+}
+
+// clampChannel rounds a value to the nearest integer from 0 to 255.
+func clampChannel(value float64) uint8 {
+	if value <= 0 || math.IsNaN(value) {
+		return 0
+	}
+	if value >= 255 {
+		return 255
+	}
+	return uint8(math.Round(value))
+}
+
+// ToRGBA converts an OKLab color to RGB.  Colors outside of the sRGB gamut
+// are clipped.  The returned color is fully opaque.
+func (lab OKLab) ToRGBA() color.RGBA {
+	l := lab.L + 0.3963377774*lab.A + 0.2158037573*lab.B
+	m := lab.L - 0.1055613458*lab.A - 0.0638541728*lab.B
+	s := lab.L - 0.0894841775*lab.A - 1.2914855480*lab.B
+
+	l = l * l * l
+	m = m * m * m
+	s = s * s * s
+
+	return color.RGBA{
+		R: linearToSrgb(+4.0767416621*l - 3.3077115913*m + 0.2309699292*s),
+		G: linearToSrgb(-1.2684380046*l + 2.6097574011*m - 0.3413193965*s),
+		B: linearToSrgb(-0.0041960863*l - 0.7034186147*m + 1.7076147010*s),
+		A: 255,
+	}
+}
+
+// OKLCh is a color in the OKLCh color space, which is the OKLab color space
+// using polar coordinates.
+type OKLCh struct {
+	// L is the perceived lightness, from 0 to 1.
+	L float64
+	// C is the chroma, or how colorful the color is.
+	C float64
+	// H is the hue, in degrees.
+	H float64
+}
+
+// ToOKLab converts an OKLCh color to OKLab.
+func (lch OKLCh) ToOKLab() OKLab {
+	radians := lch.H * math.Pi / 180
+	return OKLab{
+		L: lch.L,
+		A: lch.C * math.Cos(radians),
+		B: lch.C * math.Sin(radians),
+	}
+}
+
+// ToOKLCh converts an OKLab color to OKLCh.
+func (lab OKLab) ToOKLCh() OKLCh {
+	hue := math.Atan2(lab.B, lab.A) * 180 / math.Pi
+	if hue < 0 {
+		hue += 360
+	}
+	return OKLCh{
+		L: lab.L,
+		C: math.Sqrt(lab.A*lab.A + lab.B*lab.B),
+		H: hue,
+	}
+}
@@ -51,7 +51,9 @@ func ColorToHex(c color.RGBA) string {
 	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
 }
 
-// ParseColor converts a string to an RGBA color.
+// ParseColor converts a string to an RGBA color.  `str` can be a hex color,
+// a CSS color name, or a CSS color function like "hsl(200 80% 50%)".  If the
+// color has an alpha channel, it is stored (unpremultiplied) in `A`.
 func ParseColor(str string) (color.RGBA, error) {
 	if str == "" {
 		return color.RGBA{}, errUnrecognized
@@ -59,6 +61,8 @@ func ParseColor(str string) (color.RGBA, error) {
 		return HexToColor(str)
 	} else if c, ok := CSSColors[str]; ok {
 		return c, nil
+	} else if isColorFunction(str) {
+		return parseColorFunction(str)
 	}
 
 	return color.RGBA{}, errUnrecognized
@@ -72,6 +76,9 @@ func ValidateColor(str string) bool {
 		return ValidateHexColor(str)
 	} else if _, ok := CSSColors[str]; ok {
 		return true
+	} else if isColorFunction(str) {
+		_, err := parseColorFunction(str)
+		return err == nil
 	}
 
 	return false
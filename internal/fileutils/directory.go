@@ -22,8 +22,9 @@ type Directory interface {
 	// HasFile returns true if the directory contains a file with the specified name.
 	HasFile(name string) bool
 	// HasGlob returns true if the directory contains files which match the
-	// specified glob pattern.  The pattern is the same as for `match.Match`.
-	// The pattern may describe hierarchical paths like "*/*.js".
+	// specified glob pattern.  The pattern is the same as for `path.Match`.
+	// The pattern may describe hierarchical paths like "*/*.js", and may
+	// use "**" to match zero or more directories, like "src/**/*.kt".
 	HasGlob(glob string) bool
 	// FileSystem returns an fs.FS rooted in the directory.
 	FileSystem() fs.FS
@@ -72,6 +73,13 @@ type directory struct {
 	testInstance bool
 	// scanTimeout is the maximum time to wait for loading directory contents to complete.
 	scanTimeout time.Duration
+	globsLock   sync.Mutex
+	// globs caches the results of HasGlob.
+	globs map[string]bool
+	// globTime is the total time spent searching for "**" globs so far.  Every
+	// "**" glob shares the same `scanTimeout` budget, so checking many globs
+	// can't take longer than `scanTimeout` in total.
+	globTime time.Duration
 }
 
 // Note that caller must have mutex.
@@ -183,12 +191,36 @@ func (dir *directory) HasFile(name string) bool {
 }
 
 func (dir *directory) HasGlob(name string) bool {
-	// TODO: Would be nice if this supported "**" style globs.
-	files, err := fs.Glob(dir.fileSystem, name)
-	if err != nil {
+	dir.globsLock.Lock()
+	defer dir.globsLock.Unlock()
+
+	if result, ok := dir.globs[name]; ok {
+		return result
+	}
+
+	// Only "**" globs can search through an unbounded number of directories,
+	// so other globs have no deadline.
+	var deadline time.Time
+	start := time.Now()
+	limited := dir.scanTimeout > 0 && strings.Contains(name, "**")
+	if limited {
+		deadline = start.Add(dir.scanTimeout - dir.globTime)
+	}
+
+	result, timedOut := matchGlob(dir.fileSystem, name, deadline)
+	if limited {
+		dir.globTime += time.Since(start)
+	}
+	if timedOut {
+		// We don't know if there's a match, so don't cache the result.
 		return false
 	}
-	return len(files) > 0
+
+	if dir.globs == nil {
+		dir.globs = make(map[string]bool)
+	}
+	dir.globs[name] = result
+	return result
 }
 
 func (dir *directory) FileSystem() fs.FS {
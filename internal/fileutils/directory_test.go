@@ -5,6 +5,7 @@ import (
 	"runtime"
 	"testing"
 	"testing/fstest"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -69,3 +70,110 @@ func TestHasFile(t *testing.T) {
 
 	assert.Equal(t, true, dir.HasFile("src"))
 }
+
+func TestHasGlob(t *testing.T) {
+	fsys := fstest.MapFS{
+		"README.md":                      &fstest.MapFile{},
+		"app/App.csproj":                 &fstest.MapFile{},
+		"src/main/kotlin/com/foo/Foo.kt": &fstest.MapFile{},
+		"src/index.js":                   &fstest.MapFile{},
+		".git/objects/a.kt":              &fstest.MapFile{},
+	}
+
+	dir := NewDirectoryTestFS("/foo/bar", fsys)
+
+	assert.Equal(t, true, dir.HasGlob("*.md"))
+	assert.Equal(t, true, dir.HasGlob("*/*.js"))
+	assert.Equal(t, true, dir.HasGlob("**/*.md"))
+	assert.Equal(t, true, dir.HasGlob("**/*.csproj"))
+	assert.Equal(t, true, dir.HasGlob("src/**/*.kt"))
+	assert.Equal(t, true, dir.HasGlob("src/**/foo/*.kt"))
+	assert.Equal(t, true, dir.HasGlob("src/**/**/Foo.kt"))
+	assert.Equal(t, true, dir.HasGlob("src/**"))
+
+	assert.Equal(t, false, dir.HasGlob("*.kt"))
+	assert.Equal(t, false, dir.HasGlob("app/**/*.kt"))
+	assert.Equal(t, false, dir.HasGlob("**/*.go"))
+	assert.Equal(t, false, dir.HasGlob("[.kt"))
+
+	// "**" should not descend into hidden directories.
+	assert.Equal(t, false, dir.HasGlob("**/objects/*.kt"))
+	assert.Equal(t, true, dir.HasGlob(".git/**/*.kt"))
+}
+
+func TestHasGlobMaxDepth(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a/b/c/d/e/f/g/h/shallow.txt": &fstest.MapFile{},
+		"a/b/c/d/e/f/g/h/i/deep.txt":  &fstest.MapFile{},
+	}
+
+	dir := NewDirectoryTestFS("/foo/bar", fsys)
+
+	assert.Equal(t, true, dir.HasGlob("**/shallow.txt"))
+	assert.Equal(t, false, dir.HasGlob("**/deep.txt"))
+	assert.Equal(t, true, dir.HasGlob("a/**/deep.txt"))
+
+	// Should give up if the search takes too long.
+	matched, timedOut := matchGlob(fsys, "**/shallow.txt", time.Now().Add(-time.Second))
+	assert.Equal(t, false, matched)
+	assert.Equal(t, true, timedOut)
+}
+
+func TestHasGlobSkipsHeavyDirectories(t *testing.T) {
+	fsys := fstest.MapFS{
+		"node_modules/foo/index.ts": &fstest.MapFile{},
+		"src/__pycache__/foo.pyc":   &fstest.MapFile{},
+	}
+
+	dir := NewDirectoryTestFS("/foo/bar", fsys)
+
+	assert.Equal(t, false, dir.HasGlob("**/*.ts"))
+	assert.Equal(t, false, dir.HasGlob("**/*.pyc"))
+	assert.Equal(t, true, dir.HasGlob("node_modules/**/*.ts"))
+}
+
+func TestHasGlobSharedDeadline(t *testing.T) {
+	fsys := fstest.MapFS{
+		"README.md":      &fstest.MapFile{},
+		"docs/README.md": &fstest.MapFile{},
+	}
+
+	dir := NewDirectoryTestFS("/foo/bar", fsys).(*directory)
+	dir.scanTimeout = time.Hour
+	assert.Equal(t, true, dir.HasGlob("*.md"))
+
+	// Once "**" globs have used up the scan timeout, they should give up, and
+	// the result should not be cached.
+	dir.globTime = dir.scanTimeout
+	assert.Equal(t, false, dir.HasGlob("**/README.md"))
+	_, cached := dir.globs["**/README.md"]
+	assert.Equal(t, false, cached)
+
+	// Results found before the deadline are still cached.
+	assert.Equal(t, true, dir.HasGlob("*.md"))
+
+	// Globs without "**" don't count against the scan timeout.
+	assert.Equal(t, true, dir.HasGlob("docs/*.md"))
+}
+
+func TestHasGlobAfterScanTimeout(t *testing.T) {
+	fsys := fstest.MapFS{
+		"README.md":      &fstest.MapFile{},
+		"docs/README.md": &fstest.MapFile{},
+	}
+
+	dir := NewDirectoryTestFS("/foo/bar", fsys).(*directory)
+	dir.scanTimeout = 20 * time.Millisecond
+	assert.Equal(t, true, dir.HasGlob("*.md"))
+
+	// Time spent outside of HasGlob (e.g. running other modules) shouldn't
+	// count against the scan timeout.
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, true, dir.HasGlob("**/README.md"))
+	assert.Equal(t, true, dir.HasGlob("docs/*.md"))
+}
+
+func TestValidateGlob(t *testing.T) {
+	assert.NoError(t, ValidateGlob("src/**/*.kt"))
+	assert.Error(t, ValidateGlob("src/**/[.kt"))
+}
@@ -0,0 +1,146 @@
+package fileutils
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/jwalton/kitsch/internal/kitsch/log"
+)
+
+// maxGlobDepth is the maximum number of directories a "**" in a glob will
+// descend into.
+const maxGlobDepth = 8
+
+// ValidateGlob returns an error if the given glob pattern is invalid.
+func ValidateGlob(pattern string) error {
+	for _, segment := range strings.Split(pattern, "/") {
+		if segment == "**" {
+			continue
+		}
+		if _, err := path.Match(segment, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skippedDirectories are directories "**" will not descend into, because
+// they tend to contain a huge number of files which aren't part of the
+// project itself.
+var skippedDirectories = map[string]bool{
+	"node_modules":     true,
+	"bower_components": true,
+	"__pycache__":      true,
+}
+
+// globMatcher finds files matching a glob pattern in a file system.
+type globMatcher struct {
+	fsys fs.FS
+	// deadline is the time to give up searching, or the zero time if there
+	// is no deadline.
+	deadline time.Time
+	timedOut bool
+}
+
+// matchGlob returns true if any file in `fsys` matches the given pattern.
+// The pattern is the same as for `path.Match`, except that a "**" path
+// segment matches zero or more directories.  "**" will not descend into
+// hidden directories (like ".git"), directories like "node_modules", or more
+// than `maxGlobDepth` directories deep.  If `deadline` is not the zero time,
+// then matchGlob gives up if no match is found by the deadline, and returns
+// false with `timedOut` set to true.
+func matchGlob(fsys fs.FS, pattern string, deadline time.Time) (matched bool, timedOut bool) {
+	if ValidateGlob(pattern) != nil {
+		return false, false
+	}
+
+	matcher := globMatcher{fsys: fsys, deadline: deadline}
+	result := matcher.match(".", strings.Split(pattern, "/"), 0)
+	if matcher.timedOut {
+		log.Info("Timed out searching for files matching ", pattern)
+	}
+	return result, !result && matcher.timedOut
+}
+
+// match returns true if the path segments in `segments` match some file
+// relative to `dir`.  `depth` is the number of directories "**" segments
+// have descended into so far.
+func (matcher *globMatcher) match(dir string, segments []string, depth int) bool {
+	if len(segments) == 0 {
+		return true
+	}
+
+	if !matcher.deadline.IsZero() && time.Now().After(matcher.deadline) {
+		matcher.timedOut = true
+		return false
+	}
+
+	segment := segments[0]
+	rest := segments[1:]
+
+	if segment == "**" {
+		// "**/**" is the same as "**".
+		for len(rest) > 0 && rest[0] == "**" {
+			rest = rest[1:]
+		}
+
+		// Try matching zero directories first.
+		if matcher.match(dir, rest, depth) {
+			return true
+		}
+		if depth >= maxGlobDepth {
+			return false
+		}
+
+		entries, err := fs.ReadDir(matcher.fsys, dir)
+		if err != nil {
+			return false
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || skippedDirectories[entry.Name()] {
+				continue
+			}
+			if matcher.match(path.Join(dir, entry.Name()), segments, depth+1) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !hasGlobMeta(segment) {
+		// No need to read the whole directory for a plain file name.
+		info, err := fs.Stat(matcher.fsys, path.Join(dir, segment))
+		if err != nil {
+			return false
+		}
+		if len(rest) == 0 {
+			return true
+		}
+		return info.IsDir() && matcher.match(path.Join(dir, segment), rest, depth)
+	}
+
+	entries, err := fs.ReadDir(matcher.fsys, dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if matched, _ := path.Match(segment, entry.Name()); !matched {
+			continue
+		}
+		if len(rest) == 0 {
+			return true
+		}
+		if entry.IsDir() && matcher.match(path.Join(dir, entry.Name()), rest, depth) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasGlobMeta returns true if the given path segment contains any special
+// glob characters.
+func hasGlobMeta(segment string) bool {
+	return strings.ContainsAny(segment, `*?[\`)
+}
@@ -27,6 +27,12 @@ func isExecutable(file string) error {
 // LookPathSafe is like exec.LookPath, but does not search in ".", even
 // if it is in the path.
 func LookPathSafe(file string) (string, error) {
+	return LookPathSafeEnv(file, os.Getenv)
+}
+
+// LookPathSafeEnv is like LookPathSafe, but reads PATH with `getenv` instead
+// of from the current process's environment.
+func LookPathSafeEnv(file string, getenv func(key string) string) (string, error) {
 	// If the file is absolute, don't try to search in the path.
 	if filepath.IsAbs(file) {
 		err := isExecutable(file)
@@ -42,7 +48,7 @@ func LookPathSafe(file string) (string, error) {
 	}
 
 	// Search the path.
-	path := os.Getenv("PATH")
+	path := getenv("PATH")
 	for _, dir := range filepath.SplitList(path) {
 		if dir == "" || dir == "." {
 			continue
@@ -50,8 +50,14 @@ func findExecutable(file string, exts []string) (string, error) {
 // LookPathSafe is like exec.LookPath, but does not search in ".", even
 // if it is in the path.
 func LookPathSafe(file string) (string, error) {
+	return LookPathSafeEnv(file, os.Getenv)
+}
+
+// LookPathSafeEnv is like LookPathSafe, but reads PATH and PATHEXT with
+// `getenv` instead of from the current process's environment.
+func LookPathSafeEnv(file string, getenv func(key string) string) (string, error) {
 	var exts []string
-	x := os.Getenv(`PATHEXT`)
+	x := getenv(`PATHEXT`)
 	if x != "" {
 		for _, e := range strings.Split(strings.ToLower(x), `;`) {
 			if e == "" {
@@ -76,7 +82,7 @@ func LookPathSafe(file string) (string, error) {
 	if f, err := findExecutable(filepath.Join(".", file), exts); err == nil {
 		return f, nil
 	}
-	path := os.Getenv("path")
+	path := getenv("PATH")
 	for _, dir := range filepath.SplitList(path) {
 		if dir == "." {
 			continue
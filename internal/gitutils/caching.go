@@ -1,6 +1,9 @@
 package gitutils
 
-import "sync"
+import (
+	"context"
+	"sync"
+)
 
 // caching is a gitutils that caches results - it assumes the underlying repo
 // is not going to change between calls.
@@ -25,7 +28,8 @@ type caching struct {
 	headInfo             *HeadInfo
 	stateOnce            sync.Once
 	state                *RepositoryState
-	statsOnce            sync.Once
+	statsMutex           sync.Mutex
+	haveStats            bool
 	stats                GitStats
 	statsError           error
 }
@@ -33,8 +37,8 @@ type caching struct {
 // NewCaching returns a new caching instance of Git.  The returned instance
 // assumes the repo does not change between calls, so will not recompute the
 // same values more than once.
-func NewCaching(pathToGit string, folder string) Git {
-	underlying := New(pathToGit, folder)
+func NewCaching(pathToGit string, folder string, environ []string) Git {
+	underlying := New(pathToGit, folder, environ)
 	if underlying == nil {
 		return nil
 	}
@@ -68,12 +72,12 @@ func (c *caching) GetUpstream(branch string) string {
 
 // GetAheadBehind returns how many commits ahead and behind the given
 // localRef is compared to remoteRef.
-func (c *caching) GetAheadBehind(localRef string, remoteRef string) (ahead int, behind int, err error) {
+func (c *caching) GetAheadBehind(ctx context.Context, localRef string, remoteRef string) (ahead int, behind int, err error) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
 	if c.aheadBehindLocalRef != localRef || c.aheadBehindRemoteRef != remoteRef {
-		ahead, behind, err = c.underlying.GetAheadBehind(localRef, remoteRef)
+		ahead, behind, err = c.underlying.GetAheadBehind(ctx, localRef, remoteRef)
 		if err != nil {
 			return 0, 0, err
 		}
@@ -112,9 +116,19 @@ func (c *caching) State() RepositoryState {
 }
 
 // Stats returns status counters for the given git repo.
-func (c *caching) Stats() (GitStats, error) {
-	c.statsOnce.Do(func() {
-		c.stats, c.statsError = c.underlying.Stats()
-	})
+func (c *caching) Stats(ctx context.Context) (GitStats, error) {
+	c.statsMutex.Lock()
+	defer c.statsMutex.Unlock()
+
+	if !c.haveStats {
+		stats, err := c.underlying.Stats(ctx)
+		if ctx.Err() != nil {
+			// If the caller gave up on this call, don't cache the result - the
+			// next caller might be more patient.
+			return stats, err
+		}
+		c.stats, c.statsError = stats, err
+		c.haveStats = true
+	}
 	return c.stats, c.statsError
 }
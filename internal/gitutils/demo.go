@@ -1,6 +1,7 @@
 package gitutils
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 )
@@ -60,7 +61,7 @@ func (git DemoGit) GetUpstream(branch string) string {
 
 // GetAheadBehind returns how many commits ahead and behind the given
 // localRef is compared to remoteRef.
-func (git DemoGit) GetAheadBehind(localRef string, remoteRef string) (ahead int, behind int, err error) {
+func (git DemoGit) GetAheadBehind(ctx context.Context, localRef string, remoteRef string) (ahead int, behind int, err error) {
 	if git.IsDetached || git.CurrentBranchUpstream == "" {
 		return 0, 0, nil
 	}
@@ -99,6 +100,6 @@ func (git DemoGit) State() RepositoryState {
 }
 
 // Stats returns status counters for the given git repo.
-func (git DemoGit) Stats() (GitStats, error) {
+func (git DemoGit) Stats(ctx context.Context) (GitStats, error) {
 	return git.CurrentStats, nil
 }
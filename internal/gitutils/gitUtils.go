@@ -1,6 +1,7 @@
 package gitutils
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/fs"
@@ -23,6 +24,9 @@ var ErrNoGit = errors.New("Git is not installed")
 type gitUtils struct {
 	// pathToGit is the path to the git executable.
 	pathToGit string
+	// environ is the environment to run git in, or nil to use the
+	// environment of the current process.
+	environ []string
 	// The go-git/v5 storer.
 	storer *filesystem.Storage
 	// fsys is an fs.FS instance bound to the root of the git repository.
@@ -56,19 +60,23 @@ type Git interface {
 	// an empty string otherwise.
 	GetUpstream(branch string) string
 	// GetAheadBehind returns how many commits ahead and behind the given
-	// localRef is compared to remoteRef.
-	GetAheadBehind(localRef string, remoteRef string) (ahead int, behind int, err error)
+	// localRef is compared to remoteRef.  If git needs to be run to find the
+	// answer, it will be killed when ctx is done.
+	GetAheadBehind(ctx context.Context, localRef string, remoteRef string) (ahead int, behind int, err error)
 	// Head returns information about the current head.
 	Head(maxTagsToSearch int) (head HeadInfo, err error)
 	// State returns the current state of the repository.
 	State() RepositoryState
-	// Stats returns status counters for the given git repo.
-	Stats() (GitStats, error)
+	// Stats returns status counters for the given git repo.  The git process
+	// used to compute the stats will be killed when ctx is done.
+	Stats(ctx context.Context) (GitStats, error)
 }
 
 // New returns a new instance of `GitUtils` for the specified folder.
-// If the folder is not a git repository, it will return nil.
-func New(pathToGit string, folder string) Git {
+// If the folder is not a git repository, it will return nil.  git will be
+// run with the given environment, or with the environment of the current
+// process if `environ` is nil.
+func New(pathToGit string, folder string, environ []string) Git {
 	// Resolve the path to the git executable
 	pathToGit, err := fileutils.LookPathSafe(pathToGit)
 	if err != nil {
@@ -93,6 +101,7 @@ func New(pathToGit string, folder string) Git {
 
 	return &gitUtils{
 		pathToGit: pathToGit,
+		environ:   environ,
 		storer:    storer,
 		fsys:      fsys,
 		repoRoot:  gitRoot,
@@ -109,14 +118,16 @@ func FindGitRoot(cwd string) string {
 }
 
 // git will run a git command in the root folder of the git repository.
-// Returns empty string if there was an error running the command.
-func (g *gitUtils) git(args ...string) (string, error) {
+// Returns empty string if there was an error running the command.  The command
+// will be killed if ctx is done before it finishes.
+func (g *gitUtils) git(ctx context.Context, args ...string) (string, error) {
 	if g.pathToGit == "" {
 		return "", ErrNoGit
 	}
 
-	cmd := exec.Command(g.pathToGit, args...)
+	cmd := exec.CommandContext(ctx, g.pathToGit, args...)
 	cmd.Dir = g.repoRoot
+	cmd.Env = g.environ
 
 	out, err := cmd.Output()
 	if err != nil {
@@ -162,7 +173,7 @@ func (g *gitUtils) GetUpstream(branch string) string {
 
 // GetAheadBehind returns how many commits ahead and behind the given
 // localRef is compared to remoteRef.
-func (g *gitUtils) GetAheadBehind(localRef string, remoteRef string) (ahead int, behind int, err error) {
+func (g *gitUtils) GetAheadBehind(ctx context.Context, localRef string, remoteRef string) (ahead int, behind int, err error) {
 	// If branch and compareToBranch are the same hash, we're done.
 	branchRef, err := g.storer.Reference(plumbing.ReferenceName(localRef))
 	if err == nil {
@@ -176,7 +187,7 @@ func (g *gitUtils) GetAheadBehind(localRef string, remoteRef string) (ahead int,
 
 	// If not, we need to shell-out to git to find the answer.
 	// TODO: Rewrite this as native, as there is quite a bit of overhead going to the shell.
-	aheadBehind, err := g.git("rev-list", "--left-right", "--count", localRef+"..."+remoteRef)
+	aheadBehind, err := g.git(ctx, "rev-list", "--left-right", "--count", localRef+"..."+remoteRef)
 	if err != nil {
 		return 0, 0, err
 	}
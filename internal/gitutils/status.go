@@ -1,17 +1,21 @@
 package gitutils
 
-import "os/exec"
+import (
+	"context"
+	"os/exec"
+)
 
 // Stats returns status counters for the given git repo.
-func (utils *gitUtils) Stats() (GitStats, error) {
+func (utils *gitUtils) Stats(ctx context.Context) (GitStats, error) {
 	if utils.pathToGit == "" {
 		return GitStats{}, ErrNoGit
 	}
 
-	// This uses `exec.Command` instead of go-git's worktree.Status(),
+	// This uses `exec.CommandContext` instead of go-git's worktree.Status(),
 	// because worktree.Status() is crazy slow: https://github.com/go-git/go-git/issues/181
-	cmd := exec.Command(utils.pathToGit, "status", "-z")
+	cmd := exec.CommandContext(ctx, utils.pathToGit, "status", "-z")
 	cmd.Dir = utils.repoRoot
+	cmd.Env = utils.environ
 	stats := GitStats{}
 	cmd.Stdout = &statusWriter{stats: &stats}
 	err := cmd.Run()
@@ -3,17 +3,106 @@
 package condition
 
 import (
+	"fmt"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"sort"
 	"strings"
 
 	"github.com/jwalton/kitsch/internal/fileutils"
+	"gopkg.in/yaml.v3"
 )
 
 //go:generate go run ../genSchema/main.go --private Conditions
 
+// Environment provides the information needed to decide if conditions are met.
+type Environment interface {
+	// GetWorkingDirectory returns the current working directory.
+	GetWorkingDirectory() fileutils.Directory
+	// Getenv returns the value of the specified environment variable.
+	Getenv(key string) string
+	// IsGitRepo returns true if the current working directory is inside a
+	// git repo.
+	IsGitRepo() bool
+	// IsRoot returns true if the current user is the root user.
+	IsRoot() bool
+	// PreviousCommandStatus returns the exit status of the previous command.
+	PreviousCommandStatus() int
+}
+
+// sshEnvVars are environment variables which are set in an SSH session.
+var sshEnvVars = []string{"SSH_CONNECTION", "SSH_CLIENT", "SSH_TTY"}
+
+// EnvConditions is a map where keys are the names of environment variables,
+// and values are regular expressions to match against the value of the
+// variable.  An empty regular expression matches any non-empty value.  In
+// YAML, this can be written as a map, or as a list of variable names.
+type EnvConditions map[string]string
+
+// UnmarshalYAML unmarshals a list of variable names or a map of regexes.
+func (envConditions *EnvConditions) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.SequenceNode {
+		var names []string
+		err := node.Decode(&names)
+		if err != nil {
+			return err
+		}
+		*envConditions = make(EnvConditions, len(names))
+		for _, name := range names {
+			(*envConditions)[name] = ""
+		}
+		return nil
+	}
+
+	var values map[string]string
+	err := node.Decode(&values)
+	if err != nil {
+		return err
+	}
+	*envConditions = values
+	return nil
+}
+
+// matches returns true if the given variable is set and matches the regex
+// for the variable.
+func (envConditions EnvConditions) matches(environment Environment, name string) bool {
+	value := environment.Getenv(name)
+	if value == "" {
+		return false
+	}
+
+	pattern := envConditions[name]
+	if pattern == "" {
+		return true
+	}
+
+	matched, err := regexp.MatchString(pattern, value)
+	return err == nil && matched
+}
+
+// validate returns an error if any regex is invalid.
+func (envConditions EnvConditions) validate(field string) error {
+	names := make([]string, 0, len(envConditions))
+	for name := range envConditions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, err := regexp.Compile(envConditions[name]); err != nil {
+			return fmt.Errorf("invalid regex for %s %s: %q: %w", field, name, envConditions[name], err)
+		}
+	}
+	return nil
+}
+
 // Conditions represents a condition which can be used in configuration files to
 // specify when a module or project should be used.
+//
+// The "if" conditions are "or"ed together - if any of them are met, then the
+// Conditions are met.  If there are no "if" conditions, then the Conditions
+// are met as long as the "onlyIf" conditions are met.
 type Conditions struct {
 	// IfAncestorFiles is a list of files to search for in the current folder,
 	// or another folder higher up in the directory structure.
@@ -22,6 +111,35 @@ type Conditions struct {
 	IfFiles []string `yaml:"ifFiles"`
 	// IfExtensions is a list of extensions to search for in the current folder.
 	IfExtensions []string `yaml:"ifExtensions"`
+	// IfGlobs is a list of glob patterns to search for in the current folder.
+	// "**" matches zero or more directories (e.g. "src/**/*.kt").
+	IfGlobs []string `yaml:"ifGlobs"`
+	// IfEnv is a list of environment variables.  This condition is met if any
+	// of the variables are set, and match the given regular expression if
+	// there is one.
+	IfEnv EnvConditions `yaml:"ifEnv"`
+	// IfNotEnv is a list of environment variables.  This condition is met if
+	// any of the variables are not set, or do not match the given regular
+	// expression.
+	IfNotEnv EnvConditions `yaml:"ifNotEnv"`
+	// IfGitRepo is met if the current folder is inside a git repo.
+	IfGitRepo bool `yaml:"ifGitRepo"`
+	// IfSSH is met if the shell is running in an SSH session.
+	IfSSH bool `yaml:"ifSSH"`
+	// IfRoot is met if the current user is root.
+	IfRoot bool `yaml:"ifRoot"`
+	// IfStatusNonZero is met if the previous command failed.
+	IfStatusNonZero bool `yaml:"ifStatusNonZero"`
+	// IfCommandExists is a list of commands to search for in the path.
+	IfCommandExists []string `yaml:"ifCommandExists"`
+	// AllOf is a list of conditions.  This condition is met if every
+	// condition in the list is met.
+	AllOf []Conditions `yaml:"allOf"`
+	// AnyOf is a list of conditions.  This condition is met if any condition
+	// in the list is met.
+	AnyOf []Conditions `yaml:"anyOf"`
+	// Not is met if the given conditions are not met.
+	Not *Conditions `yaml:"not"`
 	// OnlyIfOS is a list of operating systems.  If the current GOOS is not in
 	// the list, then the Conditions are not met, even if other conditions would
 	// be satisfied.
@@ -35,21 +153,72 @@ type Conditions struct {
 // IsEmpty returns true if the condition has no conditions to match.
 func (conditions *Conditions) IsEmpty() bool {
 	return conditions == nil ||
-		(len(conditions.IfAncestorFiles) == 0 &&
-			len(conditions.IfFiles) == 0 &&
-			len(conditions.IfExtensions) == 0 &&
+		(!conditions.hasIfConditions() &&
 			len(conditions.OnlyIfOS) == 0 &&
 			len(conditions.OnlyIfNotOS) == 0)
 
 }
 
-// Matches returns true if this condition is matched in the given directory
+// hasIfConditions returns true if any of the "if" conditions are set.
+func (conditions *Conditions) hasIfConditions() bool {
+	return len(conditions.IfAncestorFiles) != 0 ||
+		len(conditions.IfFiles) != 0 ||
+		len(conditions.IfExtensions) != 0 ||
+		len(conditions.IfGlobs) != 0 ||
+		len(conditions.IfEnv) != 0 ||
+		len(conditions.IfNotEnv) != 0 ||
+		conditions.IfGitRepo ||
+		conditions.IfSSH ||
+		conditions.IfRoot ||
+		conditions.IfStatusNonZero ||
+		len(conditions.IfCommandExists) != 0 ||
+		len(conditions.AllOf) != 0 ||
+		len(conditions.AnyOf) != 0 ||
+		conditions.Not != nil
+}
+
+// Matches returns true if this condition is matched in the given environment
 // and for the current operating system.
-func (conditions *Conditions) Matches(directory fileutils.Directory) bool {
+func (conditions *Conditions) Matches(environment Environment) bool {
 	if !conditions.matchesOS() {
 		return false
 	}
 
+	if !conditions.hasIfConditions() {
+		return true
+	}
+
+	// Check the cheap conditions first.
+	for name := range conditions.IfEnv {
+		if conditions.IfEnv.matches(environment, name) {
+			return true
+		}
+	}
+
+	for name := range conditions.IfNotEnv {
+		if !conditions.IfNotEnv.matches(environment, name) {
+			return true
+		}
+	}
+
+	if conditions.IfRoot && environment.IsRoot() {
+		return true
+	}
+
+	if conditions.IfStatusNonZero && environment.PreviousCommandStatus() != 0 {
+		return true
+	}
+
+	if conditions.IfSSH {
+		for _, name := range sshEnvVars {
+			if environment.Getenv(name) != "" {
+				return true
+			}
+		}
+	}
+
+	directory := environment.GetWorkingDirectory()
+
 	for _, extension := range conditions.IfExtensions {
 		if directory.HasExtension(extension) {
 			return true
@@ -77,9 +246,80 @@ func (conditions *Conditions) Matches(directory fileutils.Directory) bool {
 		}
 	}
 
+	// Globs are the most expensive file check, so do them last.
+	for _, glob := range conditions.IfGlobs {
+		if directory.HasGlob(glob) {
+			return true
+		}
+	}
+
+	for _, command := range conditions.IfCommandExists {
+		if _, err := fileutils.LookPathSafeEnv(command, environment.Getenv); err == nil {
+			return true
+		}
+	}
+
+	if conditions.IfGitRepo && environment.IsGitRepo() {
+		return true
+	}
+
+	if len(conditions.AllOf) != 0 {
+		allMatch := true
+		for index := range conditions.AllOf {
+			if !conditions.AllOf[index].Matches(environment) {
+				allMatch = false
+				break
+			}
+		}
+		if allMatch {
+			return true
+		}
+	}
+
+	for index := range conditions.AnyOf {
+		if conditions.AnyOf[index].Matches(environment) {
+			return true
+		}
+	}
+
+	if conditions.Not != nil && !conditions.Not.Matches(environment) {
+		return true
+	}
+
 	return false
 }
 
+// Validate returns an error if any of the conditions are invalid.
+func (conditions *Conditions) Validate() error {
+	if conditions == nil {
+		return nil
+	}
+
+	if err := conditions.IfEnv.validate("ifEnv"); err != nil {
+		return err
+	}
+	if err := conditions.IfNotEnv.validate("ifNotEnv"); err != nil {
+		return err
+	}
+	for _, glob := range conditions.IfGlobs {
+		if err := fileutils.ValidateGlob(glob); err != nil {
+			return fmt.Errorf("invalid glob for ifGlobs: %q: %w", glob, err)
+		}
+	}
+
+	for index := range conditions.AllOf {
+		if err := conditions.AllOf[index].Validate(); err != nil {
+			return err
+		}
+	}
+	for index := range conditions.AnyOf {
+		if err := conditions.AnyOf[index].Validate(); err != nil {
+			return err
+		}
+	}
+	return conditions.Not.Validate()
+}
+
 func (conditions *Conditions) matchesOS() bool {
 	if len(conditions.OnlyIfNotOS) > 0 {
 		if contains(conditions.OnlyIfNotOS, runtime.GOOS) {
@@ -9,7 +9,17 @@ var conditionsJSONSchema = `{
     "ifAncestorFiles": {"type": "array", "description": "IfAncestorFiles is a list of files to search for in the current folder, or another folder higher up in the directory structure.", "items": {"type": "string", "description": ""}},
     "ifFiles": {"type": "array", "description": "IfFiles is a list of files to search for in the current folder.", "items": {"type": "string", "description": ""}},
     "ifExtensions": {"type": "array", "description": "IfExtensions is a list of extensions to search for in the current folder.", "items": {"type": "string", "description": ""}},
+    "ifGlobs": {"type": "array", "description": "IfGlobs is a list of glob patterns to search for in the current folder.  \"**\" matches zero or more directories (e.g. \"src/**/*.kt\").", "items": {"type": "string", "description": ""}},
+    "ifEnv": {"description": "IfEnv is a list of environment variables.  This condition is met if any of the variables are set, and match the given regular expression if there is one.", "oneOf": [{"type": "array", "items": {"type": "string"}}, {"type": "object", "additionalProperties": {"type": "string"}}]},
+    "ifNotEnv": {"description": "IfNotEnv is a list of environment variables.  This condition is met if any of the variables are not set, or do not match the given regular expression.", "oneOf": [{"type": "array", "items": {"type": "string"}}, {"type": "object", "additionalProperties": {"type": "string"}}]},
+    "ifGitRepo": {"type": "boolean", "description": "IfGitRepo is met if the current folder is inside a git repo."},
+    "ifSSH": {"type": "boolean", "description": "IfSSH is met if the shell is running in an SSH session."},
+    "ifRoot": {"type": "boolean", "description": "IfRoot is met if the current user is root."},
+    "ifStatusNonZero": {"type": "boolean", "description": "IfStatusNonZero is met if the previous command failed."},
+    "ifCommandExists": {"type": "array", "description": "IfCommandExists is a list of commands to search for in the path.", "items": {"type": "string", "description": ""}},
+    "allOf": {"type": "array", "description": "AllOf is a list of conditions.  This condition is met if every condition in the list is met.", "items": {"$ref": "#/definitions/Conditions"}},
+    "anyOf": {"type": "array", "description": "AnyOf is a list of conditions.  This condition is met if any condition in the list is met.", "items": {"$ref": "#/definitions/Conditions"}},
+    "not": {"$ref": "#/definitions/Conditions", "description": "Not is met if the given conditions are not met."},
     "onlyIfOS": {"type": "array", "description": "OnlyIfOS is a list of operating systems.  If the current GOOS is not in the list, then the Conditions are not met, even if other conditions would be satisfied.", "items": {"type": "string", "description": ""}},
     "onlyIfNotOS": {"type": "array", "description": "OnlyIfNotOS is a list of operating systems.  If the current GOOS is in the list, then the Conditions are not met, even if other conditions would be satisfied.", "items": {"type": "string", "description": ""}}
   }}`
-
@@ -1,13 +1,47 @@
 package condition
 
 import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 	"testing/fstest"
 
+	"github.com/MakeNowJust/heredoc"
 	"github.com/jwalton/kitsch/internal/fileutils"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
 )
 
+type testEnvironment struct {
+	directory fileutils.Directory
+	env       map[string]string
+	gitRepo   bool
+	root      bool
+	status    int
+}
+
+func (environment *testEnvironment) GetWorkingDirectory() fileutils.Directory {
+	return environment.directory
+}
+
+func (environment *testEnvironment) Getenv(key string) string {
+	return environment.env[key]
+}
+
+func (environment *testEnvironment) IsGitRepo() bool {
+	return environment.gitRepo
+}
+
+func (environment *testEnvironment) IsRoot() bool {
+	return environment.root
+}
+
+func (environment *testEnvironment) PreviousCommandStatus() int {
+	return environment.status
+}
+
 func TestIfFiles(t *testing.T) {
 	directory := fileutils.NewDirectoryTestFS(
 		"/foo/bar",
@@ -20,31 +54,196 @@ func TestIfFiles(t *testing.T) {
 			},
 		},
 	)
+	environment := &testEnvironment{directory: directory}
 
 	conditions := Conditions{IfFiles: []string{"version.txt"}}
-	assert.Equal(t, true, conditions.Matches(directory))
+	assert.Equal(t, true, conditions.Matches(environment))
 
 	conditions = Conditions{IfFiles: []string{"nothere.txt"}}
-	assert.Equal(t, false, conditions.Matches(directory))
+	assert.Equal(t, false, conditions.Matches(environment))
 
 	conditions = Conditions{IfExtensions: []string{"go"}}
-	assert.Equal(t, true, conditions.Matches(directory))
+	assert.Equal(t, true, conditions.Matches(environment))
 
 	conditions = Conditions{IfExtensions: []string{".go"}}
-	assert.Equal(t, true, conditions.Matches(directory))
+	assert.Equal(t, true, conditions.Matches(environment))
 
 	conditions = Conditions{IfExtensions: []string{".js"}}
-	assert.Equal(t, false, conditions.Matches(directory))
+	assert.Equal(t, false, conditions.Matches(environment))
 
 	conditions = Conditions{IfFiles: []string{"nothere.txt"}, IfExtensions: []string{".js"}}
-	assert.Equal(t, false, conditions.Matches(directory))
+	assert.Equal(t, false, conditions.Matches(environment))
 
 	conditions = Conditions{IfFiles: []string{"version.txt"}, IfExtensions: []string{".js"}}
-	assert.Equal(t, true, conditions.Matches(directory))
+	assert.Equal(t, true, conditions.Matches(environment))
 
 	conditions = Conditions{IfFiles: []string{"nothere.txt"}, IfExtensions: []string{".go"}}
-	assert.Equal(t, true, conditions.Matches(directory))
+	assert.Equal(t, true, conditions.Matches(environment))
 
 	conditions = Conditions{IfFiles: []string{"version.txt"}, IfExtensions: []string{".go"}}
-	assert.Equal(t, true, conditions.Matches(directory))
+	assert.Equal(t, true, conditions.Matches(environment))
+}
+
+func TestIfGlobs(t *testing.T) {
+	directory := fileutils.NewDirectoryTestFS(
+		"/foo/bar",
+		fstest.MapFS{
+			"src/main/kotlin/Main.kt": &fstest.MapFile{},
+			"app/App.csproj":          &fstest.MapFile{},
+		},
+	)
+	environment := &testEnvironment{directory: directory}
+
+	conditions := Conditions{IfGlobs: []string{"**/*.csproj"}}
+	assert.Equal(t, true, conditions.Matches(environment))
+
+	conditions = Conditions{IfGlobs: []string{"src/**/*.kt"}}
+	assert.Equal(t, true, conditions.Matches(environment))
+
+	conditions = Conditions{IfGlobs: []string{"*.kt", "**/*.java"}}
+	assert.Equal(t, false, conditions.Matches(environment))
+}
+
+func TestIfEnv(t *testing.T) {
+	environment := &testEnvironment{
+		directory: fileutils.NewDirectoryTestFS("/foo/bar", fstest.MapFS{}),
+		env:       map[string]string{"KUBECONFIG": "/home/me/.kube/config", "AWS_PROFILE": "staging"},
+	}
+
+	conditions := Conditions{IfEnv: EnvConditions{"KUBECONFIG": ""}}
+	assert.Equal(t, true, conditions.Matches(environment))
+
+	conditions = Conditions{IfEnv: EnvConditions{"NOT_SET": ""}}
+	assert.Equal(t, false, conditions.Matches(environment))
+
+	conditions = Conditions{IfEnv: EnvConditions{"AWS_PROFILE": "^prod"}}
+	assert.Equal(t, false, conditions.Matches(environment))
+
+	conditions = Conditions{IfEnv: EnvConditions{"AWS_PROFILE": "^stag"}}
+	assert.Equal(t, true, conditions.Matches(environment))
+
+	conditions = Conditions{IfNotEnv: EnvConditions{"AWS_PROFILE": "^prod"}}
+	assert.Equal(t, true, conditions.Matches(environment))
+
+	conditions = Conditions{IfNotEnv: EnvConditions{"KUBECONFIG": ""}}
+	assert.Equal(t, false, conditions.Matches(environment))
+
+	conditions = Conditions{IfSSH: true}
+	assert.Equal(t, false, conditions.Matches(environment))
+
+	environment.env["SSH_CONNECTION"] = "10.0.0.1 1234 10.0.0.2 22"
+	assert.Equal(t, true, conditions.Matches(environment))
+}
+
+func TestEnvConditionsYAML(t *testing.T) {
+	var conditions Conditions
+	err := yaml.Unmarshal([]byte(heredoc.Doc(`
+		ifEnv: [KUBECONFIG, AWS_PROFILE]
+		ifNotEnv:
+		  TERM: "^dumb$"
+	`)), &conditions)
+	assert.Nil(t, err)
+	assert.Equal(t, EnvConditions{"KUBECONFIG": "", "AWS_PROFILE": ""}, conditions.IfEnv)
+	assert.Equal(t, EnvConditions{"TERM": "^dumb$"}, conditions.IfNotEnv)
+}
+
+func TestEnvironmentConditions(t *testing.T) {
+	environment := &testEnvironment{
+		directory: fileutils.NewDirectoryTestFS("/foo/bar", fstest.MapFS{}),
+	}
+
+	conditions := Conditions{IfGitRepo: true, IfRoot: true, IfStatusNonZero: true}
+	assert.Equal(t, false, conditions.Matches(environment))
+
+	environment.gitRepo = true
+	assert.Equal(t, true, conditions.Matches(environment))
+
+	environment.gitRepo = false
+	environment.root = true
+	assert.Equal(t, true, conditions.Matches(environment))
+
+	environment.root = false
+	environment.status = 1
+	assert.Equal(t, true, conditions.Matches(environment))
+
+	conditions = Conditions{IfCommandExists: []string{"kitsch-no-such-command"}}
+	assert.Equal(t, false, conditions.Matches(environment))
+}
+
+func TestIfCommandExistsUsesEnvironmentPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test creates a unix executable")
+	}
+
+	bin := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(bin, "kitsch-test-command"), []byte("#!/bin/sh\n"), 0700))
+
+	environment := &testEnvironment{
+		directory: fileutils.NewDirectoryTestFS("/foo/bar", fstest.MapFS{}),
+		env:       map[string]string{},
+	}
+	conditions := Conditions{IfCommandExists: []string{"kitsch-test-command"}}
+	assert.Equal(t, false, conditions.Matches(environment))
+
+	environment.env["PATH"] = bin
+	assert.Equal(t, true, conditions.Matches(environment))
+}
+
+func TestCompositeConditions(t *testing.T) {
+	environment := &testEnvironment{
+		directory: fileutils.NewDirectoryTestFS("/foo/bar", fstest.MapFS{
+			"charts": &fstest.MapFile{Mode: fs.ModeDir},
+		}),
+		env: map[string]string{"KUBECONFIG": "/home/me/.kube/config"},
+	}
+
+	// Show kubernetes only when KUBECONFIG is set and a charts folder exists.
+	conditions := Conditions{AllOf: []Conditions{
+		{IfEnv: EnvConditions{"KUBECONFIG": ""}},
+		{IfFiles: []string{"charts"}},
+	}}
+	assert.Equal(t, true, conditions.Matches(environment))
+
+	delete(environment.env, "KUBECONFIG")
+	assert.Equal(t, false, conditions.Matches(environment))
+
+	conditions = Conditions{AnyOf: []Conditions{
+		{IfEnv: EnvConditions{"KUBECONFIG": ""}},
+		{IfFiles: []string{"charts"}},
+	}}
+	assert.Equal(t, true, conditions.Matches(environment))
+
+	conditions = Conditions{Not: &Conditions{IfFiles: []string{"charts"}}}
+	assert.Equal(t, false, conditions.Matches(environment))
+
+	conditions = Conditions{Not: &Conditions{IfFiles: []string{"missing"}}}
+	assert.Equal(t, true, conditions.Matches(environment))
+
+	// A condition with only "onlyIf" conditions should match if the OS matches.
+	conditions = Conditions{AllOf: []Conditions{
+		{OnlyIfOS: []string{runtime.GOOS}},
+		{IfFiles: []string{"charts"}},
+	}}
+	assert.Equal(t, true, conditions.Matches(environment))
+
+	conditions = Conditions{AllOf: []Conditions{
+		{OnlyIfNotOS: []string{runtime.GOOS}},
+		{IfFiles: []string{"charts"}},
+	}}
+	assert.Equal(t, false, conditions.Matches(environment))
+}
+
+func TestValidateConditions(t *testing.T) {
+	conditions := Conditions{AnyOf: []Conditions{
+		{IfEnv: EnvConditions{"AWS_PROFILE": "(prod"}},
+	}}
+	assert.EqualError(t, conditions.Validate(),
+		"invalid regex for ifEnv AWS_PROFILE: \"(prod\": error parsing regexp: missing closing ): `(prod`",
+	)
+
+	conditions = Conditions{IfGlobs: []string{"src/**/[.kt"}}
+	assert.EqualError(t, conditions.Validate(), `invalid glob for ifGlobs: "src/**/[.kt": syntax error in pattern`)
+
+	conditions = Conditions{Not: &Conditions{IfNotEnv: EnvConditions{"TERM": "^dumb$"}}}
+	assert.Nil(t, conditions.Validate())
 }
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"github.com/jwalton/kitsch/internal/kitsch/styling"
+	"github.com/jwalton/kitsch/internal/kitsch/theme"
+	"gopkg.in/yaml.v3"
+)
+
+// Colors is the collection of custom colors in a configuration file.  Colors
+// can be given different values for terminals with light and dark
+// backgrounds by putting them under "light" or "dark":
+//
+//	colors:
+//	  $warning: "#ff8000"
+//	  light:
+//	    $fg: "#000"
+//	  dark:
+//	    $fg: "#fff"
+type Colors struct {
+	// Default are the colors which are used no matter what the terminal's
+	// background is.
+	Default map[string]styling.CustomColor
+	// Light are colors which are used when the terminal has a light
+	// background.  These take precedence over Default.
+	Light map[string]styling.CustomColor
+	// Dark are colors which are used when the terminal has a dark background,
+	// or when we can't tell what the background is.  These take precedence
+	// over Default.
+	Dark map[string]styling.CustomColor
+}
+
+// UnmarshalYAML unmarshals the "colors" section of a configuration file.
+func (colors *Colors) UnmarshalYAML(node *yaml.Node) error {
+	var values map[string]yaml.Node
+	err := node.Decode(&values)
+	if err != nil {
+		return err
+	}
+
+	result := Colors{}
+	for name, value := range values {
+		switch name {
+		case string(theme.Light):
+			err = value.Decode(&result.Light)
+		case string(theme.Dark):
+			err = value.Decode(&result.Dark)
+		default:
+			var color styling.CustomColor
+			err = value.Decode(&color)
+			if err == nil {
+				if result.Default == nil {
+					result.Default = map[string]styling.CustomColor{}
+				}
+				result.Default[name] = color
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	*colors = result
+	return nil
+}
+
+// ForTheme returns the custom colors to use for a terminal with the given
+// theme.
+func (colors Colors) ForTheme(t theme.Theme) map[string]styling.CustomColor {
+	variant := colors.Dark
+	if t == theme.Light {
+		variant = colors.Light
+	}
+
+	result := make(map[string]styling.CustomColor, len(colors.Default)+len(variant))
+	for name, color := range colors.Default {
+		result[name] = color
+	}
+	for name, color := range variant {
+		result[name] = color
+	}
+	return result
+}
+
+// merge copies any colors in `parent` that are not in the receiver into the
+// receiver.
+func (colors *Colors) merge(parent Colors) {
+	colors.Default = mergeColorMaps(colors.Default, parent.Default)
+	colors.Light = mergeColorMaps(colors.Light, parent.Light)
+	colors.Dark = mergeColorMaps(colors.Dark, parent.Dark)
+}
+
+// clone returns a copy of the receiver.
+func (colors Colors) clone() Colors {
+	return Colors{
+		Default: cloneColorMap(colors.Default),
+		Light:   cloneColorMap(colors.Light),
+		Dark:    cloneColorMap(colors.Dark),
+	}
+}
+
+// mergeColorMaps copies any colors in `parent` that are not in `child` into
+// `child`, and returns the result.
+func mergeColorMaps(child map[string]styling.CustomColor, parent map[string]styling.CustomColor) map[string]styling.CustomColor {
+	if child == nil {
+		return parent
+	}
+
+	for key, value := range parent {
+		if _, ok := child[key]; !ok {
+			child[key] = value
+		}
+	}
+	return child
+}
+
+func cloneColorMap(colors map[string]styling.CustomColor) map[string]styling.CustomColor {
+	if colors == nil {
+		return nil
+	}
+
+	result := make(map[string]styling.CustomColor, len(colors))
+	for key, value := range colors {
+		result[key] = value
+	}
+	return result
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jwalton/kitsch/internal/kitsch/styling"
+	"github.com/jwalton/kitsch/internal/kitsch/theme"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThemeColors(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"kitsch.yaml": `
+			extends: ./base.yaml
+			colors:
+			  $warning: orange
+			  light:
+			    $fg: black
+		`,
+		"base.yaml": `
+			colors:
+			  $fg: gray
+			  $bg: black
+			  light:
+			    $fg: "#333"
+			    $bg: white
+			  dark:
+			    $fg: white
+			prompt:
+			  type: text
+			  text: "$ "
+		`,
+	})
+
+	config, err := LoadConfigFromFile(filepath.Join(dir, "kitsch.yaml"), true)
+	assert.Nil(t, err)
+
+	assert.Equal(t, map[string]styling.CustomColor{
+		"$warning": {Truecolor: "orange"},
+		"$fg":      {Truecolor: "black"},
+		"$bg":      {Truecolor: "white"},
+	}, config.Colors.ForTheme(theme.Light))
+
+	assert.Equal(t, map[string]styling.CustomColor{
+		"$warning": {Truecolor: "orange"},
+		"$fg":      {Truecolor: "white"},
+		"$bg":      {Truecolor: "black"},
+	}, config.Colors.ForTheme(theme.Dark))
+
+	// If we don't know the theme, we should use the dark colors.
+	assert.Equal(t, config.Colors.ForTheme(theme.Dark), config.Colors.ForTheme(theme.Unknown))
+}
+
+func TestThemeColorsInvalid(t *testing.T) {
+	var config Config
+	err := config.LoadFromYaml([]byte("colors:\n  light: red\n"), true)
+	assert.Error(t, err)
+}
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	// embed required for sample configs below.
 	_ "embed"
@@ -20,50 +22,297 @@ import (
 const defaultTimeout = 500
 const defaultScanTimeout = 100
 
+// presetPrefix is the prefix used in `extends` to refer to a built-in preset.
+const presetPrefix = "preset:"
+
 var errNoPrompt = errors.New("configuration is missing prompt")
 
+// stringList is a list of strings which can be written in YAML as either a
+// single string or a list of strings.
+type stringList []string
+
+func (list *stringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		var value string
+		err := node.Decode(&value)
+		if err != nil {
+			return err
+		}
+		*list = stringList{value}
+		return nil
+	}
+
+	var values []string
+	err := node.Decode(&values)
+	if err != nil {
+		return err
+	}
+	*list = values
+	return nil
+}
+
+// ExtendsError is returned when a configuration file extends a configuration
+// which can't be loaded.
+type ExtendsError struct {
+	// Chain is the list of configuration files, starting from the first
+	// configuration file loaded and ending with the configuration file that
+	// could not be loaded.
+	Chain []string
+	// Err is the reason the configuration file could not be loaded.
+	Err error
+}
+
+func (err *ExtendsError) Error() string {
+	return fmt.Sprintf("unable to load parent configuration %s: %v", strings.Join(err.Chain, " -> "), err.Err)
+}
+
+func (err *ExtendsError) Unwrap() error {
+	return err.Err
+}
+
 // Config represents a configuration file.
 type Config struct {
 	// Timeout is the default module timeout, in milliseconds.
 	Timeout int64 `yaml:"timeout"`
 	// ScanTimeout is the maximum time to spend scanning files in the current directory.
 	ScanTimeout int64 `yaml:"scanTimeout"`
-	// Extends is the name of another configuration file to extend.
-	Extends string `yaml:"extends"`
-	// Colors is a collection of custom colors.
-	Colors map[string]string `yaml:"colors"`
+	// Extends is a list of other configuration files to extend.  Relative paths
+	// are relative to the extending file.  If more than one configuration is
+	// given, later configurations take precedence over earlier ones.
+	Extends stringList `yaml:"extends"`
+	// Colors is a collection of custom colors.  Each color can optionally have
+	// fallbacks for terminals which don't support truecolor, and can have
+	// different values for light and dark terminal themes.
+	Colors Colors `yaml:"colors"`
 	// ProjectTypes are used when detecting the project type of the current folder.
 	ProjectsTypes []projects.ProjectType `yaml:"projectTypes"`
 	// Prompt is the module to use to display the prompt.
 	Prompt modules.ModuleWrapper
+	// PromptOverrides is a map of changes to make to individual modules in the
+	// prompt, keyed by module ID path.  This is applied after the prompt is
+	// copied from the parent configuration.  This only changes `Prompt` - the
+	// right, transient, and continuation prompts are not affected.
+	PromptOverrides map[string]PromptOverride `yaml:"promptOverrides"`
+	// RightPrompt is the module to use to display the right-hand prompt.
+	RightPrompt modules.ModuleWrapper `yaml:"rightPrompt"`
+	// TransientPrompt is the module to use to replace the prompt with after a
+	// command has been entered.
+	TransientPrompt modules.ModuleWrapper `yaml:"transientPrompt"`
+	// ContinuationPrompt is the module to use to display the prompt when a
+	// command spans multiple lines (PS2).
+	ContinuationPrompt modules.ModuleWrapper `yaml:"continuationPrompt"`
+	// Files is the list of configuration files that were read to build this
+	// configuration, including any files it extends.  Built-in presets are
+	// not included.  Files which were extended but could not be read are
+	// included, since creating or fixing them would change this configuration.
+	Files []string `yaml:"-"`
 }
 
 func newConfig() Config {
 	return Config{Timeout: defaultTimeout, ScanTimeout: defaultScanTimeout}
 }
 
-// LoadFromYaml loads the configuration file from a YAML file.
+// LoadFromYaml loads the configuration file from a YAML file.  Any relative
+// paths in `extends` will be resolved relative to the current directory.
 func (c *Config) LoadFromYaml(yamlData []byte, strict bool) error {
-	decoder := yaml.NewDecoder(bytes.NewReader(yamlData))
-	decoder.KnownFields(strict)
-	err := decoder.Decode(c)
+	return c.loadFromYaml(yamlData, strict, nil, readConfigFile)
+}
+
+// fileReader reads the contents of a configuration file or preset.
+type fileReader func(file string) ([]byte, error)
+
+// loadFromYaml loads the configuration from a YAML file.  `chain` is the list
+// of configuration files which lead to this file being loaded, ending with
+// this file, or empty if we don't know where this file came from.  Parent
+// configurations are read with `readFile`.  If a parent configuration can't be
+// loaded or the prompt overrides can't be applied, this returns an error if
+// `strict` is true, or prints a warning otherwise.
+func (c *Config) loadFromYaml(yamlData []byte, strict bool, chain []string, readFile fileReader) error {
+	err := c.decode(yamlData, strict)
 	if err != nil {
 		return err
 	}
 
-	if c.Extends != "" {
-		// Load the parent configuration.
-		parentConfig, err := LoadConfigFromFile(c.Extends, strict)
+	if len(chain) > 0 {
+		c.addFile(chain[len(chain)-1])
+	}
+
+	err = c.mergeParents(strict, chain, readFile)
+	if err != nil {
+		return err
+	}
+
+	return c.applyPromptOverrides(strict)
+}
+
+// decode decodes a YAML configuration file into the receiver.
+func (c *Config) decode(yamlData []byte, strict bool) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(yamlData))
+	decoder.KnownFields(strict)
+	return decoder.Decode(c)
+}
+
+// mergeParents loads each configuration in `Extends` with `readFile` and
+// merges it into the receiver.
+func (c *Config) mergeParents(strict bool, chain []string, readFile fileReader) error {
+	// Merge in parents in reverse order, so later parents take precedence
+	// over earlier ones.
+	for index := len(c.Extends) - 1; index >= 0; index-- {
+		parentConfig, err := loadParentConfig(c.Extends[index], strict, chain, readFile)
 		if err != nil {
-			log.Warn(fmt.Sprintf("Unable to load parent configuration file: %s: %v", c.Extends, err))
-		} else {
-			c.mergeParent(parentConfig)
+			if strict {
+				return err
+			}
+			log.Warn(err.Error())
+			c.addFile(resolveExtends(c.Extends[index], chain))
+			continue
 		}
+		c.mergeParent(parentConfig)
 	}
 
 	return nil
 }
 
+// addFile adds a file to the list of files this configuration was read from.
+// Presets are ignored.
+func (c *Config) addFile(file string) {
+	if strings.HasPrefix(file, presetPrefix) {
+		return
+	}
+	for _, existing := range c.Files {
+		if existing == file {
+			return
+		}
+	}
+	c.Files = append(c.Files, file)
+}
+
+// applyPromptOverrides applies `PromptOverrides` to the prompt.  If the
+// overrides can't be applied, this returns an error if `strict` is true, or
+// prints a warning otherwise.
+func (c *Config) applyPromptOverrides(strict bool) error {
+	prompt, err := overridePrompt(c.Prompt, c.PromptOverrides)
+	if err != nil {
+		if strict {
+			return err
+		}
+		log.Warn(err.Error())
+		return nil
+	}
+
+	c.Prompt = prompt
+	return nil
+}
+
+// loadParentConfig loads a configuration named in `extends`.  `chain` is the
+// list of configuration files that lead to this configuration being loaded.
+// Files are read with `readFile`.
+func loadParentConfig(name string, strict bool, chain []string, readFile fileReader) (*Config, error) {
+	file := resolveExtends(name, chain)
+
+	parentChain := make([]string, len(chain), len(chain)+1)
+	copy(parentChain, chain)
+	parentChain = append(parentChain, file)
+
+	for _, ancestor := range chain {
+		if ancestor == file {
+			return nil, &ExtendsError{Chain: parentChain, Err: errors.New("cycle detected")}
+		}
+	}
+
+	yamlData, err := readFile(file)
+	if err != nil {
+		return nil, &ExtendsError{Chain: parentChain, Err: err}
+	}
+
+	config := newConfig()
+	err = config.loadFromYaml(yamlData, strict, parentChain, readFile)
+	if err != nil {
+		var extendsErr *ExtendsError
+		if errors.As(err, &extendsErr) {
+			return nil, err
+		}
+		return nil, &ExtendsError{Chain: parentChain, Err: err}
+	}
+
+	// Record where each module came from, so problems with inherited modules
+	// can be reported against the right file.
+	for _, prompt := range config.prompts() {
+		setModuleFiles(prompt, file)
+	}
+
+	return &config, nil
+}
+
+// prompts returns a pointer to each prompt in the configuration.
+func (c *Config) prompts() []*modules.ModuleWrapper {
+	return []*modules.ModuleWrapper{&c.Prompt, &c.RightPrompt, &c.TransientPrompt, &c.ContinuationPrompt}
+}
+
+// walkModules calls `fn` for `wrapper` and every module beneath it.
+func walkModules(wrapper *modules.ModuleWrapper, fn func(module *modules.ModuleWrapper)) {
+	if wrapper.Module == nil {
+		return
+	}
+	fn(wrapper)
+	if block, ok := wrapper.Module.(*modules.BlockModule); ok {
+		for index := range block.Modules {
+			walkModules(&block.Modules[index], fn)
+		}
+	}
+}
+
+// setModuleFiles sets the `File` of `wrapper`, and of every module beneath it,
+// to `file`, unless the module already came from another file.
+func setModuleFiles(wrapper *modules.ModuleWrapper, file string) {
+	walkModules(wrapper, func(module *modules.ModuleWrapper) {
+		if module.File == "" {
+			module.File = file
+		}
+	})
+}
+
+// resolveExtends returns the file or preset referred to by the given
+// `extends` entry, where `chain` is the list of configuration files that lead
+// to the entry.
+func resolveExtends(name string, chain []string) string {
+	if strings.HasPrefix(name, presetPrefix) {
+		return name
+	}
+
+	if !filepath.IsAbs(name) && len(chain) > 0 {
+		extendedFrom := chain[len(chain)-1]
+		if !strings.HasPrefix(extendedFrom, presetPrefix) {
+			name = filepath.Join(filepath.Dir(extendedFrom), name)
+		}
+	}
+
+	return absPath(name)
+}
+
+// absPath returns the absolute path for the given file.
+func absPath(file string) string {
+	result, err := filepath.Abs(file)
+	if err != nil {
+		return filepath.Clean(file)
+	}
+	return result
+}
+
+// readConfigFile reads the contents of a configuration file, or a built-in
+// preset if `file` starts with "preset:".
+func readConfigFile(file string) ([]byte, error) {
+	if strings.HasPrefix(file, presetPrefix) {
+		name := strings.TrimPrefix(file, presetPrefix)
+		yamlData, ok := sampleconfig.Presets[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown preset %q", name)
+		}
+		return yamlData, nil
+	}
+	return os.ReadFile(file)
+}
+
 // mergeParent merges the receiver into the parent configuration, and
 // stores the result in the receiver.
 func (c *Config) mergeParent(parent *Config) {
@@ -74,19 +323,30 @@ func (c *Config) mergeParent(parent *Config) {
 		child.Prompt = parent.Prompt
 	}
 
-	// Copy any colors in the parent that are not in the child.
-	if child.Colors == nil {
-		child.Colors = parent.Colors
-	} else {
-		for key, value := range parent.Colors {
-			if _, ok := child.Colors[key]; !ok {
-				child.Colors[key] = value
-			}
-		}
+	// If this child has no right prompt, copy the right prompt from the parent.
+	if child.RightPrompt.Module == nil {
+		child.RightPrompt = parent.RightPrompt
+	}
+
+	// If this child has no transient prompt, copy the transient prompt from the parent.
+	if child.TransientPrompt.Module == nil {
+		child.TransientPrompt = parent.TransientPrompt
 	}
 
+	// If this child has no continuation prompt, copy the continuation prompt from the parent.
+	if child.ContinuationPrompt.Module == nil {
+		child.ContinuationPrompt = parent.ContinuationPrompt
+	}
+
+	// Copy any colors in the parent that are not in the child.
+	child.Colors.merge(parent.Colors)
+
 	// Merge the project types.
 	child.ProjectsTypes = projects.MergeProjectTypes(child.ProjectsTypes, parent.ProjectsTypes, true)
+
+	for _, file := range parent.Files {
+		child.addFile(file)
+	}
 }
 
 // LoadConfigFromFile will load a configuration from a file.
@@ -97,7 +357,7 @@ func LoadConfigFromFile(configFile string, strict bool) (*Config, error) {
 		return nil, err
 	}
 
-	err = config.LoadFromYaml(yamlData, strict)
+	err = config.loadFromYaml(yamlData, strict, []string{absPath(configFile)}, readConfigFile)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/jwalton/kitsch/internal/kitsch/styling"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeConfigFiles writes the given files to a temporary folder, and returns
+// the folder.
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, contents := range files {
+		file := filepath.Join(dir, name)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(file), 0750))
+		assert.Nil(t, os.WriteFile(file, []byte(heredoc.Doc(contents)), 0600))
+	}
+	return dir
+}
+
+func TestExtendsList(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"kitsch.yaml": `
+			extends: [./parents/first.yaml, parents/second.yaml]
+			colors:
+			  $a: red
+		`,
+		"parents/first.yaml": `
+			extends: ./base.yaml
+			colors:
+			  $a: green
+			  $b: green
+			  $c: green
+		`,
+		"parents/second.yaml": `
+			colors:
+			  $b: blue
+		`,
+		"parents/base.yaml": `
+			colors:
+			  $d: white
+			prompt:
+			  type: text
+			  text: "$ "
+		`,
+	})
+
+	config, err := LoadConfigFromFile(filepath.Join(dir, "kitsch.yaml"), true)
+	assert.Nil(t, err)
+	assert.Equal(t, stringList{"./parents/first.yaml", "parents/second.yaml"}, config.Extends)
+	assert.Equal(t, map[string]styling.CustomColor{
+		"$a": {Truecolor: "red"},
+		"$b": {Truecolor: "blue"},
+		"$c": {Truecolor: "green"},
+		"$d": {Truecolor: "white"},
+	}, config.Colors.Default)
+	assert.NotNil(t, config.Prompt.Module)
+	assert.ElementsMatch(t, []string{
+		filepath.Join(dir, "kitsch.yaml"),
+		filepath.Join(dir, "parents/first.yaml"),
+		filepath.Join(dir, "parents/second.yaml"),
+		filepath.Join(dir, "parents/base.yaml"),
+	}, config.Files)
+}
+
+func TestExtendsPreset(t *testing.T) {
+	config := newConfig()
+	err := config.LoadFromYaml([]byte("extends: preset:powerline\n"), true)
+	assert.Nil(t, err)
+	assert.NotNil(t, config.Prompt.Module)
+
+	config = newConfig()
+	err = config.LoadFromYaml([]byte("extends: preset:nope\n"), true)
+	assert.EqualError(t, err, `unable to load parent configuration preset:nope: unknown preset "nope"`)
+}
+
+func TestExtendsCycle(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"a.yaml": `
+			extends: [./b.yaml]
+			prompt:
+			  type: text
+			  text: "$ "
+		`,
+		"b.yaml": "extends: ./a.yaml\n",
+	})
+
+	a := filepath.Join(dir, "a.yaml")
+	b := filepath.Join(dir, "b.yaml")
+
+	_, err := LoadConfigFromFile(a, true)
+	assert.EqualError(t, err, "unable to load parent configuration "+a+" -> "+b+" -> "+a+": cycle detected")
+
+	// When not strict, we should load as much of the configuration as we can.
+	config, err := LoadConfigFromFile(a, false)
+	assert.Nil(t, err)
+	assert.NotNil(t, config.Prompt.Module)
+}
+
+func TestExtendsMissingGrandparent(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"a.yaml": "extends: ./b.yaml\n",
+		"b.yaml": "extends: ./missing.yaml\n",
+	})
+
+	a := filepath.Join(dir, "a.yaml")
+	b := filepath.Join(dir, "b.yaml")
+	missing := filepath.Join(dir, "missing.yaml")
+
+	err := ValidateConfigurationFile(a, []byte("extends: ./b.yaml\n"))
+
+	var extendsErr *ExtendsError
+	assert.ErrorAs(t, err, &extendsErr)
+	assert.Equal(t, []string{a, b, missing}, extendsErr.Chain)
+	assert.True(t, os.IsNotExist(extendsErr.Err))
+}
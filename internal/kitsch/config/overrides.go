@@ -0,0 +1,274 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jwalton/kitsch/internal/kitsch/modules"
+	"gopkg.in/yaml.v3"
+)
+
+// PromptOverride describes a change to make to a single module in the prompt.
+// At most one of Replace, Remove, or Merge may be specified.
+type PromptOverride struct {
+	// Replace is a module to replace the module with.
+	Replace yaml.Node `yaml:"replace"`
+	// Remove removes the module from the prompt.
+	Remove bool `yaml:"remove"`
+	// Merge is a map of fields to set on the module.  Fields which aren't
+	// specified will be left as they are.
+	Merge yaml.Node `yaml:"merge"`
+	// InsertBefore is a list of modules to insert before the module.
+	InsertBefore []yaml.Node `yaml:"insertBefore"`
+	// InsertAfter is a list of modules to insert after the module.
+	InsertAfter []yaml.Node `yaml:"insertAfter"`
+}
+
+// overridePrompt applies the overrides in `overrides` to `prompt`, and
+// returns the new prompt.  The original prompt is not modified.  Keys in
+// `overrides` are paths of module IDs, separated by ".", where each ID is
+// the `id` of a module in a block, or the type of the module if it has no
+// `id`.  For example, "git.git_status" would be the "git_status" module inside
+// the block with the ID "git", inside the root block of the prompt.
+//
+// Overrides only apply to the prompt, not to the right, transient, or
+// continuation prompts.  Overrides are applied in sorted order of their keys.
+// It is an error for an override to change a module that another override
+// removes or replaces.
+func overridePrompt(prompt modules.ModuleWrapper, overrides map[string]PromptOverride) (modules.ModuleWrapper, error) {
+	if len(overrides) == 0 {
+		return prompt, nil
+	}
+	if prompt.YamlNode == nil {
+		return prompt, fmt.Errorf("promptOverrides: there is no prompt to override")
+	}
+
+	// Keep track of which node in the original prompt each node in the copy
+	// came from, so we can work out which file each module came from once
+	// the overrides are applied.
+	origins := map[*yaml.Node]*yaml.Node{}
+	root := copyNodeWithOrigins(prompt.YamlNode, origins)
+
+	paths := make([]string, 0, len(overrides))
+	for path := range overrides {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	err := checkOverrideConflicts(paths, overrides)
+	if err != nil {
+		return prompt, fmt.Errorf("promptOverrides: %w", err)
+	}
+
+	for _, path := range paths {
+		err := applyPromptOverride(root, path, overrides[path], origins)
+		if err != nil {
+			return prompt, fmt.Errorf("promptOverrides: %s: %w", path, err)
+		}
+	}
+
+	var result modules.ModuleWrapper
+	err = root.Decode(&result)
+	if err != nil {
+		return prompt, fmt.Errorf("promptOverrides: %w", err)
+	}
+
+	files := map[*yaml.Node]string{}
+	walkModules(&prompt, func(module *modules.ModuleWrapper) {
+		files[module.YamlNode] = module.File
+	})
+	walkModules(&result, func(module *modules.ModuleWrapper) {
+		if origin, ok := origins[module.YamlNode]; ok {
+			module.File = files[origin]
+		}
+	})
+
+	return result, nil
+}
+
+// checkOverrideConflicts returns an error if any override changes a module
+// inside a module which is removed or replaced by another override.  `paths`
+// must be sorted, so that each path comes after its ancestors.
+func checkOverrideConflicts(paths []string, overrides map[string]PromptOverride) error {
+	for index, parentPath := range paths {
+		parent := overrides[parentPath]
+
+		action := ""
+		switch {
+		case parent.Remove:
+			action = "removes"
+		case parent.Replace.Kind != 0:
+			action = "replaces"
+		case parent.Merge.Kind == yaml.MappingNode && mappingValue(&parent.Merge, "modules") != nil:
+			action = "replaces the modules in"
+		default:
+			continue
+		}
+
+		for _, childPath := range paths[index+1:] {
+			if strings.HasPrefix(childPath, parentPath+".") {
+				return fmt.Errorf("%s: conflicts with %s, which %s %q", childPath, parentPath, action, parentPath)
+			}
+		}
+	}
+	return nil
+}
+
+// applyPromptOverride applies a single override to the given prompt.
+// `origins` maps nodes in `root` to the nodes they were copied from.
+func applyPromptOverride(root *yaml.Node, path string, override PromptOverride, origins map[*yaml.Node]*yaml.Node) error {
+	actions := 0
+	hasReplace := override.Replace.Kind != 0
+	hasMerge := override.Merge.Kind != 0
+	for _, set := range []bool{hasReplace, override.Remove, hasMerge} {
+		if set {
+			actions++
+		}
+	}
+	if actions > 1 {
+		return fmt.Errorf("only one of replace, remove, or merge may be used")
+	}
+
+	list, index, err := findModuleNode(root, path)
+	if err != nil {
+		return err
+	}
+
+	module := list.Content[index]
+	switch {
+	case hasReplace:
+		module = copyNode(&override.Replace)
+	case hasMerge:
+		if override.Merge.Kind != yaml.MappingNode {
+			return fmt.Errorf("expected a map for merge (%d:%d)", override.Merge.Line, override.Merge.Column)
+		}
+		module = copyNodeWithOrigins(module, origins)
+		for i := 0; i+1 < len(override.Merge.Content); i += 2 {
+			setMappingValue(module, override.Merge.Content[i], override.Merge.Content[i+1])
+		}
+	}
+
+	replacement := make([]*yaml.Node, 0, len(override.InsertBefore)+len(override.InsertAfter)+1)
+	for index := range override.InsertBefore {
+		replacement = append(replacement, copyNode(&override.InsertBefore[index]))
+	}
+	if !override.Remove {
+		replacement = append(replacement, module)
+	}
+	for index := range override.InsertAfter {
+		replacement = append(replacement, copyNode(&override.InsertAfter[index]))
+	}
+
+	content := make([]*yaml.Node, 0, len(list.Content)-1+len(replacement))
+	content = append(content, list.Content[:index]...)
+	content = append(content, replacement...)
+	content = append(content, list.Content[index+1:]...)
+	list.Content = content
+
+	return nil
+}
+
+// findModuleNode finds the module with the given ID path in the given root
+// module.  Returns the "modules" list that contains the module, and the index
+// of the module in that list.
+func findModuleNode(root *yaml.Node, path string) (*yaml.Node, int, error) {
+	current := root
+	var list *yaml.Node
+	index := -1
+
+	ids := strings.Split(path, ".")
+	for depth, id := range ids {
+		list = mappingValue(current, "modules")
+		if list == nil || list.Kind != yaml.SequenceNode {
+			if depth == 0 {
+				return nil, -1, fmt.Errorf("prompt is not a block")
+			}
+			return nil, -1, fmt.Errorf("%q is not a block", strings.Join(ids[:depth], "."))
+		}
+
+		parent := "prompt"
+		if depth > 0 {
+			parent = fmt.Sprintf("%q", strings.Join(ids[:depth], "."))
+		}
+
+		index = -1
+		for childIndex, child := range list.Content {
+			if moduleNodeID(child) != id {
+				continue
+			}
+			if index != -1 {
+				return nil, -1, fmt.Errorf("more than one module with ID %q in %s", id, parent)
+			}
+			index = childIndex
+		}
+		if index == -1 {
+			return nil, -1, fmt.Errorf("no module with ID %q in %s", id, parent)
+		}
+
+		current = list.Content[index]
+	}
+
+	return list, index, nil
+}
+
+// moduleNodeID returns the ID of a module, or the type of the module if it
+// has no ID.
+func moduleNodeID(node *yaml.Node) string {
+	if id := mappingValue(node, "id"); id != nil && id.Value != "" {
+		return id.Value
+	}
+	if moduleType := mappingValue(node, "type"); moduleType != nil {
+		return moduleType.Value
+	}
+	return ""
+}
+
+// setMappingValue sets the value of `key` in the given mapping node, adding
+// the key if it isn't present.
+func setMappingValue(node *yaml.Node, key *yaml.Node, value *yaml.Node) {
+	for index := 0; index+1 < len(node.Content); index += 2 {
+		if node.Content[index].Value == key.Value {
+			node.Content[index+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, key, value)
+}
+
+// copyNodeWithOrigins returns a deep copy of a YAML node.  For every node in
+// the copy, `origins` will map the copy to the original node, or to the
+// original's origin if the original is itself a copy.
+func copyNodeWithOrigins(node *yaml.Node, origins map[*yaml.Node]*yaml.Node) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	result := *node
+	if origin, ok := origins[node]; ok {
+		origins[&result] = origin
+	} else {
+		origins[&result] = node
+	}
+	if node.Content != nil {
+		result.Content = make([]*yaml.Node, len(node.Content))
+		for index, child := range node.Content {
+			result.Content[index] = copyNodeWithOrigins(child, origins)
+		}
+	}
+	return &result
+}
+
+// copyNode returns a deep copy of a YAML node.
+func copyNode(node *yaml.Node) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	result := *node
+	if node.Content != nil {
+		result.Content = make([]*yaml.Node, len(node.Content))
+		for index, child := range node.Content {
+			result.Content[index] = copyNode(child)
+		}
+	}
+	return &result
+}
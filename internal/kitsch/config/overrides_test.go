@@ -0,0 +1,200 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+const overridesTestPrompt = `
+prompt:
+  type: block
+  modules:
+    - type: time
+    - type: block
+      id: git
+      modules:
+        - type: git_head
+        - type: git_status
+          style: blue
+    - type: directory
+`
+
+// loadOverridesTestConfig loads a configuration which extends
+// overridesTestPrompt, and returns the YAML for the resulting prompt.
+func loadOverridesTestConfig(t *testing.T, overrides string) (string, error) {
+	parent := newConfig()
+	err := parent.LoadFromYaml([]byte(overridesTestPrompt), true)
+	assert.Nil(t, err)
+
+	config := newConfig()
+	err = config.decode([]byte(heredoc.Doc(overrides)), true)
+	assert.Nil(t, err)
+	config.mergeParent(&parent)
+
+	err = config.applyPromptOverrides(true)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := yaml.Marshal(config.Prompt.YamlNode)
+	assert.Nil(t, err)
+	return string(result), nil
+}
+
+func TestPromptOverrides(t *testing.T) {
+	result, err := loadOverridesTestConfig(t, `
+		promptOverrides:
+		  git.git_status:
+		    merge:
+		      style: red
+		      template: "{{ .Text }}"
+		  git.git_head:
+		    remove: true
+		  time:
+		    replace:
+		      type: text
+		      text: hello
+		  directory:
+		    insertBefore:
+		      - type: text
+		        text: "["
+		    insertAfter:
+		      - type: text
+		        text: "]"
+	`)
+	assert.Nil(t, err)
+	assert.Equal(t, heredoc.Doc(`
+		type: block
+		modules:
+		    - type: text
+		      text: hello
+		    - type: block
+		      id: git
+		      modules:
+		        - type: git_status
+		          style: red
+		          template: "{{ .Text }}"
+		    - type: text
+		      text: "["
+		    - type: directory
+		    - type: text
+		      text: "]"
+	`), result)
+}
+
+func TestPromptOverridesDoesNotModifyParent(t *testing.T) {
+	parent := newConfig()
+	err := parent.LoadFromYaml([]byte(overridesTestPrompt), true)
+	assert.Nil(t, err)
+
+	_, err = overridePrompt(parent.Prompt, map[string]PromptOverride{"time": {Remove: true}})
+	assert.Nil(t, err)
+
+	result, err := yaml.Marshal(parent.Prompt.YamlNode)
+	assert.Nil(t, err)
+	assert.Contains(t, string(result), "type: time")
+}
+
+func TestPromptOverridesErrors(t *testing.T) {
+	_, err := loadOverridesTestConfig(t, `
+		promptOverrides:
+		  git.git_stats:
+		    remove: true
+	`)
+	assert.EqualError(t, err, `promptOverrides: git.git_stats: no module with ID "git_stats" in "git"`)
+
+	_, err = loadOverridesTestConfig(t, `
+		promptOverrides:
+		  time.foo:
+		    remove: true
+	`)
+	assert.EqualError(t, err, `promptOverrides: time.foo: "time" is not a block`)
+
+	_, err = loadOverridesTestConfig(t, `
+		promptOverrides:
+		  time:
+		    remove: true
+		    merge:
+		      style: red
+	`)
+	assert.EqualError(t, err, `promptOverrides: time: only one of replace, remove, or merge may be used`)
+}
+
+func TestPromptOverridesConflicts(t *testing.T) {
+	_, err := loadOverridesTestConfig(t, `
+		promptOverrides:
+		  git:
+		    remove: true
+		  git.git_status:
+		    merge:
+		      style: red
+	`)
+	assert.EqualError(t, err, `promptOverrides: git.git_status: conflicts with git, which removes "git"`)
+
+	_, err = loadOverridesTestConfig(t, `
+		promptOverrides:
+		  git:
+		    replace:
+		      type: text
+		      text: git
+		  git.git_head.foo:
+		    remove: true
+	`)
+	assert.EqualError(t, err, `promptOverrides: git.git_head.foo: conflicts with git, which replaces "git"`)
+
+	_, err = loadOverridesTestConfig(t, `
+		promptOverrides:
+		  git:
+		    merge:
+		      modules: []
+		  git.git_head:
+		    remove: true
+	`)
+	assert.EqualError(t, err, `promptOverrides: git.git_head: conflicts with git, which replaces the modules in "git"`)
+
+	// Changing a block and a module inside it is fine, as long as the block
+	// isn't removed or replaced.
+	result, err := loadOverridesTestConfig(t, `
+		promptOverrides:
+		  git:
+		    merge:
+		      style: green
+		  git.git_head:
+		    remove: true
+	`)
+	assert.Nil(t, err)
+	assert.Contains(t, result, "style: green")
+	assert.NotContains(t, result, "git_head")
+}
+
+func TestPromptOverridesOnlyChangePrompt(t *testing.T) {
+	parent := newConfig()
+	err := parent.LoadFromYaml([]byte(overridesTestPrompt+heredoc.Doc(`
+		rightPrompt:
+		  type: block
+		  modules:
+		    - type: time
+	`)), true)
+	assert.Nil(t, err)
+
+	config := newConfig()
+	err = config.decode([]byte(heredoc.Doc(`
+		promptOverrides:
+		  time:
+		    remove: true
+	`)), true)
+	assert.Nil(t, err)
+	config.mergeParent(&parent)
+	assert.Nil(t, config.applyPromptOverrides(true))
+
+	prompt, err := yaml.Marshal(config.Prompt.YamlNode)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(prompt), "type: time")
+
+	rightPrompt, err := yaml.Marshal(config.RightPrompt.YamlNode)
+	assert.Nil(t, err)
+	assert.Contains(t, string(rightPrompt), "type: time")
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jwalton/kitsch/internal/fileutils"
+)
+
+// ProjectConfigFile is the name of a project configuration file.  A project
+// can ship one of these in its root folder to add colors, project types, or
+// prompts to the user's configuration.
+const ProjectConfigFile = ".kitsch.yaml"
+
+// FindProjectConfig returns the path to the project configuration file which
+// applies to the given directory, or "" if there is none.
+func FindProjectConfig(directory fileutils.Directory) string {
+	return directory.FindFileInAncestors(ProjectConfigFile)
+}
+
+// ProjectConfig is a project configuration file, along with the contents of
+// every configuration file it extends.  Each file is read exactly once, so the
+// contents that are checked against the trust store are the same contents
+// that get loaded.
+type ProjectConfig struct {
+	// File is the absolute path to the project configuration file.
+	File string
+	// Hash is a hash of the project configuration file and every file it
+	// extends.  This is the value stored in the trust store.
+	Hash string
+	// contents is the contents of `File`.
+	contents []byte
+	// files holds the result of reading every file `File` extends.
+	files map[string]projectConfigFile
+}
+
+// projectConfigFile is the result of reading a file extended by a project
+// configuration.
+type projectConfigFile struct {
+	data []byte
+	err  error
+}
+
+// ReadProjectConfig reads the project configuration file `configFile`, and
+// every configuration file it extends.
+func ReadProjectConfig(configFile string) (*ProjectConfig, error) {
+	contents, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+	return NewProjectConfig(configFile, contents), nil
+}
+
+// NewProjectConfig creates a ProjectConfig for the project configuration file
+// `configFile`, where `contents` is the contents of the file.  This reads every
+// configuration file `configFile` extends.
+func NewProjectConfig(configFile string, contents []byte) *ProjectConfig {
+	project := &ProjectConfig{
+		File:     absPath(configFile),
+		contents: contents,
+		files:    map[string]projectConfigFile{},
+	}
+
+	project.Hash = hashProjectConfig(project.File, contents, func(file string) ([]byte, error) {
+		if result, ok := project.files[file]; ok {
+			return result.data, result.err
+		}
+		data, err := readConfigFile(file)
+		project.files[file] = projectConfigFile{data: data, err: err}
+		return data, err
+	})
+
+	return project
+}
+
+// readFile returns the contents of a file extended by the project
+// configuration, as they were when the ProjectConfig was created.
+func (project *ProjectConfig) readFile(file string) ([]byte, error) {
+	if result, ok := project.files[file]; ok {
+		return result.data, result.err
+	}
+	return nil, fmt.Errorf("%s was not read when checking if the project configuration is trusted", file)
+}
+
+// WithProjectConfig returns a new configuration, created by merging the
+// given project configuration over the receiver, in the same way a
+// configuration file is merged over the configuration it extends.  The
+// receiver is not modified.
+func (c *Config) WithProjectConfig(projectConfig *ProjectConfig) (*Config, error) {
+	// The project configuration inherits the user's timeouts, unless it
+	// sets its own.
+	project := Config{Timeout: c.Timeout, ScanTimeout: c.ScanTimeout}
+	err := project.decode(projectConfig.contents, false)
+	if err != nil {
+		return nil, err
+	}
+
+	project.addFile(projectConfig.File)
+
+	err = project.mergeParents(false, []string{projectConfig.File}, projectConfig.readFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Copy the user's colors, so mergeParent doesn't modify them.
+	parent := *c
+	parent.Colors = c.Colors.clone()
+
+	project.mergeParent(&parent)
+
+	// Apply prompt overrides last, so the project can override modules in
+	// the user's prompt.
+	err = project.applyPromptOverrides(false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &project, nil
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/jwalton/kitsch/internal/kitsch/modules"
+	"github.com/jwalton/kitsch/internal/kitsch/styling"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithProjectConfig(t *testing.T) {
+	userConfig := newConfig()
+	userConfig.Timeout = 1000
+	err := userConfig.LoadFromYaml([]byte(heredoc.Doc(`
+		colors:
+		  $fg: blue
+		  $bg: black
+		prompt:
+		  type: text
+		  text: "$ "
+	`)), false)
+	assert.Nil(t, err)
+
+	merged, err := userConfig.WithProjectConfig(NewProjectConfig(ProjectConfigFile, []byte(heredoc.Doc(`
+		colors:
+		  $fg: red
+		projectTypes:
+		  - name: myproject
+		    conditions:
+		      ifFiles: [myproject.json]
+		    toolSymbol: MY
+	`))))
+	assert.Nil(t, err)
+
+	assert.Equal(t, int64(1000), merged.Timeout)
+	assert.Equal(t, map[string]styling.CustomColor{"$fg": {Truecolor: "red"}, "$bg": {Truecolor: "black"}}, merged.Colors.Default)
+	assert.Equal(t, "myproject", merged.ProjectsTypes[0].Name)
+	assert.IsType(t, &modules.TextModule{}, merged.Prompt.Module)
+
+	// User configuration should not be modified.
+	assert.Equal(t, map[string]styling.CustomColor{"$fg": {Truecolor: "blue"}, "$bg": {Truecolor: "black"}}, userConfig.Colors.Default)
+	assert.Empty(t, userConfig.ProjectsTypes)
+}
+
+func TestTrustStore(t *testing.T) {
+	storeFile := filepath.Join(t.TempDir(), "trusted.yaml")
+	configFile := filepath.Join(t.TempDir(), ProjectConfigFile)
+	contents := []byte("colors:\n  $fg: red\n")
+
+	project := NewProjectConfig(configFile, contents)
+
+	store, err := LoadTrustStore(storeFile)
+	assert.Nil(t, err)
+	assert.False(t, store.IsTrusted(project))
+
+	store.Trust(project)
+	assert.Nil(t, store.Save())
+
+	store, err = LoadTrustStore(storeFile)
+	assert.Nil(t, err)
+	assert.True(t, store.IsTrusted(project))
+
+	// Changing the file should make it untrusted.
+	assert.False(t, store.IsTrusted(NewProjectConfig(configFile, []byte("colors:\n  $fg: blue\n"))))
+
+	assert.True(t, store.Untrust(configFile))
+	assert.False(t, store.Untrust(configFile))
+	assert.False(t, store.IsTrusted(project))
+}
+
+func TestTrustStoreExtends(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		".kitsch.yaml": `
+			extends: ./parent.yaml
+		`,
+		"parent.yaml": `
+			extends: [./grandparent.yaml, ./missing.yaml]
+			colors:
+			  $fg: red
+		`,
+		"grandparent.yaml": `
+			colors:
+			  $bg: blue
+		`,
+	})
+	configFile := filepath.Join(dir, ProjectConfigFile)
+	readProject := func() *ProjectConfig {
+		project, err := ReadProjectConfig(configFile)
+		assert.Nil(t, err)
+		return project
+	}
+
+	store, err := LoadTrustStore(filepath.Join(t.TempDir(), "trusted.yaml"))
+	assert.Nil(t, err)
+	store.Trust(readProject())
+	assert.True(t, store.IsTrusted(readProject()))
+
+	// Changing a file the project configuration extends should make it untrusted.
+	grandparent := filepath.Join(dir, "grandparent.yaml")
+	assert.Nil(t, os.WriteFile(grandparent, []byte("colors:\n  $bg: green\n"), 0600))
+	assert.False(t, store.IsTrusted(readProject()))
+	store.Trust(readProject())
+	assert.True(t, store.IsTrusted(readProject()))
+
+	// So should creating a missing file.
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "missing.yaml"), []byte("colors: {}\n"), 0600))
+	assert.False(t, store.IsTrusted(readProject()))
+}
+
+func TestWithProjectConfigUsesTrustedContents(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		".kitsch.yaml": `
+			extends: ./parent.yaml
+		`,
+		"parent.yaml": `
+			colors:
+			  $fg: red
+		`,
+	})
+
+	project, err := ReadProjectConfig(filepath.Join(dir, ProjectConfigFile))
+	assert.Nil(t, err)
+
+	// Changing a file after it has been checked shouldn't change the
+	// configuration that gets loaded.
+	parent := filepath.Join(dir, "parent.yaml")
+	assert.Nil(t, os.WriteFile(parent, []byte("colors:\n  $fg: green\n"), 0600))
+
+	userConfig := newConfig()
+	merged, err := userConfig.WithProjectConfig(project)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]styling.CustomColor{"$fg": {Truecolor: "red"}}, merged.Colors.Default)
+}
+
+func TestLoadTrustStoreInvalid(t *testing.T) {
+	storeFile := filepath.Join(t.TempDir(), "trusted.yaml")
+	assert.Nil(t, os.WriteFile(storeFile, []byte("trusted: [\n"), 0600))
+
+	_, err := LoadTrustStore(storeFile)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TrustStore records which project configuration files the user has trusted.
+// Since a project configuration can run arbitrary commands via `custom`
+// modules, we only use a project configuration if the user has trusted the
+// exact contents of the file, and of every file it extends.
+type TrustStore struct {
+	// path is the file the trust store is saved to.
+	path string
+	// Trusted is a map where keys are the absolute path to a trusted project
+	// configuration file, and values are the hash of the file and the files it
+	// extends when it was trusted (see hashProjectConfig).
+	Trusted map[string]string `yaml:"trusted"`
+}
+
+// LoadTrustStore loads the trust store from the given file.  If the file does
+// not exist, this returns an empty trust store.
+func LoadTrustStore(path string) (*TrustStore, error) {
+	store := &TrustStore{path: path, Trusted: map[string]string{}}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = yaml.Unmarshal(data, store)
+	if err != nil {
+		return nil, err
+	}
+	if store.Trusted == nil {
+		store.Trusted = map[string]string{}
+	}
+
+	return store, nil
+}
+
+// IsTrusted returns true if the given project configuration has been
+// trusted, and neither it nor any configuration file it extends has changed
+// since.
+func (store *TrustStore) IsTrusted(project *ProjectConfig) bool {
+	hash, ok := store.Trusted[trustKey(project.File)]
+	return ok && hash == project.Hash
+}
+
+// Trust marks the given project configuration, and the contents of every
+// configuration file it extends, as trusted.
+func (store *TrustStore) Trust(project *ProjectConfig) {
+	store.Trusted[trustKey(project.File)] = project.Hash
+}
+
+// Untrust removes the given project configuration file from the trust store.
+// Returns false if the file was not trusted.
+func (store *TrustStore) Untrust(configFile string) bool {
+	key := trustKey(configFile)
+	if _, ok := store.Trusted[key]; !ok {
+		return false
+	}
+	delete(store.Trusted, key)
+	return true
+}
+
+// Save writes the trust store back to disk.
+func (store *TrustStore) Save() error {
+	data, err := yaml.Marshal(store)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(store.path, data, 0600)
+}
+
+// trustKey returns the key to use for the given file in the trust store.
+func trustKey(configFile string) string {
+	return absPath(configFile)
+}
+
+// hashProjectConfig returns a hash of a project configuration file and every
+// configuration file it extends, so changing a file the project configuration
+// extends will make it untrusted.  Extended files are read with `readFile`.
+// If the file doesn't extend anything, this is the SHA-256 hash of its
+// contents.
+func hashProjectConfig(configFile string, contents []byte, readFile fileReader) string {
+	hash := sha256.New()
+	hash.Write(contents)
+	hashExtends(hash, contents, []string{absPath(configFile)}, readFile)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// hashExtends writes the name and contents of every configuration file
+// extended by `contents` to `hash`, recursively.  `chain` is the list of
+// configuration files that lead to `contents`, ending with the file `contents`
+// was read from.  Files are read with `readFile`.
+func hashExtends(hash io.Writer, contents []byte, chain []string, readFile fileReader) {
+	var parsed struct {
+		Extends stringList `yaml:"extends"`
+	}
+	if err := yaml.Unmarshal(contents, &parsed); err != nil {
+		// The configuration won't load, so there's nothing else to trust.
+		return
+	}
+
+	for _, name := range parsed.Extends {
+		file := resolveExtends(name, chain)
+
+		cycle := false
+		for _, ancestor := range chain {
+			cycle = cycle || ancestor == file
+		}
+		if cycle {
+			// Loading the configuration will report the cycle.
+			continue
+		}
+
+		fmt.Fprintf(hash, "\x00%s\x00", file)
+		data, err := readFile(file)
+		if err != nil {
+			// Include missing files, so creating one will make the
+			// configuration untrusted.
+			fmt.Fprint(hash, "missing")
+			continue
+		}
+		fmt.Fprintf(hash, "%d\x00", len(data))
+		hash.Write(data)
+
+		parentChain := make([]string, len(chain), len(chain)+1)
+		copy(parentChain, chain)
+		hashExtends(hash, data, append(parentChain, file), readFile)
+	}
+}
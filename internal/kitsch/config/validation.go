@@ -3,6 +3,9 @@ package config
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 
 	// For baseSchema.
@@ -10,10 +13,13 @@ import (
 	"text/template"
 
 	"github.com/jwalton/kitsch/internal/kitsch/condition"
+	"github.com/jwalton/kitsch/internal/kitsch/env"
 	"github.com/jwalton/kitsch/internal/kitsch/getters"
 	"github.com/jwalton/kitsch/internal/kitsch/modules"
 	"github.com/jwalton/kitsch/internal/kitsch/projects"
 	"github.com/jwalton/kitsch/internal/kitsch/schemautils"
+	"github.com/jwalton/kitsch/internal/kitsch/styling"
+	"gopkg.in/yaml.v3"
 )
 
 //go:embed jsonschema.json
@@ -55,18 +61,26 @@ func JSONSchema() string {
 	return string(result)
 }
 
-// ValidateConfiguration validates the configuration file.
+// ValidateConfiguration validates the configuration file.  Any relative paths
+// in `extends` will be resolved relative to the current directory.
 func ValidateConfiguration(yamlData []byte) error {
+	return validateConfiguration(yamlData, nil)
+}
+
+// ValidateConfigurationFile validates the configuration file `configFile`,
+// where `yamlData` is the contents of the file.
+func ValidateConfigurationFile(configFile string, yamlData []byte) error {
+	return validateConfiguration(yamlData, []string{absPath(configFile)})
+}
+
+func validateConfiguration(yamlData []byte, chain []string) error {
 	// First try to load the configuration file.
 	var config = Config{}
-	err := config.LoadFromYaml(yamlData, true)
+	err := config.loadFromYaml(yamlData, true, chain, readConfigFile)
 	if err != nil {
 		return err
 	}
 
-	// TODO: Do custom validation for each module - validate all styles,
-	// execute all templates, check for unknown fields, etc..
-
 	// Validate the configuration file against the JSON schema.  This
 	// will catch any errors in the configuration file, but tends to have
 	// not-so-pretty error messages.
@@ -75,5 +89,259 @@ func ValidateConfiguration(yamlData []byte) error {
 		return err
 	}
 
+	// Validate all styles and templates.
+	var root yaml.Node
+	err = yaml.Unmarshal(yamlData, &root)
+	if err != nil {
+		return err
+	}
+
+	problems := validateConfig(&config, &root)
+	if len(problems) != 0 {
+		return &ValidationError{Problems: problems}
+	}
+
 	return nil
 }
+
+// ValidationError is returned from ValidateConfiguration when problems are
+// found in the styles, templates, or other settings in a configuration file.
+type ValidationError struct {
+	// Problems is the list of problems found.
+	Problems []modules.Diagnostic
+}
+
+func (err *ValidationError) Error() string {
+	result := strings.Builder{}
+	if len(err.Problems) == 1 {
+		result.WriteString("found 1 problem in configuration:")
+	} else {
+		result.WriteString(fmt.Sprintf("found %d problems in configuration:", len(err.Problems)))
+	}
+
+	for _, problem := range err.Problems {
+		result.WriteString("\n  " + problem.String())
+	}
+
+	return result.String()
+}
+
+// validateConfig checks the colors, project types, and modules in the given
+// configuration for problems.  `root` is the root YAML node for the
+// configuration, used to find line numbers for colors and project types.
+func validateConfig(config *Config, root *yaml.Node) []modules.Diagnostic {
+	// Register every custom color, including the light and dark variants, so
+	// styles which use colors from either theme can be validated.
+	styles := &styling.Registry{}
+	colorMaps := []map[string]styling.CustomColor{config.Colors.Default, config.Colors.Light, config.Colors.Dark}
+	for _, colors := range colorMaps {
+		for name, color := range colors {
+			if strings.HasPrefix(name, "$") {
+				styles.AddCustomColorWithFallbacks(name, color)
+			}
+		}
+	}
+
+	problems := validateHexComments(root)
+
+	colorsNode := mappingValue(root, "colors")
+	problems = append(problems, validateColors(styles, "colors.", config.Colors.Default, colorsNode)...)
+	problems = append(problems, validateColors(styles, "colors.light.", config.Colors.Light, mappingValue(colorsNode, "light"))...)
+	problems = append(problems, validateColors(styles, "colors.dark.", config.Colors.Dark, mappingValue(colorsNode, "dark"))...)
+	problems = append(problems, validateProjectTypes(styles, mappingValue(root, "projectTypes"))...)
+
+	context := &modules.Context{
+		Globals:      modules.NewGlobals("", "", "", 80, 0, 0, 0, ""),
+		Environment:  env.New(),
+		ProjectTypes: config.ProjectsTypes,
+		Styles:       styles,
+	}
+
+	prompts := []modules.ModuleWrapper{
+		config.Prompt,
+		config.RightPrompt,
+		config.TransientPrompt,
+		config.ContinuationPrompt,
+	}
+	for _, prompt := range prompts {
+		problems = append(problems, modules.ValidateModule(context, prompt)...)
+	}
+
+	return problems
+}
+
+// hexCommentRegex matches a YAML comment which is actually a hex color.
+var hexCommentRegex = regexp.MustCompile(`^#([0-9a-fA-F]{3,4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})(\s|$)`)
+
+// validateHexComments finds values like `style: bold #afafff`, where YAML
+// reads the unquoted hex color as a comment.
+func validateHexComments(node *yaml.Node) []modules.Diagnostic {
+	problems := []modules.Diagnostic{}
+	if node == nil {
+		return problems
+	}
+
+	if node.Kind == yaml.MappingNode {
+		for index := 0; index+1 < len(node.Content); index += 2 {
+			key, value := node.Content[index], node.Content[index+1]
+			if value.Kind != yaml.ScalarNode {
+				continue
+			}
+			comment := value.LineComment
+			if comment == "" {
+				comment = key.LineComment
+			}
+			if match := hexCommentRegex.FindStringSubmatch(comment); match != nil {
+				problems = append(problems, modules.Diagnostic{
+					Kind:   modules.DiagnosticStyle,
+					Module: describeNode(key.Value, key),
+					Message: fmt.Sprintf(
+						"\"#%s\" is a YAML comment, not a color.  Put quotes around the value to use it as a color",
+						match[1],
+					),
+				})
+			}
+		}
+	}
+
+	for _, child := range node.Content {
+		problems = append(problems, validateHexComments(child)...)
+	}
+	return problems
+}
+
+// validateColors checks that every custom color is a valid color.  `prefix`
+// is prepended to the name of each color in any problems found.
+func validateColors(
+	styles *styling.Registry,
+	prefix string,
+	colors map[string]styling.CustomColor,
+	node *yaml.Node,
+) []modules.Diagnostic {
+	problems := []modules.Diagnostic{}
+
+	names := make([]string, 0, len(colors))
+	for name := range colors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		color := colors[name]
+		keyNode, _ := mappingEntry(node, name)
+
+		message := ""
+		if !strings.HasPrefix(name, "$") {
+			message = "Custom color names must start with \"$\""
+		} else if !styling.IsColor(color.Truecolor) {
+			message = fmt.Sprintf("Invalid color \"%s\"", color.Truecolor)
+		} else if _, err := styles.Get(color.Truecolor); err != nil {
+			message = fmt.Sprintf("Invalid color: %v", err)
+		} else if err := color.ValidateFallbacks(); err != nil {
+			message = fmt.Sprintf("Invalid fallback: %v", err)
+		}
+
+		if message != "" {
+			problems = append(problems, modules.Diagnostic{
+				Kind:    modules.DiagnosticStyle,
+				Module:  describeNode(prefix+name, keyNode),
+				Message: message,
+			})
+		}
+	}
+
+	return problems
+}
+
+// validateProjectTypes checks the styles and getters in each project type.
+func validateProjectTypes(styles *styling.Registry, node *yaml.Node) []modules.Diagnostic {
+	problems := []modules.Diagnostic{}
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return problems
+	}
+
+	for _, item := range node.Content {
+		var projectType projects.ProjectType
+		if err := item.Decode(&projectType); err != nil {
+			// Should have been caught when loading the configuration.
+			continue
+		}
+
+		description := describeNode("projectTypes."+projectType.Name, item)
+
+		if projectType.Style != "" {
+			if _, err := styles.Get(projectType.Style); err != nil {
+				problems = append(problems, modules.Diagnostic{
+					Kind:    modules.DiagnosticStyle,
+					Module:  description,
+					Message: fmt.Sprintf("Invalid style: %v", err),
+				})
+			}
+		}
+
+		if err := projectType.Conditions.Validate(); err != nil {
+			problems = append(problems, modules.Diagnostic{
+				Kind:    modules.DiagnosticConfig,
+				Module:  description,
+				Message: "Invalid conditions: " + err.Error(),
+			})
+		}
+
+		getterLists := [][]getters.Getter{
+			projectType.ToolVersion,
+			projectType.PackageManagerVersion,
+			projectType.PackageVersion,
+		}
+		for _, getterList := range getterLists {
+			for _, getter := range getterList {
+				customGetter, ok := getter.(getters.CustomGetter)
+				if !ok {
+					continue
+				}
+				if err := customGetter.Validate(); err != nil {
+					problems = append(problems, modules.Diagnostic{
+						Kind:    modules.DiagnosticGetter,
+						Module:  description,
+						Message: err.Error(),
+					})
+				}
+			}
+		}
+	}
+
+	return problems
+}
+
+// describeNode returns a description of a value in the configuration file,
+// including its line and column.
+func describeNode(name string, node *yaml.Node) string {
+	if node == nil {
+		return name
+	}
+	return fmt.Sprintf("%s(%d:%d)", name, node.Line, node.Column)
+}
+
+// mappingEntry returns the key and value nodes for the given key in a YAML
+// mapping node, or nils if the key is not present.
+func mappingEntry(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node != nil && node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	for index := 0; index+1 < len(node.Content); index += 2 {
+		if node.Content[index].Value == key {
+			return node.Content[index], node.Content[index+1]
+		}
+	}
+	return nil, nil
+}
+
+// mappingValue returns the value node for the given key in a YAML mapping
+// node, or nil if the key is not present.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	_, value := mappingEntry(node, key)
+	return value
+}
@@ -1,8 +1,13 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/MakeNowJust/heredoc"
+	"github.com/jwalton/kitsch/internal/kitsch/styling"
 	"github.com/jwalton/kitsch/sampleconfig"
 	"github.com/stretchr/testify/assert"
 )
@@ -47,4 +52,140 @@ prompt:
 func TestValidateBuiltInConfigs(t *testing.T) {
 	err := ValidateConfiguration(sampleconfig.DefaultConfig)
 	assert.Nil(t, err)
+
+	for name, preset := range sampleconfig.Presets {
+		err := ValidateConfiguration(preset)
+		assert.Nil(t, err, name)
+	}
+}
+
+func TestValidateUnquotedHexColor(t *testing.T) {
+	c := heredoc.Doc(`
+		prompt:
+		  type: text
+		  text: "Hello # world"
+		  style: bold #afafff # a real comment
+	`)
+	err := ValidateConfiguration([]byte(c))
+	assert.EqualError(t, err, heredoc.Doc(`
+		found 1 problem in configuration:
+		  [style] style(4:3): "#afafff" is a YAML comment, not a color.  Put quotes around the value to use it as a color`,
+	))
+}
+
+func TestValidateStylesAndTemplates(t *testing.T) {
+	c := heredoc.Doc(`
+		colors:
+		  $good: "#ff0000"
+		  $bad: notacolor
+		projectTypes:
+		  - name: go
+		    style: $good
+		    toolVersion:
+		      type: custom
+		      from: go version
+		      regex: "go([0-9.]+"
+		prompt:
+		  type: block
+		  modules:
+		    - type: text
+		      text: hi
+		      style: brigthBlue
+		    - type: directory
+		      template: "{{ .Data.Pth }}"
+	`)
+
+	err := ValidateConfiguration([]byte(c))
+	assert.EqualError(t, err, strings.Join([]string{
+		"found 4 problems in configuration:",
+		`  [style] colors.$bad(3:3): Invalid color "notacolor"`,
+		`  [getter] projectTypes.go(5:5): invalid regex: "go([0-9.]+": error parsing regexp: missing closing ): ` + "`go([0-9.]+`",
+		`  [style] text(14:7): Invalid style: error compiling style "brigthBlue": unknown style "brigthBlue"`,
+		`  [template] directory(17:7): Error in template: template:1:8: <.Data.Pth>: modules.directoryModuleResult has no field "Pth" (did you mean "Path"?)`,
+	}, "\n"))
+}
+
+func TestValidateColorFallbacks(t *testing.T) {
+	c := heredoc.Doc(`
+		colors:
+		  $git:
+		    truecolor: "#20a0ff"
+		    ansi256: 39
+		    ansi: brightBlue
+		  $bad:
+		    truecolor: "#20a0ff"
+		    ansi256: 300
+		prompt:
+		  type: text
+		  text: hi
+		  style: $git
+	`)
+
+	var config Config
+	err := config.LoadFromYaml([]byte(c), true)
+	if assert.NoError(t, err) {
+		assert.Equal(t, styling.CustomColor{Truecolor: "#20a0ff", Ansi256: "39", Ansi: "brightBlue"}, config.Colors.Default["$git"])
+	}
+
+	err = ValidateConfiguration([]byte(c))
+	assert.EqualError(t, err, strings.Join([]string{
+		"found 1 problem in configuration:",
+		`  [style] colors.$bad(6:3): Invalid fallback: invalid ansi256 color "300"`,
+	}, "\n"))
+}
+
+func TestValidateThemeColors(t *testing.T) {
+	c := heredoc.Doc(`
+		colors:
+		  light:
+		    $fg: "#000"
+		  dark:
+		    $fg: "#fff"
+		    $bad: banana
+		prompt:
+		  type: text
+		  text: hi
+		  style: $fg
+	`)
+
+	err := ValidateConfiguration([]byte(c))
+	assert.EqualError(t, err, strings.Join([]string{
+		"found 1 problem in configuration:",
+		`  [style] colors.dark.$bad(6:5): Invalid color "banana"`,
+	}, "\n"))
+}
+
+func TestValidateInheritedModules(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"kitsch.yaml": `
+			extends: ./parent.yaml
+			promptOverrides:
+			  time:
+			    insertAfter:
+			      - type: text
+			        text: hi
+			        style: brigthRed
+		`,
+		"parent.yaml": `
+			prompt:
+			  type: block
+			  modules:
+			    - type: time
+			    - type: text
+			      text: hi
+			      style: brigthBlue
+		`,
+	})
+	configFile := filepath.Join(dir, "kitsch.yaml")
+	contents, err := os.ReadFile(configFile)
+	assert.Nil(t, err)
+
+	// Problems in modules inherited from another file should say which file
+	// they came from.
+	err = ValidateConfigurationFile(configFile, contents)
+	assert.EqualError(t, err, strings.Join([]string{
+		"found 2 problems in configuration:",
+		`  [style] text(5:9): Invalid style: error compiling style "brigthRed": unknown style "brigthRed"`,
+		`  [style] text(5:7) in ` + filepath.Join(dir, "parent.yaml") + `: Invalid style: error compiling style "brigthBlue": unknown style "brigthBlue"`,
+	}, "\n"))
 }
@@ -0,0 +1,184 @@
+// Package daemon implements a long running kitsch process which renders prompts
+// on behalf of `kitsch prompt`.  Loading the configuration and compiling styles
+// only needs to happen once in the daemon, instead of once for every prompt.
+//
+// Clients connect to the daemon over a unix socket in the configuration folder,
+// write a single JSON encoded PromptRequest, and read back a single JSON encoded
+// PromptResponse.
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// socketName is the name of the socket file in the configuration folder.
+const socketName = "daemon.sock"
+
+// dialTimeout is how long a client will wait to connect to the daemon before
+// giving up and rendering the prompt itself.
+const dialTimeout = 50 * time.Millisecond
+
+// requestTimeout is the maximum amount of time a client will wait for the
+// daemon to render a prompt.
+const requestTimeout = 5 * time.Second
+
+// PromptRequest is a request sent to the daemon to render a prompt.
+type PromptRequest struct {
+	// ConfigFile is the configuration file the client would have loaded.  The
+	// daemon will refuse requests for a different configuration file.
+	ConfigFile string `json:"configFile"`
+	// ColorLevel is the color level the client would have rendered with.
+	ColorLevel int `json:"colorLevel"`
+	// Shell is the type of shell.
+	Shell string `json:"shell"`
+	// CWD is the current working directory.
+	CWD string `json:"cwd"`
+	// LogicalCWD is the display name for the current working directory.
+	LogicalCWD string `json:"logicalCwd"`
+	// TerminalWidth is the width of the terminal.
+	TerminalWidth int `json:"terminalWidth"`
+	// Status is the status code of the previously run command.
+	Status int `json:"status"`
+	// Jobs is the number of currently running jobs.
+	Jobs int `json:"jobs"`
+	// PreviousCommandDuration is the duration of the previous command, in milliseconds.
+	PreviousCommandDuration int64 `json:"cmdDuration"`
+	// Keymap is the keymap of fish/zsh.
+	Keymap string `json:"keymap"`
+	// Right is true to render the right prompt.
+	Right bool `json:"right"`
+	// Transient is true to render the transient prompt.
+	Transient bool `json:"transient"`
+	// Continuation is true to render the continuation prompt.
+	Continuation bool `json:"continuation"`
+	// Async is true to render async modules from the cache.
+	Async bool `json:"async"`
+	// AsyncRefresh is true to refresh async modules instead of rendering the
+	// prompt.
+	AsyncRefresh bool `json:"asyncRefresh"`
+	// Background is the terminal's background color, if the shell was able
+	// to find it.
+	Background string `json:"background,omitempty"`
+	// Env is the environment of the client.
+	Env map[string]string `json:"env"`
+}
+
+// PromptResponse is the daemon's response to a PromptRequest.
+type PromptResponse struct {
+	// Output is the rendered prompt.
+	Output string `json:"output"`
+	// Error is a description of why the prompt could not be rendered.  If this
+	// is set, the client should render the prompt itself.
+	Error string `json:"error,omitempty"`
+}
+
+// Handler renders a prompt for a request.
+type Handler func(request PromptRequest) (string, error)
+
+// SocketPath returns the path to the daemon's socket in the given configuration
+// folder.
+func SocketPath(configDir string) string {
+	return filepath.Join(configDir, socketName)
+}
+
+// Render asks the daemon listening on the given socket to render a prompt.
+// Returns an error if the daemon is not running, or if it could not render
+// the prompt.
+func Render(socketPath string, request PromptRequest) (string, error) {
+	conn, err := net.DialTimeout("unix", socketPath, dialTimeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	err = conn.SetDeadline(time.Now().Add(requestTimeout))
+	if err != nil {
+		return "", err
+	}
+
+	err = json.NewEncoder(conn).Encode(request)
+	if err != nil {
+		return "", err
+	}
+
+	var response PromptResponse
+	err = json.NewDecoder(conn).Decode(&response)
+	if err != nil {
+		return "", err
+	}
+	if response.Error != "" {
+		return "", errors.New(response.Error)
+	}
+
+	return response.Output, nil
+}
+
+// Listen creates a listener on the given socket.  If a stale socket is left
+// over from a previous daemon, it will be removed.  Returns an error if another
+// daemon is already listening on the socket.
+func Listen(socketPath string) (net.Listener, error) {
+	if _, err := os.Stat(socketPath); err == nil {
+		conn, err := net.DialTimeout("unix", socketPath, dialTimeout)
+		if err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("daemon is already running on %s", socketPath)
+		}
+
+		err = os.Remove(socketPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not remove stale socket %s: %w", socketPath, err)
+		}
+	}
+
+	return net.Listen("unix", socketPath)
+}
+
+// Serve accepts connections on the given listener, and renders prompts using
+// the given handler.  Each connection is handled in its own goroutine, so the
+// handler must be safe to call concurrently.  Serve returns when the listener
+// is closed.
+func Serve(listener net.Listener, handler Handler) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		go serveConn(conn, handler)
+	}
+}
+
+func serveConn(conn net.Conn, handler Handler) {
+	defer conn.Close()
+
+	err := conn.SetDeadline(time.Now().Add(requestTimeout))
+	if err != nil {
+		return
+	}
+
+	var request PromptRequest
+	err = json.NewDecoder(conn).Decode(&request)
+	if err != nil {
+		return
+	}
+
+	var response PromptResponse
+	output, err := handler(request)
+	if err != nil {
+		response.Error = err.Error()
+	} else {
+		response.Output = output
+	}
+
+	// If the client has gone away there's nobody to report this error to.
+	_ = json.NewEncoder(conn).Encode(response)
+}
@@ -0,0 +1,55 @@
+package daemon
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	tempdir, err := os.MkdirTemp("", "daemon")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	socketPath := SocketPath(tempdir)
+
+	listener, err := Listen(socketPath)
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		_ = Serve(listener, func(request PromptRequest) (string, error) {
+			if request.Shell == "unknown" {
+				return "", errors.New("unsupported shell")
+			}
+			return request.CWD + " " + request.Env["USER"] + " $ ", nil
+		})
+	}()
+
+	output, err := Render(socketPath, PromptRequest{
+		Shell: "zsh",
+		CWD:   "/tmp",
+		Env:   map[string]string{"USER": "jwalton"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp jwalton $ ", output)
+
+	_, err = Render(socketPath, PromptRequest{Shell: "unknown"})
+	assert.EqualError(t, err, "unsupported shell")
+
+	// Should not be able to start a second daemon on the same socket.
+	_, err = Listen(socketPath)
+	assert.Error(t, err)
+}
+
+func TestRenderNoDaemon(t *testing.T) {
+	tempdir, err := os.MkdirTemp("", "daemon")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempdir)
+
+	_, err = Render(filepath.Join(tempdir, "missing.sock"), PromptRequest{})
+	assert.Error(t, err)
+}
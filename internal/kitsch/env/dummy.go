@@ -1,5 +1,7 @@
 package env
 
+import "strings"
+
 // DummyEnv is a dummy environment for use in unit testing.
 type DummyEnv struct {
 	// Env contains the environment variables for this dummy environment.
@@ -7,12 +9,27 @@ type DummyEnv struct {
 }
 
 // Getenv returns the value of the specified environment variable.
-// Returns the value set in `env.Env`.
+// Returns the value set in `env.Env`.  On Windows, where environment variable
+// names are case-insensitive, `key` is matched without regard to case.
 func (env DummyEnv) Getenv(key string) string {
-	val, ok := env.Env[key]
+	return lookupEnv(env.Env, key, caseInsensitiveNames)
+}
+
+// lookupEnv returns the value of `key` in `values`.  If `ignoreCase` is true
+// and there is no exact match, this will return the value of a key which
+// differs from `key` only in case (e.g. "Path" for "PATH").
+func lookupEnv(values map[string]string, key string, ignoreCase bool) string {
+	val, ok := values[key]
 	if ok {
 		return val
 	}
+	if ignoreCase {
+		for name, val := range values {
+			if strings.EqualFold(name, key) {
+				return val
+			}
+		}
+	}
 	return ""
 }
 
@@ -25,3 +42,16 @@ func (env DummyEnv) HasSomeEnv(keys ...string) bool {
 	}
 	return false
 }
+
+// Environ returns the values in `env.Env` as a list of "key=value" strings.
+// If `env.Env` is nil, returns nil.
+func (env DummyEnv) Environ() []string {
+	if env.Env == nil {
+		return nil
+	}
+	result := make([]string, 0, len(env.Env))
+	for key, value := range env.Env {
+		result = append(result, key+"="+value)
+	}
+	return result
+}
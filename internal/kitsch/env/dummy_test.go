@@ -0,0 +1,30 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupEnv(t *testing.T) {
+	values := map[string]string{
+		"Path":    "C:\\Windows",
+		"PATHEXT": ".EXE",
+	}
+
+	assert.Equal(t, "C:\\Windows", lookupEnv(values, "Path", false))
+	assert.Equal(t, "", lookupEnv(values, "PATH", false))
+	assert.Equal(t, "C:\\Windows", lookupEnv(values, "PATH", true))
+	assert.Equal(t, ".EXE", lookupEnv(values, "PathExt", true))
+	assert.Equal(t, "", lookupEnv(values, "HOME", true))
+}
+
+func TestNewFromMapPath(t *testing.T) {
+	environment := NewFromMap(map[string]string{"Path": "/usr/bin"})
+	if caseInsensitiveNames {
+		assert.Equal(t, "/usr/bin", environment.Getenv("PATH"))
+	} else {
+		assert.Equal(t, "", environment.Getenv("PATH"))
+	}
+	assert.Equal(t, "/usr/bin", environment.Getenv("Path"))
+}
@@ -17,6 +17,10 @@ type Env interface {
 	//
 	// would return true if this is an SSH session.
 	HasSomeEnv(...string) bool
+	// Environ returns the environment as a list of "key=value" strings, for
+	// use as the environment of a child process.  Returns nil if child
+	// processes should inherit the environment of the current process.
+	Environ() []string
 }
 
 type defaultEnv struct{}
@@ -38,3 +42,13 @@ func (defaultEnv) HasSomeEnv(keys ...string) bool {
 	}
 	return false
 }
+
+func (defaultEnv) Environ() []string {
+	return nil
+}
+
+// NewFromMap creates a new instance of Env which reads environment variables
+// from the given map instead of from the current process.
+func NewFromMap(values map[string]string) Env {
+	return DummyEnv{Env: values}
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+// +build !windows
+
+package env
+
+// caseInsensitiveNames is true if environment variable names are
+// case-insensitive on this platform.
+const caseInsensitiveNames = false
@@ -218,6 +218,26 @@ func (getter CustomGetter) GetValue(context GetterContext) (interface{}, error)
 	return result, nil
 }
 
+// Validate checks the getter's configuration for problems, such as an invalid
+// regex or value template.
+func (getter CustomGetter) Validate() error {
+	if getter.Regex != "" {
+		_, err := regexp.Compile(getter.Regex)
+		if err != nil {
+			return fmt.Errorf("invalid regex: \"%s\": %w", getter.Regex, err)
+		}
+	}
+
+	if getter.ValueTemplate != "" {
+		_, err := template.New(getter.Type.String()).Funcs(sprigTemplateFunctions).Parse(getter.ValueTemplate)
+		if err != nil {
+			return fmt.Errorf("invalid template: \"%s\": %w", getter.ValueTemplate, err)
+		}
+	}
+
+	return nil
+}
+
 func (getter CustomGetter) getCacheKeyForFile(file string) (string, error) {
 	var err error
 	origFile := file
@@ -302,7 +322,7 @@ func (getter CustomGetter) getCustomValue(
 
 	// Resolve the executable to an absolute path.
 	executable := commandParts[0]
-	executable, err = fileutils.LookPathSafe(executable)
+	executable, err = fileutils.LookPathSafeEnv(executable, context.Getenv)
 	if err != nil {
 		return nil, fmt.Errorf("could not find executable: \"%s\": %w", commandParts[0], err)
 	}
@@ -331,8 +351,9 @@ func (getter CustomGetter) getCustomValue(
 	}
 
 	// If that fails, run the command.
-	cmd := exec.Command(executable, commandParts[1:]...)
+	cmd := exec.CommandContext(context.Context(), executable, commandParts[1:]...)
 	cmd.Dir = context.GetWorkingDirectory().Path()
+	cmd.Env = context.Environ()
 	result, err := cmd.CombinedOutput()
 	if err != nil {
 		return nil, fmt.Errorf("error running command: \"%s\": %w", executable, err)
@@ -1,8 +1,12 @@
 package getters
 
 import (
+	"context"
+	"os"
+	"runtime"
 	"testing"
 	"testing/fstest"
+	"time"
 
 	"github.com/jwalton/kitsch/internal/cache"
 	"github.com/jwalton/kitsch/internal/fileutils"
@@ -10,12 +14,18 @@ import (
 )
 
 type testGetterContext struct {
+	ctx       context.Context
 	directory fileutils.Directory
 	home      string
 	cache     cache.Cache
 	env       map[string]string
 }
 
+// Context returns the context for the current operation.
+func (context *testGetterContext) Context() context.Context {
+	return context.ctx
+}
+
 // GetWorkingDirectory returns the current working directory.
 func (context *testGetterContext) GetWorkingDirectory() fileutils.Directory {
 	return context.directory
@@ -31,6 +41,15 @@ func (context *testGetterContext) Getenv(key string) string {
 	return context.env[key]
 }
 
+// Environ returns the environment to run external commands in.
+func (context *testGetterContext) Environ() []string {
+	result := []string{}
+	for key, value := range context.env {
+		result = append(result, key+"="+value)
+	}
+	return result
+}
+
 // GetValueCache returns the value cache.
 func (context *testGetterContext) GetValueCache() cache.Cache {
 	return context.cache
@@ -38,6 +57,7 @@ func (context *testGetterContext) GetValueCache() cache.Cache {
 
 func makeTestGetterContext(fsys fstest.MapFS) *testGetterContext {
 	return &testGetterContext{
+		ctx:       context.Background(),
 		directory: fileutils.NewDirectoryTestFS("/foo/bar", fsys),
 		home:      "/users/jwalton",
 		cache:     cache.NewMemoryCache(),
@@ -249,3 +269,63 @@ func TestYamlGetterTextTemplate(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "v1.17.1", val)
 }
+
+func TestCustomGetterKilledOnTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sleep is not available on Windows")
+	}
+
+	getterContext := makeTestGetterContext(fstest.MapFS{})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	getterContext.ctx = ctx
+	getterContext.directory = fileutils.NewDirectory(t.TempDir(), 0)
+	getterContext.env = map[string]string{"PATH": os.Getenv("PATH")}
+
+	getter := CustomGetter{
+		Type: TypeCustom,
+		From: "sleep 10",
+	}
+
+	start := time.Now()
+	_, err := getter.GetValue(getterContext)
+
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestCustomGetterUsesContextEnvironment(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sh is not available on Windows")
+	}
+
+	getterContext := makeTestGetterContext(fstest.MapFS{})
+	getterContext.directory = fileutils.NewDirectory(t.TempDir(), 0)
+	getter := CustomGetter{
+		Type: TypeCustom,
+		From: `sh -c 'printf %s "$KITSCH_TEST"'`,
+	}
+
+	// The executable should be resolved using the context's PATH.
+	_, err := getter.GetValue(getterContext)
+	assert.EqualError(t, err, `could not find executable: "sh": exec: "sh": executable file not found in $PATH`)
+
+	// The command should be run with the context's environment.
+	getterContext.env = map[string]string{
+		"PATH":        os.Getenv("PATH"),
+		"KITSCH_TEST": "hello",
+	}
+	val, err := getter.GetValue(getterContext)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", val)
+}
+
+func TestCustomGetterValidate(t *testing.T) {
+	assert.Nil(t, CustomGetter{Type: TypeCustom, Regex: `v(\d+)`, ValueTemplate: "{{ .Text }}"}.Validate())
+
+	err := CustomGetter{Type: TypeCustom, Regex: `v(\d+`}.Validate()
+	assert.EqualError(t, err, "invalid regex: \"v(\\d+\": error parsing regexp: missing closing ): `v(\\d+`")
+
+	err = CustomGetter{Type: TypeCustom, ValueTemplate: "{{ .Text }"}.Validate()
+	assert.EqualError(t, err, `invalid template: "{{ .Text }": template: TypeCustom:1: unexpected "}" in operand`)
+}
@@ -3,6 +3,8 @@
 package getters
 
 import (
+	"context"
+
 	"github.com/jwalton/kitsch/internal/cache"
 	"github.com/jwalton/kitsch/internal/fileutils"
 )
@@ -10,6 +12,10 @@ import (
 // GetterContext is an interface used by a Getter to retrieve information from
 // the environment or file system.
 type GetterContext interface {
+	// Context returns the context for the current operation.  Getters which
+	// run external commands should stop them when this context is done.
+	Context() context.Context
+
 	// GetWorkingDirectory returns the current working directory.
 	GetWorkingDirectory() fileutils.Directory
 
@@ -19,6 +25,10 @@ type GetterContext interface {
 	// Getenv returns the value of the specified environment variable.
 	Getenv(key string) string
 
+	// Environ returns the environment to run external commands in, as a list
+	// of "key=value" strings, or nil to use the current process's environment.
+	Environ() []string
+
 	// GetValueCache returns the value cache.
 	GetValueCache() cache.Cache
 }
@@ -21,17 +21,31 @@ func getKitschCommand() string {
 	return kitschCommand
 }
 
+// Options are options which control which features are enabled by the
+// initialization script.
+type Options struct {
+	// TransientPrompt is true if the prompt should be replaced with the
+	// transient prompt after a command is entered.
+	TransientPrompt bool
+	// ContinuationPrompt is true if kitsch should render the continuation
+	// prompt (PS2).
+	ContinuationPrompt bool
+	// Async is true if the prompt contains async modules, which need to be
+	// refreshed in the background.
+	Async bool
+}
+
 // ShortInitScript returns the kitsch initialization script for the given shell type.
 func ShortInitScript(shell string, configFile string) (string, error) {
-	return getInitScript("init-short", shell, configFile)
+	return getInitScript("init-short", shell, configFile, Options{})
 }
 
 // InitScript returns the full kitsch initialization script for the given shell type.
-func InitScript(shell string, configFile string) (string, error) {
-	return getInitScript("init", shell, configFile)
+func InitScript(shell string, configFile string, options Options) (string, error) {
+	return getInitScript("init", shell, configFile, options)
 }
 
-func getInitScript(filename string, shell string, configFile string) (string, error) {
+func getInitScript(filename string, shell string, configFile string, options Options) (string, error) {
 	kitschCommand := getKitschCommand()
 
 	shellExt := shell
@@ -40,9 +54,12 @@ func getInitScript(filename string, shell string, configFile string) (string, er
 		shellExt = "ps1"
 	}
 
-	data := map[string]string{
-		"kitschCommand": kitschCommand,
-		"configFile":    configFile,
+	data := map[string]interface{}{
+		"kitschCommand":      kitschCommand,
+		"configFile":         configFile,
+		"transientPrompt":    options.TransientPrompt,
+		"continuationPrompt": options.ContinuationPrompt,
+		"async":              options.Async,
 	}
 
 	initTemplate, err := initTemplates.ReadFile("templates/" + shell + "-" + filename + "." + shellExt)
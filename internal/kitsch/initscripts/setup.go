@@ -15,6 +15,7 @@ var code = gchalk.WithBold().BrightWhite
 var shellConfigFiles = map[string]string{
 	"bash":       "~/.bashrc",
 	"zsh":        "~/.zshrc",
+	"fish":       "~/.config/fish/config.fish",
 	"powershell": "Microsoft.PowerShell_profile.ps1 (you can find the location of this file by running `echo $PROFILE`)",
 }
 
@@ -38,6 +39,7 @@ func ShowSetupInstructions(
 	shellSetupCommand := map[string]string{
 		"bash":       `eval "$(` + programName + ` init bash)"`,
 		"zsh":        `eval "$(` + programName + ` init zsh)"`,
+		"fish":       programName + ` init fish | source`,
 		"powershell": `Invoke-Expression (&` + programName + ` init powershell)`,
 	}
 
@@ -50,6 +52,10 @@ func ShowSetupInstructions(
 			if command -v ` + programName + ` > /dev/null; then
 			    eval "$(` + programName + ` init zsh)"
 			fi`),
+		"fish": heredoc.Doc(`
+			if type -q ` + programName + `
+			    ` + programName + ` init fish | source
+			end`),
 		"powershell": `Invoke-Expression (&` + programName + ` init powershell)`,
 	}
 
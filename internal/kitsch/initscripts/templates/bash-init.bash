@@ -48,15 +48,22 @@ kitsch_precmd() {
 
     eval "$_PRESERVED_PROMPT_COMMAND"
 
-    # Prepare the timer data, if needed.
+    # Prepare the timer data, if needed.  Note that bash has no right prompt,
+    # so if one is configured, kitsch will draw it as part of PS1.
     if [[ $KITSCH_START_TIME ]]; then
         KITSCH_END_TIME=$({{ .kitschCommand }} time)
         KITSCH_DURATION=$((KITSCH_END_TIME - KITSCH_START_TIME))
-        PS1="$({{ .kitschCommand }} prompt {{with .configFile}}--config {{.}} {{end}}--shell bash --terminal-width="$COLUMNS" --status=$KITSCH_CMD_STATUS --jobs="$NUM_JOBS" --cmd-duration=$KITSCH_DURATION)"
+        PS1="$({{ .kitschCommand }} prompt {{with .configFile}}--config {{.}} {{end}}--shell bash --background="$KITSCH_BACKGROUND" --terminal-width="$COLUMNS" --status=$KITSCH_CMD_STATUS --jobs="$NUM_JOBS" --cmd-duration=$KITSCH_DURATION)"
         unset KITSCH_START_TIME
     else
-        PS1="$({{ .kitschCommand }} prompt {{with .configFile}}--config {{.}} {{end}}--shell bash --terminal-width="$COLUMNS" --status=$KITSCH_CMD_STATUS --jobs="$NUM_JOBS")"
+        PS1="$({{ .kitschCommand }} prompt {{with .configFile}}--config {{.}} {{end}}--shell bash --background="$KITSCH_BACKGROUND" --terminal-width="$COLUMNS" --status=$KITSCH_CMD_STATUS --jobs="$NUM_JOBS")"
     fi
+{{- if .continuationPrompt }}
+
+    # PS2 has to be generated here, because bash won't interpret the "\[" and
+    # "\]" escapes if they come from a command substitution inside PS2.
+    PS2="$({{ .kitschCommand }} prompt --continuation {{with .configFile}}--config {{.}} {{end}}--shell bash --background="$KITSCH_BACKGROUND" --terminal-width="$COLUMNS" --status=$KITSCH_CMD_STATUS --jobs="$NUM_JOBS")"
+{{- end }}
     KITSCH_PREEXEC_READY=true  # Signal that we can safely restart the timer
 }
 
@@ -98,6 +105,30 @@ fi
 # Set up the start time and KITSCH_SHELL, which controls shell-specific sequences
 KITSCH_START_TIME=$({{ .kitschCommand }} time)
 
+# Ask the terminal for its background color with an OSC 11 query, so kitsch can
+# pick light or dark colors.  Terminals that don't support the query won't
+# reply, so we only wait briefly.  KITSCH_BACKGROUND is kept local to this
+# shell, so child shells ask their own terminal.  Call this again if you change
+# your terminal's color scheme.
+kitsch_refresh_background() {
+    KITSCH_BACKGROUND=""
+    [[ -t 0 && -t 1 ]] || return
+    local kitsch_stty kitsch_background
+    kitsch_stty=$(stty -g 2>/dev/null) || return
+    stty raw -echo min 0 time 1 2>/dev/null
+    printf '\e]11;?\a' > /dev/tty
+    IFS= read -r -d $'\a' kitsch_background < /dev/tty
+    stty "$kitsch_stty"
+    if [[ $kitsch_background =~ rgb:[0-9a-fA-F/]+ ]]; then
+        KITSCH_BACKGROUND="${BASH_REMATCH[0]}"
+    fi
+}
+
+# Skip the query if the user has picked a theme or a background.
+if [[ -z "$KITSCH_THEME" && -z "$KITSCH_BACKGROUND" ]]; then
+    kitsch_refresh_background
+fi
+
 # Set up the session key that will be used to store logs
 KITSCH_SESSION_KEY="$RANDOM$RANDOM$RANDOM$RANDOM$RANDOM"; # Random generates a number b/w 0 - 32767
 KITSCH_SESSION_KEY="${KITSCH_SESSION_KEY}0000000000000000" # Pad it to 16+ chars.
@@ -0,0 +1 @@
+source ("{{ .kitschCommand }}" init {{with .configFile}}--config "{{.}}" {{end}}--print-full-init fish | psub)
\ No newline at end of file
@@ -0,0 +1,63 @@
+# Adapted from https://github.com/starship/starship/blob/master/src/init/starship.fish
+# Copyright (c) 2019-2021, Starship Contributors
+
+# Runs `kitsch prompt`.  The first argument is the status of the previous
+# command, and any additional arguments are passed through to kitsch.
+function __kitsch_prompt --argument-names kitsch_cmd_status
+    # kitsch expects the zsh-style "vicmd" keymap when in vi normal mode.
+    set -l kitsch_keymap ""
+    switch "$fish_key_bindings"
+        case fish_hybrid_key_bindings fish_vi_key_bindings
+            switch "$fish_bind_mode"
+                case default
+                    set kitsch_keymap vicmd
+                case '*'
+                    set kitsch_keymap "$fish_bind_mode"
+            end
+    end
+
+    # Account for changes in variable name between v2.7 and v3.0
+    set -l kitsch_duration "$CMD_DURATION$cmd_duration"
+    set -l kitsch_jobs (count (jobs -p))
+
+    "{{ .kitschCommand }}" prompt {{with .configFile}}--config "{{.}}" {{end}}--shell fish --background="$KITSCH_BACKGROUND" --terminal-width="$COLUMNS" --status=$kitsch_cmd_status --keymap="$kitsch_keymap" --cmd-duration="$kitsch_duration" --jobs=$kitsch_jobs $argv[2..-1]
+end
+
+function fish_prompt
+    __kitsch_prompt $status
+end
+
+function fish_right_prompt
+    __kitsch_prompt $status --right
+end
+
+# Disable virtualenv prompt, it breaks kitsch
+set -g VIRTUAL_ENV_DISABLE_PROMPT 1
+
+# Remove default mode prompt, since kitsch shows the keymap itself.
+builtin functions -e fish_mode_prompt
+
+# Ask the terminal for its background color with an OSC 11 query, so kitsch can
+# pick light or dark colors.  Terminals that don't support the query won't
+# reply, so we only wait briefly.  KITSCH_BACKGROUND is kept local to this
+# shell, so child shells ask their own terminal.  Call this again if you change
+# your terminal's color scheme.
+function kitsch_refresh_background
+    set -g KITSCH_BACKGROUND ""
+    isatty stdin; and isatty stdout; or return
+    set -l kitsch_stty (stty -g 2>/dev/null); or return
+    stty raw -echo min 0 time 1 2>/dev/null
+    printf '\e]11;?\a' > /dev/tty
+    set -l kitsch_background (command dd bs=1 count=64 < /dev/tty 2>/dev/null | string match -r 'rgb:[0-9a-fA-F/]+')
+    stty $kitsch_stty
+    set -q kitsch_background[1]
+    and set -g KITSCH_BACKGROUND $kitsch_background[1]
+end
+
+# Skip the query if the user has picked a theme or a background.
+if test -z "$KITSCH_THEME"; and test -z "$KITSCH_BACKGROUND"
+    kitsch_refresh_background
+end
+
+# Set up the session key that will be used to store logs
+set -gx KITSCH_SESSION_KEY (random 10000000000000 9999999999999999)
@@ -22,7 +22,7 @@ function global:prompt {
         }
     }
 
-    function Invoke-Native {
+    function New-StartInfo {
         param($Executable, $Arguments)
         $startInfo = New-Object System.Diagnostics.ProcessStartInfo -ArgumentList $Executable -Property @{
             StandardOutputEncoding = [System.Text.Encoding]::UTF8;
@@ -50,6 +50,12 @@ function global:prompt {
             }
             $startInfo.Arguments = $escaped -Join ' ';
         }
+        return $startInfo
+    }
+
+    function Invoke-Native {
+        param($Executable, $Arguments)
+        $startInfo = New-StartInfo -Executable $Executable -Arguments $Arguments
         $process = [System.Diagnostics.Process]::Start($startInfo)
 
         # stderr isn't displayed with this style of invocation
@@ -62,6 +68,27 @@ function global:prompt {
 
         $process.StandardOutput.ReadToEnd();
     }
+{{- if .async }}
+
+    # Refresh async modules in a background process.  When the process exits,
+    # redraw the prompt if anything changed.
+    function Start-AsyncRefresh {
+        param($Executable, $Arguments)
+        Stop-KitschAsyncRefresh
+
+        $process = New-Object System.Diagnostics.Process
+        $process.StartInfo = New-StartInfo -Executable $Executable -Arguments $Arguments
+        $process.EnableRaisingEvents = $true
+        Register-ObjectEvent -InputObject $process -EventName Exited -SourceIdentifier KitschAsyncRefresh -Action {
+            Unregister-Event -SourceIdentifier KitschAsyncRefresh
+            $output = $Event.Sender.StandardOutput.ReadToEnd()
+            if ($output.Trim() -ne '') {
+                [Microsoft.PowerShell.PSConsoleReadLine]::InvokePrompt()
+            }
+        } | Out-Null
+        $process.Start() | Out-Null
+    }
+{{- end }}
 
     $origDollarQuestion = $global:?
     $origLastExitCode = $global:LASTEXITCODE
@@ -85,6 +112,9 @@ function global:prompt {
         "--terminal-width=$($Host.UI.RawUI.WindowSize.Width)",
         "--jobs=$($jobs)"
     )
+    # Unlike the other shells, we don't ask the terminal for its background
+    # color, so there's no "--background" here.  Set $env:KITSCH_THEME to
+    # "light" or "dark" to pick the theme.
 
     # Whe start from the premise that the command executed correctly, which covers also the fresh console.
     $lastExitCodeForPrompt = 0
@@ -104,8 +134,21 @@ function global:prompt {
 
     $arguments += "--status=$($lastExitCodeForPrompt)"
 
-    # Invoke Kitsch
+    if ($global:KitschTransient) {
+        $arguments += "--transient"
+    }
+
+    # Invoke Kitsch.  PowerShell has no right prompt, so if one is configured,
+    # kitsch will draw it as part of the prompt.
+{{- if .async }}
+    Invoke-Native -Executable {{ .kitschCommand }} -Arguments ($arguments + "--async")
+
+    # Start refreshing async modules once the prompt has been rendered, so we
+    # don't try to redraw the prompt before it's been drawn.
+    Start-AsyncRefresh -Executable {{ .kitschCommand }} -Arguments ($arguments + "--async-refresh")
+{{- else }}
     Invoke-Native -Executable {{ .kitschCommand }} -Arguments $arguments
+{{- end }}
 
     # Propagate the original $LASTEXITCODE from before the prompt function was invoked.
     $global:LASTEXITCODE = $origLastExitCode
@@ -131,6 +174,61 @@ function global:prompt {
 
 }
 
+{{ if .transientPrompt -}}
+# Redraw the prompt as the transient prompt when the user accepts a line, so old
+# prompts in the scrollback are collapsed.
+if (Get-Module PSReadLine) {
+    Set-PSReadLineKeyHandler -Key Enter -ScriptBlock {
+        $line = $null
+        $cursor = $null
+        [Microsoft.PowerShell.PSConsoleReadLine]::GetBufferState([ref]$line, [ref]$cursor)
+
+        # Don't collapse the prompt if the command is incomplete, since PSReadLine
+        # will keep editing on a continuation line.
+        $parseErrors = $null
+        [System.Management.Automation.Language.Parser]::ParseInput($line, [ref]$null, [ref]$parseErrors) | Out-Null
+        if ($parseErrors.Count -eq 0) {
+            $global:KitschTransient = $true
+            try {
+                [Microsoft.PowerShell.PSConsoleReadLine]::InvokePrompt()
+            } finally {
+                $global:KitschTransient = $false
+            }
+        }
+
+        [Microsoft.PowerShell.PSConsoleReadLine]::AcceptLine()
+    }
+}
+
+{{ end -}}
+{{ if .async -}}
+function global:Stop-KitschAsyncRefresh {
+    Unregister-Event -SourceIdentifier KitschAsyncRefresh -ErrorAction Ignore
+    Remove-Job -Name KitschAsyncRefresh -Force -ErrorAction Ignore
+}
+
+# Stop waiting for async modules when a command is run, so we don't redraw the
+# prompt over top of the command's output.
+if (Get-Module PSReadLine) {
+    $global:KitschPreservedAddToHistoryHandler = (Get-PSReadLineOption).AddToHistoryHandler
+    Set-PSReadLineOption -AddToHistoryHandler {
+        param($line)
+        Stop-KitschAsyncRefresh
+        if ($global:KitschPreservedAddToHistoryHandler) {
+            return & $global:KitschPreservedAddToHistoryHandler $line
+        }
+        return $true
+    }
+}
+
+{{ end -}}
+{{ if .continuationPrompt -}}
+# PSReadLine's continuation prompt is a fixed string, so render it once here.
+if (Get-Module PSReadLine) {
+    Set-PSReadLineOption -ContinuationPrompt (@(&{{ .kitschCommand }} prompt --continuation {{with .configFile}}--config "{{.}}" {{end}}--shell=powershell) -join "`n")
+}
+
+{{ end -}}
 # Disable virtualenv prompt, it breaks kitsch
 $ENV:VIRTUAL_ENV_DISABLE_PROMPT=1
 
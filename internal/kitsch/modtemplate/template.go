@@ -45,13 +45,10 @@ func CompileTemplate(styles *styling.Registry, environment env.Env, name string,
 		return buf.String(), err
 	}
 
-	funcMap["env"] = func(name string) string {
-		return environment.Getenv(name)
-	}
-
 	tmpl, err := tmpl.
 		Funcs(funcMap).
 		Funcs(sprigTemplateFunctions).
+		Funcs(envFuncMap(environment)).
 		Funcs(styling.TxtFuncMap(styles)).
 		Funcs(powerline.TxtFuncMap(styles)).
 		Parse(templateString)
@@ -61,6 +58,30 @@ func CompileTemplate(styles *styling.Registry, environment env.Env, name string,
 	return tmpl, nil
 }
 
+// Rebind returns a copy of a template created by CompileTemplate, which uses
+// the given styles and environment instead of the ones the template was
+// compiled with.  This is much cheaper than compiling the template again.
+func Rebind(tmpl *template.Template, styles *styling.Registry, environment env.Env) (*template.Template, error) {
+	result, err := tmpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	return result.
+		Funcs(envFuncMap(environment)).
+		Funcs(styling.TxtFuncMap(styles)).
+		Funcs(powerline.TxtFuncMap(styles)), nil
+}
+
+// envFuncMap returns template functions which read from the environment.
+func envFuncMap(environment env.Env) template.FuncMap {
+	return template.FuncMap{
+		"env": func(name string) string {
+			return environment.Getenv(name)
+		},
+	}
+}
+
 // TemplateToString renders a template to a string.
 func TemplateToString(template *template.Template, data interface{}) (string, error) {
 	var b bytes.Buffer
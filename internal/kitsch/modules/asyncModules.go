@@ -0,0 +1,145 @@
+package modules
+
+import (
+	"bytes"
+	gocontext "context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/jwalton/kitsch/internal/kitsch/styling"
+)
+
+// asyncCacheEntry is the value stored in the value cache for an async module.
+type asyncCacheEntry struct {
+	Text       string
+	StartStyle styling.CharacterColors
+	EndStyle   styling.CharacterColors
+}
+
+// asyncCacheKey returns the key used to store the result of this module in the
+// value cache.  Results are stored per-directory, since most modules produce
+// different output in different directories.
+func (wrapper ModuleWrapper) asyncCacheKey(context *Context) string {
+	hash := sha256.Sum256([]byte(wrapper.String() + "\x00" + context.Globals.CWD))
+	return "async-" + hex.EncodeToString(hash[:])
+}
+
+// getAsyncResult returns the last result generated for this async module from
+// the value cache, or the module's placeholder if there is no cached result.
+func (wrapper ModuleWrapper) getAsyncResult(ctx gocontext.Context, context *Context) ModuleWrapperResult {
+	if context.ValueCache != nil {
+		var entry asyncCacheEntry
+		value := context.ValueCache.Get(wrapper.asyncCacheKey(context))
+		if value != nil && json.Unmarshal(value, &entry) == nil {
+			return ModuleWrapperResult{
+				Text:       entry.Text,
+				StartStyle: entry.StartStyle,
+				EndStyle:   entry.EndStyle,
+			}
+		}
+	}
+
+	// Show the placeholder.  We don't run the template here, since the
+	// template is expecting data from the module.
+	result := ModuleWrapperResult{Text: wrapper.config.AsyncPlaceholder}
+	style := context.GetStyle(ctx, wrapper.config.Style)
+	if style != nil && result.Text != "" {
+		result.Text, result.StartStyle, result.EndStyle = style.ApplyGetColors(result.Text)
+	}
+	return result
+}
+
+// HasAsyncModules returns true if this module, or any of its children, is an
+// async module.
+func (wrapper ModuleWrapper) HasAsyncModules() bool {
+	found := false
+	wrapper.walk(func(child ModuleWrapper) bool {
+		found = found || child.config.Async
+		return !found
+	})
+	return found
+}
+
+// walk calls `fn` for this module and every module beneath it.  If `fn`
+// returns false, the children of that module will be skipped.
+func (wrapper ModuleWrapper) walk(fn func(child ModuleWrapper) bool) {
+	if wrapper.Module == nil || !fn(wrapper) {
+		return
+	}
+
+	if block, ok := wrapper.Module.(*BlockModule); ok {
+		for _, child := range block.Modules {
+			child.walk(fn)
+		}
+	}
+}
+
+// asyncTimeoutMultiplier is how many times longer than the default module
+// timeout an async module is allowed to run for, if it has no timeout of its
+// own.
+const asyncTimeoutMultiplier = 10
+
+// asyncTimeout returns the timeout to use when refreshing this async module.
+// Since async modules run in the background, they get much longer than the
+// default timeout, but we still don't want a hung command to keep the refresh
+// running forever.
+func (wrapper ModuleWrapper) asyncTimeout(context *Context) time.Duration {
+	if wrapper.config.Timeout > 0 {
+		return time.Duration(wrapper.config.Timeout) * time.Millisecond
+	}
+	return asyncTimeoutMultiplier * context.DefaultTimeout
+}
+
+// RefreshAsyncModules executes every async module in the given prompt and
+// stores the results in the value cache, so they can be picked up the next
+// time the prompt is rendered with `context.Async` set.  Returns true if the
+// result of any module changed.
+func RefreshAsyncModules(context *Context, root ModuleWrapper) bool {
+	asyncModules := []ModuleWrapper{}
+	root.walk(func(child ModuleWrapper) bool {
+		if !child.conditionsMatch(gocontext.Background(), context) {
+			// Skip this module and all its children.
+			return false
+		}
+		if child.config.Async {
+			asyncModules = append(asyncModules, child)
+			return false
+		}
+		return true
+	})
+
+	var changed bool
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for _, module := range asyncModules {
+		wg.Add(1)
+		go func(module ModuleWrapper) {
+			defer wg.Done()
+
+			result := module.execute(gocontext.Background(), context, module.asyncTimeout(context))
+
+			value, err := json.Marshal(asyncCacheEntry{
+				Text:       result.Text,
+				StartStyle: result.StartStyle,
+				EndStyle:   result.EndStyle,
+			})
+			if err != nil {
+				return
+			}
+
+			key := module.asyncCacheKey(context)
+			if !bytes.Equal(context.ValueCache.Get(key), value) {
+				context.ValueCache.Set(key, value)
+				mutex.Lock()
+				changed = true
+				mutex.Unlock()
+			}
+		}(module)
+	}
+	wg.Wait()
+
+	return changed
+}
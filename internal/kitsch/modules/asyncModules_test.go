@@ -0,0 +1,83 @@
+package modules
+
+import (
+	gocontext "context"
+	"testing"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsyncModule(t *testing.T) {
+	blockMod := moduleWrapperFromYAML(heredoc.Doc(`
+		type: block
+		modules:
+		- type: text
+		  text: hello
+		- type: text
+		  text: world
+		  async: true
+		  asyncPlaceholder: "..."
+    `))
+
+	assert.True(t, blockMod.HasAsyncModules())
+
+	context := newTestContext("jwalton")
+
+	// Async modules are rendered normally if async is disabled.
+	result := blockMod.Execute(gocontext.Background(), context)
+	assert.Equal(t, "hello world", result.Text)
+
+	// Should show the placeholder when there's no cached value.
+	context.Async = true
+	result = blockMod.Execute(gocontext.Background(), context)
+	assert.Equal(t, "hello ...", result.Text)
+
+	// Refreshing should update the cache.
+	assert.True(t, RefreshAsyncModules(context, blockMod))
+	result = blockMod.Execute(gocontext.Background(), context)
+	assert.Equal(t, "hello world", result.Text)
+
+	// Refreshing again should produce the same result.
+	assert.False(t, RefreshAsyncModules(context, blockMod))
+
+	// Cached values should be stored per-directory.
+	context.Globals.CWD = "/tmp"
+	result = blockMod.Execute(gocontext.Background(), context)
+	assert.Equal(t, "hello ...", result.Text)
+}
+
+func TestHasAsyncModules(t *testing.T) {
+	blockMod := moduleWrapperFromYAML(heredoc.Doc(`
+		type: block
+		modules:
+		- type: text
+		  text: hello
+    `))
+
+	assert.False(t, blockMod.HasAsyncModules())
+	assert.False(t, ModuleWrapper{}.HasAsyncModules())
+}
+
+func TestAsyncModuleTimeout(t *testing.T) {
+	context := newTestContext("jwalton")
+	context.DefaultTimeout = 500 * time.Millisecond
+
+	// Async modules get ten times the default timeout.
+	mod := moduleWrapperFromYAML(heredoc.Doc(`
+		type: text
+		text: hello
+		async: true
+	`))
+	assert.Equal(t, 5*time.Second, mod.asyncTimeout(context))
+
+	// An explicit timeout is used as is.
+	mod = moduleWrapperFromYAML(heredoc.Doc(`
+		type: text
+		text: hello
+		async: true
+		timeout: 20
+	`))
+	assert.Equal(t, 20*time.Millisecond, mod.asyncTimeout(context))
+}
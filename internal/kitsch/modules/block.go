@@ -1,11 +1,11 @@
 package modules
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"text/template"
 
-	"github.com/jwalton/kitsch/internal/kitsch/log"
 	"github.com/jwalton/kitsch/internal/kitsch/modtemplate"
 	"github.com/jwalton/kitsch/internal/kitsch/modules/schemas"
 	"github.com/jwalton/kitsch/internal/kitsch/styling"
@@ -32,6 +32,11 @@ type BlockModule struct {
 	// next module, and Index is the index of the current module in the modules
 	// array.
 	Join string
+	// ContinuousGradient, if true, will cause a gradient in this block's style
+	// to be applied across the joined text of all child modules, replacing
+	// any colors set by the children, so the gradient flows continuously from
+	// the first child to the last instead of being interrupted by each child.
+	ContinuousGradient bool `yaml:"continuousGradient"`
 }
 
 type blockModuleResult struct {
@@ -44,12 +49,12 @@ type blockModuleResult struct {
 }
 
 // Execute the block module.
-func (mod BlockModule) Execute(context *Context) ModuleResult {
+func (mod BlockModule) Execute(ctx context.Context, context *Context) ModuleResult {
 	resultsArray := make([]ModuleWrapperResult, 0, len(mod.Modules))
 	childDurations := perf.New(len(mod.Modules))
 	resultsByID := make(map[string]ModuleWrapperResult, len(mod.Modules))
 
-	moduleResults := executeModules(context, mod.Modules)
+	moduleResults := executeModules(ctx, context, mod.Modules)
 	for index := range moduleResults {
 		wrapper := mod.Modules[index]
 		result := moduleResults[index]
@@ -61,25 +66,15 @@ func (mod BlockModule) Execute(context *Context) ModuleResult {
 			resultsArray = append(resultsArray, result)
 		}
 
-		id := wrapper.config.ID
-		if id == "" {
-			_, typeInUse := resultsByID[wrapper.config.Type]
-			if !typeInUse {
-				// If the module has no ID, use its type.
-				id = wrapper.config.Type
-			} else {
-				id = fmt.Sprintf("%s(%d:%d)", wrapper.config.Type, wrapper.Line, wrapper.Column)
-			}
-		}
-
-		resultsByID[id] = result
+		resultsByID[childID(wrapper, resultsByID)] = result
 	}
 
-	defaultText := mod.joinChildren(context, resultsArray)
+	defaultText := mod.joinChildren(ctx, context, resultsArray)
 
 	result := ModuleResult{
-		DefaultText: defaultText,
-		Performance: childDurations,
+		DefaultText:        defaultText,
+		ContinuousGradient: mod.ContinuousGradient,
+		Performance:        childDurations,
 		Data: blockModuleResult{
 			Modules:     resultsByID,
 			ModuleArray: resultsArray,
@@ -101,6 +96,51 @@ func (mod BlockModule) Execute(context *Context) ModuleResult {
 	return result
 }
 
+// childID returns the ID to use for a child module in `blockModuleResult.Modules`.
+// `resultsByID` is the results for the child modules before this one.
+func childID(wrapper ModuleWrapper, resultsByID map[string]ModuleWrapperResult) string {
+	if wrapper.config.ID != "" {
+		return wrapper.config.ID
+	}
+
+	if _, typeInUse := resultsByID[wrapper.config.Type]; !typeInUse {
+		// If the module has no ID, use its type.
+		return wrapper.config.Type
+	}
+
+	return fmt.Sprintf("%s(%d:%d)", wrapper.config.Type, wrapper.Line, wrapper.Column)
+}
+
+// Validate validates the join template and all child modules.
+func (mod BlockModule) Validate(validation *Validation) {
+	if strings.Contains(mod.Join, "{{") {
+		validation.Template("join template", mod.Join, blockJoinData{
+			Globals: &validation.Context.Globals,
+			Index:   1,
+		})
+	}
+
+	for _, child := range mod.Modules {
+		validation.Module(child)
+	}
+}
+
+// exampleData returns a blockModuleResult where every child has example data.
+func (mod BlockModule) exampleData() interface{} {
+	resultsByID := make(map[string]ModuleWrapperResult, len(mod.Modules))
+	resultsArray := make([]ModuleWrapperResult, 0, len(mod.Modules))
+	for _, child := range mod.Modules {
+		result := ModuleWrapperResult{Data: exampleData(child)}
+		resultsByID[childID(child, resultsByID)] = result
+		resultsArray = append(resultsArray, result)
+	}
+
+	return blockModuleResult{
+		Modules:     resultsByID,
+		ModuleArray: resultsArray,
+	}
+}
+
 // blockJoinData is the data passed to the join template.
 type blockJoinData struct {
 	// Globals are the global variables.
@@ -113,7 +153,7 @@ type blockJoinData struct {
 	Index int
 }
 
-func (mod BlockModule) joinChildren(context *Context, children []ModuleWrapperResult) string {
+func (mod BlockModule) joinChildren(ctx context.Context, context *Context, children []ModuleWrapperResult) string {
 	out := strings.Builder{}
 
 	var join *template.Template = nil
@@ -133,6 +173,7 @@ func (mod BlockModule) joinChildren(context *Context, children []ModuleWrapperRe
 			var err error
 			join, err = modtemplate.CompileTemplate(context.Styles, context.Environment, "join", mod.Join)
 			if err != nil {
+				context.AddDiagnostic(ctx, DiagnosticTemplate, "Error compiling join template: "+err.Error())
 				join = nil
 			}
 		}
@@ -147,7 +188,7 @@ func (mod BlockModule) joinChildren(context *Context, children []ModuleWrapperRe
 					Index:      index,
 				})
 				if err != nil {
-					log.Warn(err.Error())
+					context.AddDiagnostic(ctx, DiagnosticTemplate, "Error executing join template: "+err.Error())
 					joiner = " "
 				}
 				out.WriteString(joiner)
@@ -177,7 +218,7 @@ func init() {
 // executeModules executes an array of modules in parallel.  It returns an array
 // of the same length as `modules`, where each value in the resulting array
 // contains the result of executing the corresponding module.
-func executeModules(context *Context, modules []ModuleWrapper) []ModuleWrapperResult {
+func executeModules(ctx context.Context, context *Context, modules []ModuleWrapper) []ModuleWrapperResult {
 	type chResult struct {
 		index int
 		value ModuleWrapperResult
@@ -187,8 +228,9 @@ func executeModules(context *Context, modules []ModuleWrapper) []ModuleWrapperRe
 	ch := make(chan chResult)
 
 	// Create a goroutine for each module.
+	moduleContexts := explainChildren(ctx, modules)
 	executeModule := func(index int, module ModuleWrapper) {
-		ch <- chResult{index, module.Execute(context)}
+		ch <- chResult{index, module.Execute(moduleContexts[index], context)}
 	}
 	for i, module := range modules {
 		go executeModule(i, module)
@@ -1,9 +1,11 @@
 package modules
 
 import (
+	gocontext "context"
 	"testing"
 
 	"github.com/MakeNowJust/heredoc"
+	"github.com/jwalton/gchalk"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -17,7 +19,7 @@ func TestBlock(t *testing.T) {
 		  text: world
     `))
 
-	result := blockMod.Execute(newTestContext("jwalton"))
+	result := blockMod.Execute(gocontext.Background(), newTestContext("jwalton"))
 	assert.Equal(t, "hello world", result.Text)
 }
 
@@ -34,10 +36,54 @@ func TestBlockStyles(t *testing.T) {
 		  text: world
     `))
 
-	result := blockMod.Execute(newTestContext("jwalton"))
+	result := blockMod.Execute(gocontext.Background(), newTestContext("jwalton"))
 	assert.Equal(t, "hello redblue world", result.Text)
 }
 
+func TestBlockContinuousGradient(t *testing.T) {
+	level := gchalk.GetLevel()
+	gchalk.SetLevel(gchalk.LevelAnsi16m)
+	defer gchalk.SetLevel(level)
+
+	blockMod := moduleWrapperFromYAML(heredoc.Doc(`
+		type: block
+		style: "bg:linear-gradient(#f00, #f00)"
+		continuousGradient: true
+		join: ""
+		modules:
+		- type: text
+		  style: bg:blue
+		  text: ab
+		- type: text
+		  text: cd
+    `))
+
+	result := blockMod.Execute(gocontext.Background(), newTestContext("jwalton"))
+	assert.Equal(t,
+		"\u001b[44m\u001b[48;2;255;0;0mab\u001b[48;2;255;0;0mcd\u001b[49m",
+		result.Text,
+	)
+
+	// Without continuousGradient, the child's background should be left alone.
+	blockMod = moduleWrapperFromYAML(heredoc.Doc(`
+		type: block
+		style: "bg:linear-gradient(#f00, #f00)"
+		join: ""
+		modules:
+		- type: text
+		  style: bg:blue
+		  text: ab
+		- type: text
+		  text: cd
+    `))
+
+	result = blockMod.Execute(gocontext.Background(), newTestContext("jwalton"))
+	assert.Equal(t,
+		"\u001b[44mab\u001b[48;2;255;0;0mcd\u001b[49m",
+		result.Text,
+	)
+}
+
 // TestBlockSubIDs verifies that the results of child modules can be indexed by ID.
 func TestBlockSubIDs(t *testing.T) {
 	blockMod := moduleWrapperFromYAML(heredoc.Doc(`
@@ -50,7 +96,7 @@ func TestBlockSubIDs(t *testing.T) {
 		- type: prompt
     `))
 
-	result := blockMod.Execute(newTestContext("oriana"))
+	result := blockMod.Execute(gocontext.Background(), newTestContext("oriana"))
 
 	assert.Equal(t, "oriana", result.Text)
 }
@@ -64,7 +110,7 @@ func TestBlockSubIDsNoData(t *testing.T) {
 		  text: ""
     `))
 
-	result := blockMod.Execute(newTestContext("oriana"))
+	result := blockMod.Execute(gocontext.Background(), newTestContext("oriana"))
 
 	// This should render text, because the "text" module should be included
 	// in .Data.Modules, even though there was no output.
@@ -1,10 +1,10 @@
 package modules
 
 import (
+	"context"
 	"fmt"
 	"time"
 
-	"github.com/jwalton/kitsch/internal/kitsch/log"
 	"github.com/jwalton/kitsch/internal/kitsch/modules/schemas"
 	"gopkg.in/yaml.v3"
 )
@@ -35,7 +35,7 @@ type cmdDurationModuleResult struct {
 }
 
 // Execute the module.
-func (mod CmdDurationModule) Execute(context *Context) ModuleResult {
+func (mod CmdDurationModule) Execute(ctx context.Context, context *Context) ModuleResult {
 	var durationStr string
 	if context.Globals.PreviousCommandDuration < mod.MinTime {
 		durationStr = ""
@@ -63,15 +63,10 @@ func (mod CmdDurationModule) formatDuration(timeInMs int64) string {
 }
 
 // Validate validates this module.
-func (mod CmdDurationModule) Validate(context *Context, prefix string) {
+func (mod CmdDurationModule) Validate(validation *Validation) {
 	if mod.MinTime < 0 {
-		log.Warn(fmt.Sprintf("%s: Invalid minTime: %d", prefix, mod.MinTime))
+		validation.Report(DiagnosticConfig, fmt.Sprintf("Invalid minTime: %d", mod.MinTime))
 	}
-
-	// testTemplate(context, prefix, mod.Template, map[string]interface{}{
-	// 	"Zero duration": cmdDurationModuleResult{Duration: 0, PrettyDuration: ""},
-	// 	"With duration": cmdDurationModuleResult{Duration: 20, PrettyDuration: "20s"},
-	// })
 }
 
 func init() {
@@ -79,6 +74,7 @@ func init() {
 		"command_duration",
 		registeredModule{
 			jsonSchema: schemas.CmdDurationModuleJSONSchema,
+			data:       cmdDurationModuleResult{},
 			factory: func(node *yaml.Node) (Module, error) {
 				module := CmdDurationModule{Type: "command_duration", MinTime: 2000}
 				err := node.Decode(&module)
@@ -1,6 +1,7 @@
 package modules
 
 import (
+	gocontext "context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -11,7 +12,7 @@ func TestCmdDuration(t *testing.T) {
 
 	forTime := func(mod Module, time int) string {
 		context.Globals.PreviousCommandDuration = int64(time)
-		result := mod.Execute(context)
+		result := mod.Execute(gocontext.Background(), context)
 		return result.DefaultText
 	}
 
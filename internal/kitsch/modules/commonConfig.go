@@ -22,16 +22,33 @@ type CommonConfig struct {
 	Template string `yaml:"template"`
 	// Conditions are conditions that must be met for this module to execute.
 	Conditions *condition.Conditions `yaml:"conditions,omitempty" jsonschema:",ref"`
+	// If is a golang template expression, like `ne .Globals.Status 0`.  If
+	// the expression is false, the module will not be executed.
+	If string `yaml:"if"`
 	// Timeout is the maximum amount of time, in milliseconds, to wait for this
 	// module to execute.  If not specified, the default timeout for most modules
 	// will be 200ms, but for block modules it will be infinite.
 	Timeout int64 `yaml:"timeout"`
+	// Async, if true, will cause this module to be rendered in the background.
+	// The prompt will show the last value this module produced in the current
+	// directory (or AsyncPlaceholder if there is no such value), and will be
+	// redrawn when the module finishes.  Only zsh and powershell support async
+	// modules - in other shells async modules are rendered normally.
+	Async bool `yaml:"async"`
+	// AsyncPlaceholder is the text to show for an async module while it is
+	// being rendered, if there is no previous value to show.
+	AsyncPlaceholder string `yaml:"asyncPlaceholder"`
 }
 
-// Validate checks for common configuration errors in the CommonConfig, and prints
-// errors to the log if any are found.
-func (config *CommonConfig) Validate(context *Context, prefix string) {
-	context.GetStyle(config.Style)
+// Validate checks for common configuration errors in the CommonConfig.
+func (config *CommonConfig) Validate(validation *Validation) {
+	validation.Style("style", config.Style)
+	if config.Timeout < 0 {
+		validation.Report(DiagnosticConfig, fmt.Sprintf("Invalid timeout: %d", config.Timeout))
+	}
+	if err := config.Conditions.Validate(); err != nil {
+		validation.Report(DiagnosticConfig, "Invalid conditions: "+err.Error())
+	}
 }
 
 func getCommonConfig(node *yaml.Node) (CommonConfig, error) {
@@ -1,6 +1,7 @@
 package modules
 
 import (
+	"context"
 	"io/fs"
 	"os"
 	"sync"
@@ -47,6 +48,12 @@ type Globals struct {
 	TerminalWidth int `yaml:"width"`
 	// PathSeparator is the path separator for the current system.
 	PathSeparator string `yaml:"pathSeparator"`
+	// Transient is true if we are rendering a transient prompt, which will
+	// replace the prompt in the scrollback after a command is entered.
+	Transient bool `yaml:"transient"`
+	// Diagnostics is a list of problems encountered while rendering the prompt,
+	// such as modules that timed out.
+	Diagnostics []Diagnostic `yaml:"diagnostics"`
 }
 
 // NewGlobals creates a new Globals object.
@@ -132,34 +139,64 @@ type Context struct {
 	// If set, flexible spaces will be replaced with this sentinel value.
 	// See DemoConfig.FlexibleSpaceReplacement for details.
 	FlexibleSpaceReplacement string
+	// Async is true if modules with `async: true` should be rendered from the
+	// value cache, instead of being executed.  See RefreshAsyncModules.
+	Async bool
 
 	mutex          sync.Mutex
 	gitInitialized bool
 	git            gitutils.Git
+
+	diagnosticsMutex sync.Mutex
+	diagnostics      []Diagnostic
+	// replaying is true if we are re-rendering the prompt after collecting
+	// diagnostics.  See RenderPrompt.
+	replaying       bool
+	memoizedResults map[Module]memoizedResult
+}
+
+// GetterContext returns a GetterContext which can be used to run getters on
+// behalf of a module.  Any commands run by the getters will be killed when
+// ctx is done.
+func (context *Context) GetterContext(ctx context.Context) getters.GetterContext {
+	return moduleGetterContext{ctx: ctx, context: context}
+}
+
+// moduleGetterContext is the GetterContext for a single module execution.
+type moduleGetterContext struct {
+	ctx     context.Context
+	context *Context
+}
+
+// Context returns the context for the current module execution.
+func (getterContext moduleGetterContext) Context() context.Context {
+	return getterContext.ctx
 }
 
 // GetWorkingDirectory returns the current working directory.
-func (context *Context) GetWorkingDirectory() fileutils.Directory {
-	return context.Directory
+func (getterContext moduleGetterContext) GetWorkingDirectory() fileutils.Directory {
+	return getterContext.context.Directory
 }
 
 // GetHomeDirectoryPath returns the full path to the user's home directory.
-func (context *Context) GetHomeDirectoryPath() string {
-	return context.Globals.Home
+func (getterContext moduleGetterContext) GetHomeDirectoryPath() string {
+	return getterContext.context.Globals.Home
 }
 
 // Getenv returns the value of the specified environment variable.
-func (context *Context) Getenv(key string) string {
-	return context.Environment.Getenv(key)
+func (getterContext moduleGetterContext) Getenv(key string) string {
+	return getterContext.context.Environment.Getenv(key)
 }
 
-// GetValueCache returns the value cache.
-func (context *Context) GetValueCache() cache.Cache {
-	return context.ValueCache
+// Environ returns the environment to run external commands in.
+func (getterContext moduleGetterContext) Environ() []string {
+	return getterContext.context.Environment.Environ()
 }
 
-// Make sure that Context implements the GetterContext interface.
-var _ getters.GetterContext = (*Context)(nil)
+// GetValueCache returns the value cache.
+func (getterContext moduleGetterContext) GetValueCache() cache.Cache {
+	return getterContext.context.ValueCache
+}
 
 // Git returns a git instance for the current repo, or nil if the current
 // working directory is not part of a git repo, or git is not installed.
@@ -168,18 +205,49 @@ func (context *Context) Git() gitutils.Git {
 	defer context.mutex.Unlock()
 
 	if !context.gitInitialized {
-		context.git = gitutils.NewCaching("git", context.Globals.CWD)
+		// Find git using the PATH from the environment we're rendering for,
+		// which may not be our own if we're running as a daemon.
+		pathToGit, err := fileutils.LookPathSafeEnv("git", context.Getenv)
+		if err != nil {
+			pathToGit = ""
+		}
+		context.git = gitutils.NewCaching(pathToGit, context.Globals.CWD, context.Environment.Environ())
 		context.gitInitialized = true
 	}
 	return context.git
 }
 
-// GetStyle returns the specified style, or logs a warning and returns an empty style
-// if the style string cannot be parsed.
-func (context *Context) GetStyle(styleString string) *styling.Style {
+// GetWorkingDirectory returns the current working directory.
+func (context *Context) GetWorkingDirectory() fileutils.Directory {
+	return context.Directory
+}
+
+// Getenv returns the value of the specified environment variable.
+func (context *Context) Getenv(key string) string {
+	return context.Environment.Getenv(key)
+}
+
+// IsGitRepo returns true if the current working directory is part of a git repo.
+func (context *Context) IsGitRepo() bool {
+	return context.Git() != nil
+}
+
+// IsRoot returns true if the current user is root.
+func (context *Context) IsRoot() bool {
+	return context.Globals.IsRoot
+}
+
+// PreviousCommandStatus returns the exit status of the previous command.
+func (context *Context) PreviousCommandStatus() int {
+	return context.Globals.Status
+}
+
+// GetStyle returns the specified style, or records a diagnostic and returns an
+// empty style if the style string cannot be parsed.
+func (context *Context) GetStyle(ctx context.Context, styleString string) *styling.Style {
 	style, err := context.Styles.Get(styleString)
 	if err != nil {
-		log.Warn(`Unable to parse style: "`+styleString+`":`, err.Error())
+		context.AddDiagnostic(ctx, DiagnosticStyle, `Unable to parse style: "`+styleString+`": `+err.Error())
 		style, _ = context.Styles.Get("")
 	}
 
@@ -278,6 +346,7 @@ func NewDemoContext(
 		git:                      config.Git,
 		DefaultTimeout:           1000 * time.Millisecond,
 		FlexibleSpaceReplacement: config.FlexibleSpaceReplacement,
+		diagnostics:              append([]Diagnostic(nil), config.Globals.Diagnostics...),
 	}
 }
 
@@ -0,0 +1,192 @@
+package modules
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/jwalton/kitsch/internal/kitsch/log"
+)
+
+// DiagnosticKind is the kind of problem described by a Diagnostic.
+type DiagnosticKind string
+
+const (
+	// DiagnosticTimeout is recorded when a module times out.
+	DiagnosticTimeout DiagnosticKind = "timeout"
+	// DiagnosticTemplate is recorded when a template fails to compile or execute.
+	DiagnosticTemplate DiagnosticKind = "template"
+	// DiagnosticStyle is recorded when a style string can't be parsed.
+	DiagnosticStyle DiagnosticKind = "style"
+	// DiagnosticGetter is recorded when a module fails to get a value from a
+	// file, command, or environment variable.
+	DiagnosticGetter DiagnosticKind = "getter"
+	// DiagnosticConfig is reported by ValidateModule when a module has an
+	// invalid setting.
+	DiagnosticConfig DiagnosticKind = "config"
+)
+
+// Diagnostic is a problem encountered while rendering the prompt.
+type Diagnostic struct {
+	// Kind is the kind of problem.  One of "timeout", "template", "style",
+	// "getter", or "config".
+	Kind DiagnosticKind `yaml:"kind" json:"kind"`
+	// Module is a description of the module that had the problem, e.g.
+	// "git_status(12:5)".  This will be empty if the problem wasn't caused
+	// by a specific module.
+	Module string `yaml:"module" json:"module"`
+	// Message is a description of the problem.
+	Message string `yaml:"message" json:"message"`
+}
+
+func (diagnostic Diagnostic) String() string {
+	if diagnostic.Module == "" {
+		return "[" + string(diagnostic.Kind) + "] " + diagnostic.Message
+	}
+	return "[" + string(diagnostic.Kind) + "] " + diagnostic.Module + ": " + diagnostic.Message
+}
+
+// moduleDescriptionKey is the key used to store the description of the
+// currently executing module in a module's context.
+type moduleDescriptionKey struct{}
+
+// withModuleDescription returns a copy of ctx which records that the given
+// module is executing.
+func withModuleDescription(ctx context.Context, wrapper ModuleWrapper) context.Context {
+	return context.WithValue(ctx, moduleDescriptionKey{}, wrapper.String())
+}
+
+// AddDiagnostic records a problem encountered while rendering the prompt.
+// `ctx` should be the context passed to `Module.Execute()`, and is used to
+// work out which module the problem belongs to.
+func (context *Context) AddDiagnostic(ctx context.Context, kind DiagnosticKind, message string) {
+	if context.replaying {
+		// We already recorded this the first time around.
+		return
+	}
+
+	diagnostic := Diagnostic{Kind: kind, Message: message}
+	if ctx != nil {
+		diagnostic.Module, _ = ctx.Value(moduleDescriptionKey{}).(string)
+	}
+
+	log.Warn(diagnostic.String())
+
+	context.diagnosticsMutex.Lock()
+	defer context.diagnosticsMutex.Unlock()
+	context.diagnostics = append(context.diagnostics, diagnostic)
+}
+
+// Diagnostics returns a list of all problems encountered while rendering the
+// prompt.
+func (context *Context) Diagnostics() []Diagnostic {
+	context.diagnosticsMutex.Lock()
+	defer context.diagnosticsMutex.Unlock()
+
+	result := make([]Diagnostic, len(context.diagnostics))
+	copy(result, context.diagnostics)
+	return result
+}
+
+// consumesDiagnostics returns true if the prompt rooted at `root` displays the
+// problems found while rendering it, either with a `diagnostics` module or
+// with a template that reads `.Globals.Diagnostics`.  Templates are checked by
+// looking for "Diagnostics" anywhere in the template, so this may return true
+// for prompts that don't actually use diagnostics, but will never return
+// false for prompts that do.
+func consumesDiagnostics(root ModuleWrapper) bool {
+	mentionsDiagnostics := func(tmpl string) bool {
+		return strings.Contains(tmpl, "Diagnostics")
+	}
+
+	found := false
+	root.walk(func(child ModuleWrapper) bool {
+		switch module := child.Module.(type) {
+		case *DiagnosticsModule:
+			found = true
+		case *BlockModule:
+			found = mentionsDiagnostics(module.Join)
+		}
+		found = found || mentionsDiagnostics(child.config.Template) || mentionsDiagnostics(child.config.If)
+		return !found
+	})
+	return found
+}
+
+// memoizedResult is the result of executing a module, saved so the prompt can
+// be re-rendered without executing the module again.
+type memoizedResult struct {
+	result   ModuleResult
+	timedOut bool
+	// duration is how long the module took to execute.
+	duration time.Duration
+}
+
+// canMemoize returns true if the result of the given module can be reused
+// when re-rendering the prompt.  Blocks need to be re-executed so they can
+// pick up changes in their children, and the diagnostics module needs to be
+// re-executed to pick up the diagnostics from the first render.
+func canMemoize(module Module) bool {
+	switch module.(type) {
+	case *BlockModule, *DiagnosticsModule:
+		return false
+	}
+	return isPointer(module)
+}
+
+// isPointer returns true if the given module is a pointer.  We use modules as
+// map keys, so only pointers can be memoized.
+func isPointer(module Module) bool {
+	return module != nil && reflect.ValueOf(module).Kind() == reflect.Ptr
+}
+
+// memoize saves the result of executing a module.  Only the first result for
+// any given module is saved.  Results are saved even for modules which can't
+// be memoized, so re-rendering the prompt can report how long they took the
+// first time.
+func (context *Context) memoize(module Module, result memoizedResult) {
+	if !isPointer(module) {
+		return
+	}
+
+	context.diagnosticsMutex.Lock()
+	defer context.diagnosticsMutex.Unlock()
+
+	if context.memoizedResults == nil {
+		context.memoizedResults = map[Module]memoizedResult{}
+	}
+	if _, ok := context.memoizedResults[module]; !ok {
+		context.memoizedResults[module] = result
+	}
+}
+
+// getMemoizedResult returns the saved result for the given module, if we are
+// re-rendering the prompt.
+func (context *Context) getMemoizedResult(module Module) (memoizedResult, bool) {
+	if !canMemoize(module) {
+		return memoizedResult{}, false
+	}
+	return context.getSavedResult(module)
+}
+
+// firstRenderDuration returns how long the given module took to execute the
+// first time, if we are re-rendering the prompt.
+func (context *Context) firstRenderDuration(module Module) (time.Duration, bool) {
+	result, ok := context.getSavedResult(module)
+	return result.duration, ok
+}
+
+// getSavedResult returns the result saved by `memoize()` for the given module,
+// if we are re-rendering the prompt.
+func (context *Context) getSavedResult(module Module) (memoizedResult, bool) {
+	if !context.replaying || !isPointer(module) {
+		return memoizedResult{}, false
+	}
+
+	context.diagnosticsMutex.Lock()
+	defer context.diagnosticsMutex.Unlock()
+
+	result, ok := context.memoizedResults[module]
+	return result, ok
+}
@@ -1,10 +1,10 @@
 package modules
 
 import (
+	"context"
 	"strings"
 
 	"github.com/jwalton/kitsch/internal/kitsch/getters"
-	"github.com/jwalton/kitsch/internal/kitsch/log"
 	"github.com/jwalton/kitsch/internal/kitsch/modules/schemas"
 	"gopkg.in/yaml.v3"
 )
@@ -41,7 +41,7 @@ type customModuleTextResult struct {
 }
 
 // Execute the module.
-func (mod CustomModule) Execute(context *Context) ModuleResult {
+func (mod CustomModule) Execute(ctx context.Context, context *Context) ModuleResult {
 	getter := getters.CustomGetter{
 		Type:  mod.Type,
 		From:  mod.Command,
@@ -50,9 +50,9 @@ func (mod CustomModule) Execute(context *Context) ModuleResult {
 		Cache: mod.Cache,
 	}
 
-	value, err := getter.GetValue(context)
+	value, err := getter.GetValue(context.GetterContext(ctx))
 	if err != nil {
-		log.Warn("Error executing custom module: ", err)
+		context.AddDiagnostic(ctx, DiagnosticGetter, "Error executing custom module: "+err.Error())
 		value = ""
 	}
 
@@ -67,6 +67,24 @@ func (mod CustomModule) Execute(context *Context) ModuleResult {
 	return ModuleResult{DefaultText: text, Data: value}
 }
 
+// Validate validates the getter settings for this module.
+func (mod CustomModule) Validate(validation *Validation) {
+	getter := getters.CustomGetter{Type: mod.Type, As: mod.As, Regex: mod.Regex}
+	if err := getter.Validate(); err != nil {
+		validation.Report(DiagnosticGetter, err.Error())
+	}
+}
+
+// exampleData returns an example of the data this module returns.
+func (mod CustomModule) exampleData() interface{} {
+	if mod.Regex != "" || mod.As == getters.AsUndefined || mod.As == getters.AsText {
+		return customModuleTextResult{}
+	}
+	// The data will be parsed from JSON, YAML, or TOML, so we don't know what
+	// it will look like.
+	return nil
+}
+
 func init() {
 	registerModule(
 		"custom",
@@ -0,0 +1,68 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jwalton/kitsch/internal/kitsch/modules/schemas"
+	"gopkg.in/yaml.v3"
+)
+
+//go:generate go run ../genSchema/main.go --pkg schemas DiagnosticsModule
+
+// DiagnosticsModule shows an indicator if there were any problems rendering
+// the prompt, such as modules which timed out, or templates which could not
+// be executed.  Run `kitsch diagnostics` to see the details.
+//
+type DiagnosticsModule struct {
+	// Type is the type of this module.
+	Type string `yaml:"type" jsonschema:",required,enum=diagnostics"`
+	// Symbol is the symbol to show in front of the count of problems.  Defaults to "⚠".
+	Symbol string `yaml:"symbol"`
+}
+
+type diagnosticsModuleData struct {
+	// Symbol is the symbol from the configuration.
+	Symbol string
+	// Count is the number of problems encountered while rendering the prompt.
+	Count int
+	// Diagnostics is the list of problems encountered while rendering the prompt.
+	Diagnostics []Diagnostic
+}
+
+// Execute the module.
+func (mod DiagnosticsModule) Execute(ctx context.Context, context *Context) ModuleResult {
+	diagnostics := context.Globals.Diagnostics
+
+	defaultText := ""
+	if len(diagnostics) > 0 {
+		defaultText = fmt.Sprintf("%s%d", mod.Symbol, len(diagnostics))
+	}
+
+	return ModuleResult{
+		DefaultText: defaultText,
+		Data: diagnosticsModuleData{
+			Symbol:      mod.Symbol,
+			Count:       len(diagnostics),
+			Diagnostics: diagnostics,
+		},
+	}
+}
+
+func init() {
+	registerModule(
+		"diagnostics",
+		registeredModule{
+			jsonSchema: schemas.DiagnosticsModuleJSONSchema,
+			data:       diagnosticsModuleData{},
+			factory: func(node *yaml.Node) (Module, error) {
+				module := DiagnosticsModule{
+					Type:   "diagnostics",
+					Symbol: "⚠",
+				}
+				err := node.Decode(&module)
+				return &module, err
+			},
+		},
+	)
+}
@@ -0,0 +1,109 @@
+package modules
+
+import (
+	gocontext "context"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnosticsModule(t *testing.T) {
+	context := newTestContext("jwalton")
+	mod := moduleFromYAML(heredoc.Doc(`
+		type: diagnostics
+	`))
+
+	result := mod.Execute(gocontext.Background(), context)
+	assert.Equal(t, "", result.DefaultText)
+
+	context.Globals.Diagnostics = []Diagnostic{
+		{Kind: DiagnosticTimeout, Module: "git_status(1:1)", Message: "Timed out after 200ms"},
+	}
+	result = mod.Execute(gocontext.Background(), context)
+	assert.Equal(t, "⚠1", result.DefaultText)
+	assert.Equal(t, 1, result.Data.(diagnosticsModuleData).Count)
+}
+
+func TestRenderPromptWithDiagnostics(t *testing.T) {
+	context := newTestContext("jwalton")
+	root := moduleWrapperFromYAML(heredoc.Doc(`
+		type: block
+		modules:
+		  - type: text
+		    text: hello
+		    template: "{{ .Text | noSuchFunction }}"
+		  - type: diagnostics
+		    symbol: "!"
+	`))
+
+	_, text := RenderPrompt(context, root)
+	assert.Equal(t, "hello !1", text)
+
+	diagnostics := context.Diagnostics()
+	assert.Equal(t, 1, len(diagnostics))
+	assert.Equal(t, DiagnosticTemplate, diagnostics[0].Kind)
+	assert.Equal(t, "text(3:5)", diagnostics[0].Module)
+}
+
+func TestRenderPromptWithTimeout(t *testing.T) {
+	context := newTestContext("jwalton")
+	root := moduleWrapperFromYAML(heredoc.Doc(`
+		type: block
+		modules:
+		  - type: diagnostics
+	`))
+	block := root.Module.(*BlockModule)
+	block.Modules = append([]ModuleWrapper{{
+		config: CommonConfig{Type: "sleep", Timeout: 10},
+		Module: &sleepModule{Type: "sleep", Duration: 100, Text: "slow"},
+	}}, block.Modules...)
+
+	_, text := RenderPrompt(context, root)
+	assert.Equal(t, "⚠1", text)
+
+	diagnostics := context.Diagnostics()
+	assert.Equal(t, 1, len(diagnostics))
+	assert.Equal(t, DiagnosticTimeout, diagnostics[0].Kind)
+	assert.Equal(t, "sleep(0:0)", diagnostics[0].Module)
+}
+
+func TestRenderPromptOnlyReplaysWhenDiagnosticsAreUsed(t *testing.T) {
+	context := newTestContext("jwalton")
+	root := moduleWrapperFromYAML(heredoc.Doc(`
+		type: block
+		modules:
+		  - type: text
+		    text: hello
+		    template: "{{ .Text | noSuchFunction }}"
+	`))
+
+	// Nothing displays the diagnostics, so there's no need to re-render.
+	_, text := RenderPrompt(context, root)
+	assert.Equal(t, "hello", text)
+	assert.Equal(t, 1, len(context.Diagnostics()))
+	assert.Equal(t, 0, len(context.Globals.Diagnostics))
+
+	assert.Equal(t, false, consumesDiagnostics(root))
+	assert.Equal(t, true, consumesDiagnostics(moduleWrapperFromYAML(heredoc.Doc(`
+		type: block
+		modules:
+		  - type: text
+		    text: hello
+		  - type: diagnostics
+	`))))
+	assert.Equal(t, true, consumesDiagnostics(moduleWrapperFromYAML(heredoc.Doc(`
+		type: block
+		modules:
+		  - type: text
+		    text: hello
+		    template: "{{ len .Globals.Diagnostics }}"
+	`))))
+	assert.Equal(t, true, consumesDiagnostics(moduleWrapperFromYAML(heredoc.Doc(`
+		type: block
+		join: "{{ if .Globals.Diagnostics }}!{{ end }}"
+		modules:
+		  - type: text
+		    text: hello
+	`))))
+}
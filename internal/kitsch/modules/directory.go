@@ -1,6 +1,7 @@
 package modules
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
@@ -75,7 +76,7 @@ func (mod DirectoryModule) truncateToFolder(path string, truncatePath string) st
 }
 
 // Execute the directory module.
-func (mod DirectoryModule) Execute(context *Context) ModuleResult {
+func (mod DirectoryModule) Execute(ctx context.Context, context *Context) ModuleResult {
 	truncationSymbol := defaultString(mod.TruncationSymbol, defaultTruncationSymbol)
 
 	pathSeparator := context.Globals.PathSeparator
@@ -150,6 +151,7 @@ func init() {
 		"directory",
 		registeredModule{
 			jsonSchema: schemas.DirectoryModuleJSONSchema,
+			data:       directoryModuleResult{},
 			factory: func(node *yaml.Node) (Module, error) {
 				var module DirectoryModule = DirectoryModule{
 					Type:             "directory",
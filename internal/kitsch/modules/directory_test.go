@@ -1,6 +1,7 @@
 package modules
 
 import (
+	gocontext "context"
 	"testing"
 	"testing/fstest"
 
@@ -46,7 +47,7 @@ func makeTestDirectoryModule(
 func TestDirectory(t *testing.T) {
 	context, mod := makeTestDirectoryModule("/", "/tmp/test", "", "{type: directory}")
 
-	result := mod.Execute(context)
+	result := mod.Execute(gocontext.Background(), context)
 	assert.Equal(t, ModuleResult{
 		Data: directoryModuleResult{
 			Path:           "/tmp/test",
@@ -65,7 +66,7 @@ func TestReadOnlyDirectory(t *testing.T) {
 	}
 	context.Directory = fileutils.NewDirectoryTestFS(context.Globals.CWD, fsys)
 
-	result := mod.Execute(context)
+	result := mod.Execute(gocontext.Background(), context)
 	assert.Equal(t, ModuleResult{
 		Data: directoryModuleResult{
 			Path:           "/tmp/test",
@@ -79,42 +80,42 @@ func TestReadOnlyDirectory(t *testing.T) {
 
 func TestRootDirectory(t *testing.T) {
 	context, mod := makeTestDirectoryModule("/", "/", "", "{type: directory}")
-	assert.Equal(t, "/", mod.Execute(context).DefaultText)
+	assert.Equal(t, "/", mod.Execute(gocontext.Background(), context).DefaultText)
 }
 
 func TestRootDirectoryWindows(t *testing.T) {
 	context, mod := makeTestDirectoryModule("\\", "D:\\", "", "{type: directory}")
-	assert.Equal(t, "D:\\", mod.Execute(context).DefaultText)
+	assert.Equal(t, "D:\\", mod.Execute(gocontext.Background(), context).DefaultText)
 }
 
 func TestHomeDirectory(t *testing.T) {
 	context, mod := makeTestDirectoryModule("/", "/Users/jwalton", "", "{type: directory}")
 	context.Globals.CWD = context.Globals.Home
-	assert.Equal(t, "~", mod.Execute(context).DefaultText)
+	assert.Equal(t, "~", mod.Execute(gocontext.Background(), context).DefaultText)
 
 	context.Globals.CWD = context.Globals.Home + "/foo"
-	assert.Equal(t, "~/foo", mod.Execute(context).DefaultText)
+	assert.Equal(t, "~/foo", mod.Execute(gocontext.Background(), context).DefaultText)
 
 	context.Globals.CWD = context.Globals.Home + "/foo/bar/baz"
-	assert.Equal(t, "~/foo/bar/baz", mod.Execute(context).DefaultText)
+	assert.Equal(t, "~/foo/bar/baz", mod.Execute(gocontext.Background(), context).DefaultText)
 
 	context.Globals.CWD = context.Globals.Home + "/foo/bar/baz/qux"
-	assert.Equal(t, "…/bar/baz/qux", mod.Execute(context).DefaultText)
+	assert.Equal(t, "…/bar/baz/qux", mod.Execute(gocontext.Background(), context).DefaultText)
 }
 
 func TestHomeDirectoryWindows(t *testing.T) {
 	context, mod := makeTestDirectoryModule("\\", "C:\\Users\\jwalton", "", "{type: directory}")
 	context.Globals.Home = "C:\\Users\\jwalton"
-	assert.Equal(t, "~", mod.Execute(context).DefaultText)
+	assert.Equal(t, "~", mod.Execute(gocontext.Background(), context).DefaultText)
 
 	context.Globals.CWD = context.Globals.Home + "\\foo"
-	assert.Equal(t, "~\\foo", mod.Execute(context).DefaultText)
+	assert.Equal(t, "~\\foo", mod.Execute(gocontext.Background(), context).DefaultText)
 
 	context.Globals.CWD = context.Globals.Home + "\\foo\\bar\\baz"
-	assert.Equal(t, "~\\foo\\bar\\baz", mod.Execute(context).DefaultText)
+	assert.Equal(t, "~\\foo\\bar\\baz", mod.Execute(gocontext.Background(), context).DefaultText)
 
 	context.Globals.CWD = context.Globals.Home + "\\foo\\bar\\baz\\qux"
-	assert.Equal(t, "…\\bar\\baz\\qux", mod.Execute(context).DefaultText)
+	assert.Equal(t, "…\\bar\\baz\\qux", mod.Execute(gocontext.Background(), context).DefaultText)
 }
 
 func TestDirectoryTruncate(t *testing.T) {
@@ -125,7 +126,7 @@ func TestDirectoryTruncate(t *testing.T) {
 		`),
 	)
 
-	assert.Equal(t, "…/bar/baz/qux", mod.Execute(context).DefaultText)
+	assert.Equal(t, "…/bar/baz/qux", mod.Execute(gocontext.Background(), context).DefaultText)
 }
 
 func TestDirectoryTruncateWindows(t *testing.T) {
@@ -136,13 +137,13 @@ func TestDirectoryTruncateWindows(t *testing.T) {
 		`),
 	)
 
-	assert.Equal(t, "C:\\…\\bar\\baz\\qux", mod.Execute(context).DefaultText)
+	assert.Equal(t, "C:\\…\\bar\\baz\\qux", mod.Execute(gocontext.Background(), context).DefaultText)
 
 	context.Globals.CWD = "C:\\tmp\\foo\\bar\\baz"
-	assert.Equal(t, "C:\\…\\foo\\bar\\baz", mod.Execute(context).DefaultText)
+	assert.Equal(t, "C:\\…\\foo\\bar\\baz", mod.Execute(gocontext.Background(), context).DefaultText)
 
 	context.Globals.CWD = "C:\\tmp\\foo\\bar"
-	assert.Equal(t, "C:\\tmp\\foo\\bar", mod.Execute(context).DefaultText)
+	assert.Equal(t, "C:\\tmp\\foo\\bar", mod.Execute(gocontext.Background(), context).DefaultText)
 }
 
 func TestDirectoryTruncateToGitRepo(t *testing.T) {
@@ -152,16 +153,16 @@ func TestDirectoryTruncateToGitRepo(t *testing.T) {
 			truncationLength: 3
 		`),
 	)
-	assert.Equal(t, "kitsch", mod.Execute(context).DefaultText)
+	assert.Equal(t, "kitsch", mod.Execute(gocontext.Background(), context).DefaultText)
 
 	context.Globals.CWD = "/Users/jwalton/dev/kitsch/src"
-	assert.Equal(t, "kitsch/src", mod.Execute(context).DefaultText)
+	assert.Equal(t, "kitsch/src", mod.Execute(gocontext.Background(), context).DefaultText)
 
 	context.Globals.CWD = "/Users/jwalton/dev/kitsch/src/foo/bar/baz/qux"
-	assert.Equal(t, "kitsch/…/bar/baz/qux", mod.Execute(context).DefaultText)
+	assert.Equal(t, "kitsch/…/bar/baz/qux", mod.Execute(gocontext.Background(), context).DefaultText)
 
 	mod.RepoSymbol = "?"
-	assert.Equal(t, "?kitsch/…/bar/baz/qux", mod.Execute(context).DefaultText)
+	assert.Equal(t, "?kitsch/…/bar/baz/qux", mod.Execute(gocontext.Background(), context).DefaultText)
 
 	context, mod = makeTestDirectoryModule("/", "/Users/jwalton/dev/kitsch", "/Users/jwalton/dev/kitsch",
 		heredoc.Doc(`
@@ -171,10 +172,10 @@ func TestDirectoryTruncateToGitRepo(t *testing.T) {
 		`),
 	)
 	context.Globals.CWD = "/Users/jwalton/dev/kitsch/src"
-	assert.Equal(t, "~/dev/kitsch/src", mod.Execute(context).DefaultText)
+	assert.Equal(t, "~/dev/kitsch/src", mod.Execute(gocontext.Background(), context).DefaultText)
 
 	context.Globals.CWD = "/Users/jwalton/work/dev/kitsch/src"
-	assert.Equal(t, "…/dev/kitsch/src", mod.Execute(context).DefaultText)
+	assert.Equal(t, "…/dev/kitsch/src", mod.Execute(gocontext.Background(), context).DefaultText)
 
 }
 
@@ -184,5 +185,5 @@ func TestWindowLogicalCWD(t *testing.T) {
 
 	context.Globals.logicalCWD = "Env:\\"
 
-	assert.Equal(t, "Env:\\", mod.Execute(context).DefaultText)
+	assert.Equal(t, "Env:\\", mod.Execute(gocontext.Background(), context).DefaultText)
 }
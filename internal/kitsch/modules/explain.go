@@ -0,0 +1,128 @@
+package modules
+
+import (
+	gocontext "context"
+	"time"
+)
+
+// Explanation describes how a single module was rendered.  This is used by
+// `kitsch explain` to help debug configurations.
+type Explanation struct {
+	// Type is the type of the module.
+	Type string
+	// ID is the ID of the module, or "" if it has no ID.
+	ID string
+	// Line is the line number of the module in the configuration file.
+	Line int
+	// Column is the column number of the module in the configuration file.
+	Column int
+	// HasConditions is true if the module has conditions.
+	HasConditions bool
+	// ConditionsMatched is true if the module's conditions matched, or if the
+	// module has no conditions.  If this is false, the module was not executed.
+	ConditionsMatched bool
+	// Async is true if the module is an async module, and the result was
+	// read from the cache.
+	Async bool
+	// TimedOut is true if the module timed out.
+	TimedOut bool
+	// DefaultText is the default text generated by the module, before the
+	// template was applied.
+	DefaultText string
+	// Data is the template data generated by the module.
+	Data interface{}
+	// Style is the style that was applied to the module's output.
+	Style string
+	// Text is the final output of the module.
+	Text string
+	// Duration is the time it took this module to execute.
+	Duration time.Duration
+	// Children contains explanations for any child modules.
+	Children []*Explanation
+}
+
+// explanationKey is the key used to store the Explanation for the currently
+// executing module in a module's context.
+type explanationKey struct{}
+
+// withExplanation returns a copy of ctx which will record how the next module
+// is executed in `explanation`.
+func withExplanation(ctx gocontext.Context, explanation *Explanation) gocontext.Context {
+	return gocontext.WithValue(ctx, explanationKey{}, explanation)
+}
+
+// getExplanation returns the Explanation to record into for the given context,
+// or nil if we're not explaining this module.
+func getExplanation(ctx gocontext.Context) *Explanation {
+	explanation, _ := ctx.Value(explanationKey{}).(*Explanation)
+	return explanation
+}
+
+// start resets the explanation, and fills in details about the module.
+func (explanation *Explanation) start(wrapper ModuleWrapper) {
+	if explanation == nil {
+		return
+	}
+
+	*explanation = Explanation{
+		Type:              wrapper.config.Type,
+		ID:                wrapper.config.ID,
+		Line:              wrapper.Line,
+		Column:            wrapper.Column,
+		HasConditions:     !wrapper.config.Conditions.IsEmpty() || wrapper.config.If != "",
+		ConditionsMatched: true,
+	}
+}
+
+// finish records the result of executing the module.
+func (explanation *Explanation) finish(
+	wrapper ModuleWrapper,
+	moduleResult ModuleResult,
+	result ModuleWrapperResult,
+) {
+	if explanation == nil {
+		return
+	}
+
+	explanation.DefaultText = moduleResult.DefaultText
+	explanation.Data = moduleResult.Data
+	explanation.Style = wrapper.config.Style
+	if moduleResult.StyleOverride != "" {
+		explanation.Style = moduleResult.StyleOverride
+	}
+	explanation.Text = result.Text
+	explanation.Duration = result.Duration
+}
+
+// explainChildren creates an Explanation for each of the given child modules,
+// and returns a context for executing each one.  If we aren't explaining the
+// parent module, this will return `ctx` for every child.
+func explainChildren(ctx gocontext.Context, modules []ModuleWrapper) []gocontext.Context {
+	contexts := make([]gocontext.Context, len(modules))
+	parent := getExplanation(ctx)
+
+	if parent != nil {
+		parent.Children = make([]*Explanation, len(modules))
+	}
+
+	for index := range modules {
+		if parent == nil {
+			contexts[index] = ctx
+		} else {
+			child := &Explanation{}
+			parent.Children[index] = child
+			contexts[index] = withExplanation(ctx, child)
+		}
+	}
+
+	return contexts
+}
+
+// ExplainPrompt renders the top-level module in a prompt, the same as
+// RenderPrompt, and returns an explanation of how each module was rendered.
+func ExplainPrompt(context *Context, root ModuleWrapper) (*Explanation, string) {
+	explanation := &Explanation{}
+	ctx := withExplanation(gocontext.Background(), explanation)
+	_, text := renderPrompt(ctx, context, root)
+	return explanation, text
+}
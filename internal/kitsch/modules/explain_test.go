@@ -0,0 +1,91 @@
+package modules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplainPrompt(t *testing.T) {
+	context := newTestContext("jwalton")
+	root := moduleWrapperFromYAML(heredoc.Doc(`
+		type: block
+		id: root
+		modules:
+		  - type: text
+		    text: hello
+		    style: red
+		    template: "<{{ .Text }}>"
+		  - type: text
+		    text: never
+		    conditions:
+		      ifFiles: ["missing.txt"]
+		  - type: block
+		    modules:
+		      - type: text
+		        text: world
+	`))
+
+	explanation, text := ExplainPrompt(context, root)
+	assert.Equal(t, "<hello> world", text)
+
+	assert.Equal(t, "block", explanation.Type)
+	assert.Equal(t, "root", explanation.ID)
+	assert.Equal(t, 1, explanation.Line)
+	assert.Equal(t, 3, len(explanation.Children))
+
+	hello := explanation.Children[0]
+	assert.Equal(t, "text", hello.Type)
+	assert.Equal(t, 4, hello.Line)
+	assert.Equal(t, 5, hello.Column)
+	assert.True(t, hello.ConditionsMatched)
+	assert.False(t, hello.TimedOut)
+	assert.Equal(t, "hello", hello.DefaultText)
+	assert.Equal(t, "red", hello.Style)
+	assert.Equal(t, "<hello>", hello.Text)
+
+	never := explanation.Children[1]
+	assert.True(t, never.HasConditions)
+	assert.False(t, never.ConditionsMatched)
+	assert.Equal(t, "", never.Text)
+
+	nested := explanation.Children[2]
+	assert.Equal(t, 1, len(nested.Children))
+	assert.Equal(t, "world", nested.Children[0].Text)
+}
+
+func TestExplainPromptDurationsWithDiagnostics(t *testing.T) {
+	context := newTestContext("jwalton")
+	root := moduleWrapperFromYAML(heredoc.Doc(`
+		type: block
+		modules:
+		  - type: diagnostics
+	`))
+	block := root.Module.(*BlockModule)
+	block.Modules = append([]ModuleWrapper{
+		{
+			config: CommonConfig{Type: "sleep", Timeout: 20},
+			Module: &sleepModule{Type: "sleep", Duration: 200, Text: "timeout"},
+		},
+		{
+			config: CommonConfig{Type: "sleep", Timeout: 1000},
+			Module: &sleepModule{Type: "sleep", Duration: 30, Text: "slow"},
+		},
+	}, block.Modules...)
+
+	// The timeout will cause the prompt to be rendered a second time, but we
+	// should still see how long each module took the first time around.
+	explanation, text := ExplainPrompt(context, root)
+	assert.Equal(t, "slow ⚠1", text)
+
+	timedOut := explanation.Children[0]
+	assert.True(t, timedOut.TimedOut)
+	assert.GreaterOrEqual(t, timedOut.Duration, 20*time.Millisecond)
+
+	slow := explanation.Children[1]
+	assert.GreaterOrEqual(t, slow.Duration, 30*time.Millisecond)
+
+	assert.GreaterOrEqual(t, explanation.Duration, 30*time.Millisecond)
+}
@@ -1,10 +1,10 @@
 package modules
 
 import (
+	"context"
 	"strings"
 
 	"github.com/jwalton/kitsch/internal/kitsch/getters"
-	"github.com/jwalton/kitsch/internal/kitsch/log"
 	"github.com/jwalton/kitsch/internal/kitsch/modules/schemas"
 	"gopkg.in/yaml.v3"
 )
@@ -39,7 +39,7 @@ type fileModuleTextResult struct {
 }
 
 // Execute the module.
-func (mod FileModule) Execute(context *Context) ModuleResult {
+func (mod FileModule) Execute(ctx context.Context, context *Context) ModuleResult {
 	getter := getters.CustomGetter{
 		Type:  mod.Type,
 		From:  mod.File,
@@ -47,9 +47,9 @@ func (mod FileModule) Execute(context *Context) ModuleResult {
 		Regex: mod.Regex,
 	}
 
-	value, err := getter.GetValue(context)
+	value, err := getter.GetValue(context.GetterContext(ctx))
 	if err != nil {
-		log.Warn("Error executing file module: ", err)
+		context.AddDiagnostic(ctx, DiagnosticGetter, "Error executing file module: "+err.Error())
 		value = ""
 	}
 
@@ -64,6 +64,24 @@ func (mod FileModule) Execute(context *Context) ModuleResult {
 	return ModuleResult{DefaultText: text, Data: value}
 }
 
+// Validate validates the getter settings for this module.
+func (mod FileModule) Validate(validation *Validation) {
+	getter := getters.CustomGetter{Type: mod.Type, As: mod.As, Regex: mod.Regex}
+	if err := getter.Validate(); err != nil {
+		validation.Report(DiagnosticGetter, err.Error())
+	}
+}
+
+// exampleData returns an example of the data this module returns.
+func (mod FileModule) exampleData() interface{} {
+	if mod.Regex != "" || mod.As == getters.AsUndefined || mod.As == getters.AsText {
+		return fileModuleTextResult{}
+	}
+	// The data will be parsed from JSON, YAML, or TOML, so we don't know what
+	// it will look like.
+	return nil
+}
+
 func init() {
 	registerModule(
 		"file",
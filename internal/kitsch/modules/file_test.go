@@ -1,6 +1,7 @@
 package modules
 
 import (
+	gocontext "context"
 	"testing"
 	"testing/fstest"
 
@@ -23,7 +24,7 @@ func TestFileModule(t *testing.T) {
 		template: '{{.Data.foo}}'
 	`))
 
-	result := mod.Execute(context)
+	result := mod.Execute(gocontext.Background(), context)
 	assert.Equal(t, map[string]interface{}{"foo": "bar"}, result.Data)
 	assert.Equal(t, "bar", result.Text)
 }
@@ -41,7 +42,7 @@ func TestFileModuleRegex(t *testing.T) {
 		regex: "^Docker version (.*), build .*$"
 	`))
 
-	result := mod.Execute(context)
+	result := mod.Execute(gocontext.Background(), context)
 	assert.Equal(t, fileModuleTextResult{Text: "20.10.8"}, result.Data)
 	assert.Equal(t, "20.10.8", result.Text)
 }
@@ -58,7 +59,7 @@ func TestFileModuleMissingFile(t *testing.T) {
 		template: '{{.Data.foo}}'
 	`))
 
-	result := mod.Execute(context)
+	result := mod.Execute(gocontext.Background(), context)
 	assert.Equal(t, fileModuleTextResult{Text: ""}, result.Data)
 	assert.Equal(t, "", result.Text)
 }
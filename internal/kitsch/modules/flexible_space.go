@@ -1,11 +1,11 @@
 package modules
 
 import (
+	"context"
 	"strings"
 
-	"github.com/jwalton/go-ansiparser"
+	"github.com/jwalton/kitsch/internal/ansiwidth"
 	"github.com/jwalton/kitsch/internal/kitsch/modules/schemas"
-	"github.com/mattn/go-runewidth"
 	"gopkg.in/yaml.v3"
 )
 
@@ -38,7 +38,7 @@ type FlexibleSpaceModule struct {
 }
 
 // Execute the flexible space module.
-func (mod FlexibleSpaceModule) Execute(context *Context) ModuleResult {
+func (mod FlexibleSpaceModule) Execute(ctx context.Context, context *Context) ModuleResult {
 	return ModuleResult{DefaultText: flexibleSpaceMarker, Data: map[string]interface{}{}}
 }
 
@@ -47,6 +47,7 @@ func init() {
 		"flexible_space",
 		registeredModule{
 			jsonSchema: schemas.FlexibleSpaceModuleJSONSchema,
+			data:       map[string]interface{}{},
 			factory: func(node *yaml.Node) (Module, error) {
 				var module FlexibleSpaceModule = FlexibleSpaceModule{
 					Type: "flexible_space",
@@ -77,7 +78,7 @@ func processFlexibleSpaces(terminalWidth int, renderedPrompt string, replacement
 
 			segmentsTotalLength := 0
 			for _, segment := range segments {
-				segmentsTotalLength += getPrintWidth(segment)
+				segmentsTotalLength += ansiwidth.StringWidth(segment)
 			}
 
 			extraSpace := terminalWidth - segmentsTotalLength
@@ -107,22 +108,3 @@ func processFlexibleSpaces(terminalWidth int, renderedPrompt string, replacement
 
 	return result
 }
-
-func getPrintWidth(str string) int {
-	width := 0
-	tokenizer := ansiparser.NewStringTokenizer(str)
-
-	for tokenizer.Next() {
-		token := tokenizer.Token()
-
-		if token.Type == ansiparser.String {
-			if token.IsASCII {
-				width += len(token.Content)
-			} else {
-				width += runewidth.StringWidth(token.Content)
-			}
-		}
-	}
-
-	return width
-}
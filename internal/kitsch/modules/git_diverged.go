@@ -1,6 +1,7 @@
 package modules
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -44,7 +45,7 @@ type gitDivergedResult struct {
 }
 
 // Execute runs a git module.
-func (mod GitDiverged) Execute(context *Context) ModuleResult {
+func (mod GitDiverged) Execute(ctx context.Context, context *Context) ModuleResult {
 	git := context.Git()
 
 	result := gitDivergedResult{}
@@ -63,7 +64,7 @@ func (mod GitDiverged) Execute(context *Context) ModuleResult {
 	if !head.Detached {
 		upstream = git.GetUpstream(head.Description)
 		if upstream != "" {
-			ahead, behind, _ = git.GetAheadBehind("refs/heads/"+head.Description, "refs/remotes/"+upstream)
+			ahead, behind, _ = git.GetAheadBehind(ctx, "refs/heads/"+head.Description, "refs/remotes/"+upstream)
 		}
 	}
 
@@ -124,6 +125,7 @@ func init() {
 		"git_diverged",
 		registeredModule{
 			jsonSchema: schemas.GitDivergedJSONSchema,
+			data:       gitDivergedResult{},
 			factory: func(node *yaml.Node) (Module, error) {
 				module := GitDiverged{
 					Type:             "git_diverged",
@@ -1,6 +1,8 @@
 package modules
 
 import (
+	"context"
+
 	"github.com/jwalton/kitsch/internal/kitsch/modules/schemas"
 	"gopkg.in/yaml.v3"
 )
@@ -36,7 +38,7 @@ type gitHeadResult struct {
 }
 
 // Execute runs a git module.
-func (mod GitHeadModule) Execute(context *Context) ModuleResult {
+func (mod GitHeadModule) Execute(ctx context.Context, context *Context) ModuleResult {
 	git := context.Git()
 
 	if git == nil {
@@ -77,6 +79,7 @@ func init() {
 		"git_head",
 		registeredModule{
 			jsonSchema: schemas.GitHeadModuleJSONSchema,
+			data:       gitHeadResult{},
 			factory: func(node *yaml.Node) (Module, error) {
 				module := GitHeadModule{
 					Type:            "git_head",
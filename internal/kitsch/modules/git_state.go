@@ -1,6 +1,7 @@
 package modules
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -48,7 +49,7 @@ type gitStateResult struct {
 }
 
 // Execute runs a git module.
-func (mod GitStateModule) Execute(context *Context) ModuleResult {
+func (mod GitStateModule) Execute(ctx context.Context, context *Context) ModuleResult {
 	git := context.Git()
 
 	if git == nil {
@@ -119,6 +120,7 @@ func init() {
 		"git_state",
 		registeredModule{
 			jsonSchema: schemas.GitStateModuleJSONSchema,
+			data:       gitStateResult{},
 			factory: func(node *yaml.Node) (Module, error) {
 				module := GitStateModule{
 					Type:                "git_state",
@@ -1,6 +1,7 @@
 package modules
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -42,14 +43,14 @@ type gitStatusModuleResult struct {
 }
 
 // Execute runs a git module.
-func (mod GitStatusModule) Execute(context *Context) ModuleResult {
+func (mod GitStatusModule) Execute(ctx context.Context, context *Context) ModuleResult {
 	git := context.Git()
 
 	if git == nil {
 		return ModuleResult{DefaultText: "", Data: gitStatusModuleResult{}}
 	}
 
-	stats, _ := git.Stats()
+	stats, _ := git.Stats(ctx)
 	stashCount, err := git.GetStashCount()
 	if err != nil {
 		stashCount = 0
@@ -57,7 +58,7 @@ func (mod GitStatusModule) Execute(context *Context) ModuleResult {
 	}
 
 	return ModuleResult{
-		DefaultText: mod.renderDefault(context, stats, stashCount),
+		DefaultText: mod.renderDefault(ctx, context, stats, stashCount),
 		Data: gitStatusModuleResult{
 			Index:      stats.Index,
 			Unstaged:   stats.Unstaged,
@@ -68,6 +69,7 @@ func (mod GitStatusModule) Execute(context *Context) ModuleResult {
 }
 
 func (mod GitStatusModule) renderDefault(
+	ctx context.Context,
 	context *Context,
 	stats gitutils.GitStats,
 	stashCount int,
@@ -76,9 +78,9 @@ func (mod GitStatusModule) renderDefault(
 	indexTotal := stats.Index.Added + stats.Index.Modified + stats.Index.Deleted
 	unstagedTotal := stats.Unstaged.Added + stats.Unstaged.Modified + stats.Unstaged.Deleted
 
-	indexStyle := context.GetStyle(mod.IndexStyle)
-	unstagedStyle := context.GetStyle(mod.UnstagedStyle)
-	stashStyle := context.GetStyle(mod.StashStyle)
+	indexStyle := context.GetStyle(ctx, mod.IndexStyle)
+	unstagedStyle := context.GetStyle(ctx, mod.UnstagedStyle)
+	stashStyle := context.GetStyle(ctx, mod.StashStyle)
 
 	if (indexTotal) > 0 || stats.Unmerged > 0 {
 		indexPart := mod.renderStats(stats.Index)
@@ -110,11 +112,19 @@ func (mod GitStatusModule) renderStats(stats gitutils.GitFileStats) string {
 	return fmt.Sprintf("+%d ~%d -%d", stats.Added, stats.Modified, stats.Deleted)
 }
 
+// Validate validates the styles for this module.
+func (mod GitStatusModule) Validate(validation *Validation) {
+	validation.Style("indexStyle", mod.IndexStyle)
+	validation.Style("unstagedStyle", mod.UnstagedStyle)
+	validation.Style("stashStyle", mod.StashStyle)
+}
+
 func init() {
 	registerModule(
 		"git_status",
 		registeredModule{
 			jsonSchema: schemas.GitStatusModuleJSONSchema,
+			data:       gitStatusModuleResult{},
 			factory: func(node *yaml.Node) (Module, error) {
 				module := GitStatusModule{
 					Type:          "git_status",
@@ -1,6 +1,7 @@
 package modules
 
 import (
+	gocontext "context"
 	"testing"
 
 	"github.com/MakeNowJust/heredoc"
@@ -19,7 +20,7 @@ func TestGitStatusClean(t *testing.T) {
 		type: git_status
 	`))
 
-	result := mod.Execute(&context)
+	result := mod.Execute(gocontext.Background(), &context)
 	assert.Equal(t, "", result.Text)
 }
 
@@ -50,7 +51,7 @@ func TestGitStatusChanges(t *testing.T) {
 		type: git_status
 	`))
 
-	result := mod.Execute(&context)
+	result := mod.Execute(gocontext.Background(), &context)
 	assert.Equal(t, "+1 ~2 -3 !4 | +5 ~6 -7 (8)", result.Text)
 }
 
@@ -70,6 +71,6 @@ func TestGitStatusUnmerged(t *testing.T) {
 		type: git_status
 	`))
 
-	result := mod.Execute(&context)
+	result := mod.Execute(gocontext.Background(), &context)
 	assert.Equal(t, "+0 ~0 -0 !4", result.Text)
 }
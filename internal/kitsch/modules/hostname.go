@@ -1,6 +1,7 @@
 package modules
 
 import (
+	"context"
 	"strings"
 
 	"github.com/jwalton/kitsch/internal/kitsch/modules/schemas"
@@ -30,7 +31,7 @@ type hostnameResult struct {
 }
 
 // Execute the module.
-func (mod HostnameModule) Execute(context *Context) ModuleResult {
+func (mod HostnameModule) Execute(ctx context.Context, context *Context) ModuleResult {
 	// TODO: Move isSSH to somewhere common.
 	isSSH := context.Environment.HasSomeEnv("SSH_CLIENT", "SSH_CONNECTION", "SSH_TTY")
 	show := isSSH || mod.ShowAlways
@@ -62,6 +63,7 @@ func init() {
 		"hostname",
 		registeredModule{
 			jsonSchema: schemas.HostnameModuleJSONSchema,
+			data:       hostnameResult{},
 			factory: func(node *yaml.Node) (Module, error) {
 				module := HostnameModule{Type: "hostname"}
 				err := node.Decode(&module)
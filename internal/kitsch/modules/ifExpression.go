@@ -0,0 +1,75 @@
+package modules
+
+import (
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/jwalton/kitsch/internal/kitsch/modtemplate"
+)
+
+// ifExpression is the compiled form of the `if` expression from a module's
+// CommonConfig.  A single ifExpression is shared by every copy of a
+// ModuleWrapper, so the expression is only compiled once.
+type ifExpression struct {
+	// source is the expression from the configuration file.
+	source string
+
+	once     sync.Once
+	compiled *template.Template
+	err      error
+}
+
+func newIfExpression(source string) *ifExpression {
+	return &ifExpression{source: source}
+}
+
+// ifExpressionTemplate converts an `if` expression into a template.  The
+// expression may be a bare expression like `ne .Globals.Status 0`, or a
+// template like `{{ ne .Globals.Status 0 }}`.
+func ifExpressionTemplate(source string) string {
+	if strings.Contains(source, "{{") {
+		return source
+	}
+	return "{{ " + source + " }}"
+}
+
+// ifExpressionData returns the data passed to an `if` expression.
+func ifExpressionData(context *Context) TemplateData {
+	return TemplateData{Globals: &context.Globals}
+}
+
+// evaluate returns true if the expression is true for the given context.
+// The expression is considered true unless it produces "", "false", or "0".
+func (expression *ifExpression) evaluate(context *Context) (bool, error) {
+	expression.once.Do(func() {
+		expression.compiled, expression.err = modtemplate.CompileTemplate(
+			context.Styles,
+			context.Environment,
+			"if",
+			ifExpressionTemplate(expression.source),
+		)
+	})
+	if expression.err != nil {
+		return false, expression.err
+	}
+
+	// The compiled template uses the environment from the first context
+	// it was compiled with, so bind it to this context.
+	tmpl, err := modtemplate.Rebind(expression.compiled, context.Styles, context.Environment)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := modtemplate.TemplateToString(tmpl, ifExpressionData(context))
+	if err != nil {
+		return false, err
+	}
+
+	switch strings.TrimSpace(result) {
+	case "", "false", "0":
+		return false, nil
+	default:
+		return true, nil
+	}
+}
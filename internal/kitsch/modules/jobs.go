@@ -1,6 +1,7 @@
 package modules
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/jwalton/kitsch/internal/kitsch/modules/schemas"
@@ -36,7 +37,7 @@ type jobsModuleData struct {
 }
 
 // Execute the module.
-func (mod JobsModule) Execute(context *Context) ModuleResult {
+func (mod JobsModule) Execute(ctx context.Context, context *Context) ModuleResult {
 	jobs := context.Globals.Jobs
 	showSymbol := jobs >= mod.SymbolThreshold
 	showCount := jobs >= mod.CountThreshold
@@ -65,6 +66,7 @@ func init() {
 		"jobs",
 		registeredModule{
 			jsonSchema: schemas.JobsModuleJSONSchema,
+			data:       jobsModuleData{},
 			factory: func(node *yaml.Node) (Module, error) {
 				module := JobsModule{
 					Type:            "jobs",
@@ -1,6 +1,7 @@
 package modules
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 
@@ -89,7 +90,7 @@ func (mod KubernetesModule) loadConfigFile(homedir string) *kubectlConfig {
 }
 
 // Execute the module.
-func (mod KubernetesModule) Execute(context *Context) ModuleResult {
+func (mod KubernetesModule) Execute(ctx context.Context, context *Context) ModuleResult {
 	text := ""
 	data := kubernetesModuleData{}
 
@@ -126,6 +127,7 @@ func init() {
 		"kubernetes",
 		registeredModule{
 			jsonSchema: schemas.KubernetesModuleJSONSchema,
+			data:       kubernetesModuleData{},
 			factory: func(node *yaml.Node) (Module, error) {
 				module := KubernetesModule{
 					Type:       "kubernetes",
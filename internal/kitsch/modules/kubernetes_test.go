@@ -1,6 +1,7 @@
 package modules
 
 import (
+	gocontext "context"
 	"testing"
 
 	"github.com/MakeNowJust/heredoc"
@@ -24,7 +25,7 @@ func TestKubernetes(t *testing.T) {
 	`))
 
 	context := newTestContext("jwalton")
-	result := mod.Execute(context)
+	result := mod.Execute(gocontext.Background(), context)
 
 	expectedData := kubernetesModuleData{
 		OriginalContext: "prod",
@@ -55,7 +56,7 @@ func TestKubernetesWithAlias(t *testing.T) {
 	`))
 
 	context := newTestContext("jwalton")
-	result := mod.Execute(context)
+	result := mod.Execute(gocontext.Background(), context)
 
 	expectedData := kubernetesModuleData{
 		OriginalContext: "prod",
@@ -85,7 +86,7 @@ func TestKubernetesWithNamespace(t *testing.T) {
 	`))
 
 	context := newTestContext("jwalton")
-	result := mod.Execute(context)
+	result := mod.Execute(gocontext.Background(), context)
 
 	expectedData := kubernetesModuleData{
 		OriginalContext: "prod",
@@ -115,7 +116,7 @@ func TestKubernetesWithDefaultNamespace(t *testing.T) {
 	`))
 
 	context := newTestContext("jwalton")
-	result := mod.Execute(context)
+	result := mod.Execute(gocontext.Background(), context)
 
 	expectedData := kubernetesModuleData{
 		OriginalContext: "prod",
@@ -139,7 +140,7 @@ func TestKubernetesWithMissingContext(t *testing.T) {
 	`))
 
 	context := newTestContext("jwalton")
-	result := mod.Execute(context)
+	result := mod.Execute(gocontext.Background(), context)
 
 	expectedData := kubernetesModuleData{
 		OriginalContext: "prod",
@@ -159,7 +160,7 @@ func TestKubernetesWithCorruptConfigFile(t *testing.T) {
 	mod.configFileContents = []byte(`unexpected`)
 
 	context := newTestContext("jwalton")
-	result := mod.Execute(context)
+	result := mod.Execute(gocontext.Background(), context)
 
 	expectedData := kubernetesModuleData{
 		OriginalContext: "",
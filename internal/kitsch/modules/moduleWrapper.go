@@ -1,19 +1,17 @@
 package modules
 
 import (
+	gocontext "context"
 	"fmt"
 	"text/template"
 	"time"
 
-	"github.com/jwalton/kitsch/internal/kitsch/log"
 	"github.com/jwalton/kitsch/internal/kitsch/modtemplate"
 	"github.com/jwalton/kitsch/internal/kitsch/styling"
 	"github.com/jwalton/kitsch/internal/perf"
 	"gopkg.in/yaml.v3"
 )
 
-// TOOD: Store filename in ModuleWrapper?
-
 // ModuleWrapper represents an item within a list of modules.
 type ModuleWrapper struct {
 	// config is common configuration for this module.
@@ -24,9 +22,16 @@ type ModuleWrapper struct {
 	Line int
 	// Column is the column number of the module in the configuration file.
 	Column int
+	// File is the configuration file the module was read from, if it was
+	// inherited from another configuration file via `extends`, or "" if the
+	// module is from the configuration file being loaded.
+	File string
 	// YamlNode is the YAML node that this module was read from, or nil if this module
 	// was not loaded from YAML.
 	YamlNode *yaml.Node
+	// ifExpression is the compiled `if` expression from the config, or nil
+	// if there is none.
+	ifExpression *ifExpression
 }
 
 // ModuleWrapperResult represents the output of a ModuleWrapper.
@@ -71,6 +76,9 @@ func (wrapper *ModuleWrapper) UnmarshalYAML(node *yaml.Node) error {
 		return err
 	}
 	wrapper.config = config
+	if config.If != "" {
+		wrapper.ifExpression = newIfExpression(config.If)
+	}
 
 	// Load the actual module from the factory.
 	mod, ok := registeredModules[config.Type]
@@ -100,45 +108,157 @@ func (wrapper ModuleWrapper) String() string {
 }
 
 // Execute executes this module.  This will run the underlying Module, and then
-// apply styling and the template from the CommonConfig.
-func (wrapper ModuleWrapper) Execute(context *Context) ModuleWrapperResult {
-	if !wrapper.config.Conditions.IsEmpty() && !wrapper.config.Conditions.Matches(context.Directory) {
+// apply styling and the template from the CommonConfig.  If the module times
+// out, or if ctx is cancelled, the module's context will be cancelled and
+// an empty result will be returned.
+func (wrapper ModuleWrapper) Execute(ctx gocontext.Context, context *Context) ModuleWrapperResult {
+	explanation := getExplanation(ctx)
+	explanation.start(wrapper)
+
+	if !wrapper.conditionsMatch(ctx, context) {
 		// If the item has conditions, and they don't match, return an empty result.
+		if explanation != nil {
+			explanation.ConditionsMatched = false
+		}
 		return ModuleWrapperResult{}
 	}
 
+	if wrapper.config.Async && context.Async {
+		result := wrapper.getAsyncResult(withModuleDescription(ctx, wrapper), context)
+		if explanation != nil {
+			explanation.Async = true
+			explanation.Style = wrapper.config.Style
+			explanation.Text = result.Text
+		}
+		return result
+	}
+
 	// If the module has no timeout, use the default timeout.
 	timeout := time.Duration(wrapper.config.Timeout) * time.Millisecond
 	if timeout == 0 && wrapper.config.Type != "block" {
 		timeout = context.DefaultTimeout
 	}
 
+	return wrapper.execute(ctx, context, timeout)
+}
+
+// conditionsMatch returns true if the module's conditions and `if` expression
+// are both met.  If the `if` expression can't be evaluated, a diagnostic is
+// recorded and the module is skipped.
+func (wrapper ModuleWrapper) conditionsMatch(ctx gocontext.Context, context *Context) bool {
+	if !wrapper.config.Conditions.IsEmpty() && !wrapper.config.Conditions.Matches(context) {
+		return false
+	}
+
+	if wrapper.config.If == "" {
+		return true
+	}
+
+	expression := wrapper.ifExpression
+	if expression == nil {
+		// Module wasn't loaded from YAML, so we don't have a cached expression.
+		expression = newIfExpression(wrapper.config.If)
+	}
+
+	matched, err := expression.evaluate(context)
+	if err != nil {
+		context.AddDiagnostic(withModuleDescription(ctx, wrapper), DiagnosticTemplate, fmt.Sprintf("Error evaluating if: %v", err))
+		return false
+	}
+	return matched
+}
+
+// execute runs the underlying module with the given timeout.  If timeout is 0,
+// the module will be allowed to run for as long as it likes.
+func (wrapper ModuleWrapper) execute(
+	ctx gocontext.Context,
+	context *Context,
+	timeout time.Duration,
+) ModuleWrapperResult {
 	start := time.Now()
+	explanation := getExplanation(ctx)
+
+	var moduleCtx gocontext.Context
+	var cancel gocontext.CancelFunc
+	if timeout <= 0 {
+		moduleCtx, cancel = gocontext.WithCancel(ctx)
+	} else {
+		moduleCtx, cancel = gocontext.WithTimeout(ctx, timeout)
+	}
+	// Make sure any processes the module started are killed once we're done
+	// waiting for it.
+	defer cancel()
+	moduleCtx = withModuleDescription(moduleCtx, wrapper)
+
+	// If we're re-rendering the prompt, reuse the result from the first time
+	// we ran this module.
+	if memoized, ok := context.getMemoizedResult(wrapper.Module); ok {
+		result := ModuleWrapperResult{}
+		if !memoized.timedOut {
+			result = processModuleResult(moduleCtx, context, wrapper, memoized.result)
+		}
+		// Report how long the module took the first time, since that's how
+		// long it really takes.
+		result.Duration = memoized.duration
+		if memoized.timedOut && explanation != nil {
+			explanation.TimedOut = true
+			explanation.Duration = result.Duration
+		} else {
+			explanation.finish(wrapper, memoized.result, result)
+		}
+		return result
+	}
+
+	type executeResult struct {
+		moduleResult ModuleResult
+		result       ModuleWrapperResult
+	}
 
 	// Run the module in a goroutine, so we can time it out.
-	ch := make(chan ModuleWrapperResult, 1)
+	ch := make(chan executeResult, 1)
 	go func() {
-		moduleResult := wrapper.Module.Execute(context)
-		ch <- processModuleResult(context, wrapper, moduleResult)
+		moduleResult := wrapper.Module.Execute(moduleCtx, context)
+		ch <- executeResult{
+			moduleResult: moduleResult,
+			result:       processModuleResult(moduleCtx, context, wrapper, moduleResult),
+		}
 	}()
 
+	// If the module doesn't execute in time, return an empty result.
 	var result ModuleWrapperResult
-	if timeout <= 0 {
-		result = <-ch
-	} else {
-		// If the module doesn't execute in time, return an empty result.
-		select {
-		case result = <-ch:
-		case <-time.After(timeout):
+	var moduleResult ModuleResult
+	timedOut := false
+	select {
+	case executed := <-ch:
+		moduleResult = executed.moduleResult
+		result = executed.result
+	case <-moduleCtx.Done():
+		timedOut = true
+		if ctx.Err() == nil {
 			// Module timed out!
-			// TODO: Record a list of which modules timed out in the context,
-			// so we can display a list of them in a warning.
-			log.Warn("Module ", wrapper.String(), " timed out after ", timeout)
-			result = ModuleWrapperResult{}
+			context.AddDiagnostic(moduleCtx, DiagnosticTimeout, fmt.Sprintf("Timed out after %v", timeout))
 		}
+		result = ModuleWrapperResult{}
 	}
 
 	result.Duration = time.Since(start)
+	if duration, ok := context.firstRenderDuration(wrapper.Module); ok {
+		// Modules like blocks are re-executed when re-rendering the prompt,
+		// but should still report how long they took the first time.
+		result.Duration = duration
+	}
+	context.memoize(wrapper.Module, memoizedResult{
+		result:   moduleResult,
+		timedOut: timedOut,
+		duration: result.Duration,
+	})
+
+	if timedOut && explanation != nil {
+		explanation.TimedOut = true
+		explanation.Duration = result.Duration
+	} else {
+		explanation.finish(wrapper, moduleResult, result)
+	}
 
 	return result
 }
@@ -160,6 +280,7 @@ func compileModuleTemplate(context *Context, tmpl string) (*template.Template, e
 // executeModule is called to execute a module.  This handles "common" stuff that
 // all modules do, like calling templates.
 func processModuleResult(
+	ctx gocontext.Context,
 	context *Context,
 	moduleWrapper ModuleWrapper,
 	moduleResult ModuleResult,
@@ -168,7 +289,7 @@ func processModuleResult(
 	if moduleResult.StyleOverride != "" {
 		styleStr = moduleResult.StyleOverride
 	}
-	style := context.GetStyle(styleStr)
+	style := context.GetStyle(ctx, styleStr)
 
 	text := moduleResult.DefaultText
 	startStyle := moduleResult.StartStyle
@@ -177,7 +298,7 @@ func processModuleResult(
 	if moduleWrapper.config.Template != "" {
 		tmpl, err := compileModuleTemplate(context, moduleWrapper.config.Template)
 		if err != nil {
-			log.Warn(fmt.Sprintf("Error compiling template in %s: %v", moduleWrapper.String(), err))
+			context.AddDiagnostic(ctx, DiagnosticTemplate, fmt.Sprintf("Error compiling template: %v", err))
 		} else {
 			templateData := TemplateData{
 				Data:    moduleResult.Data,
@@ -187,19 +308,18 @@ func processModuleResult(
 
 			text, err = modtemplate.TemplateToString(tmpl, templateData)
 			if err != nil {
-				log.Warn(fmt.Sprintf(
-					"Error executing template in %s:\n%s\n%v",
-					moduleWrapper.String(),
-					moduleWrapper.config.Template,
-					err,
-				))
+				context.AddDiagnostic(ctx, DiagnosticTemplate, fmt.Sprintf("Error executing template: %v", err))
 				text = moduleResult.DefaultText
 			}
 		}
 	}
 
 	if style != nil && text != "" {
-		text, startStyle, endStyle = style.ApplyGetColors(text)
+		if moduleResult.ContinuousGradient {
+			text, startStyle, endStyle = style.ApplyOverGetColors(text)
+		} else {
+			text, startStyle, endStyle = style.ApplyGetColors(text)
+		}
 	}
 
 	return ModuleWrapperResult{
@@ -212,31 +332,27 @@ func processModuleResult(
 }
 
 // RenderPrompt renders the top-level module in a prompt.
+//
+// If any problems are found while rendering the prompt, and the prompt has a
+// `diagnostics` module or a template which reads `Globals.Diagnostics`, the
+// prompt will be rendered a second time with `Globals.Diagnostics` filled in,
+// so modules and templates which display diagnostics will be accurate.  The
+// second render reuses the results of each module from the first render, so
+// only templates and styles are re-run.
 func RenderPrompt(context *Context, root ModuleWrapper) (ModuleWrapperResult, string) {
-	result := root.Execute(context)
-	return result, processFlexibleSpaces(context.Globals.TerminalWidth, result.Text, context.FlexibleSpaceReplacement)
+	return renderPrompt(gocontext.Background(), context, root)
 }
 
-// func testTemplate(context *Context, prefix string, template string, dataMap map[string]interface{}) {
-// 	if template == "" {
-// 		return
-// 	}
-
-// 	tmpl, err := compileModuleTemplate(context, template)
-// 	if err != nil {
-// 		log.Warn(fmt.Sprintf("%s: Error compiling template: %v", prefix, err))
-// 	} else {
-// 		for description, data := range dataMap {
-// 			templateData := TemplateData{
-// 				Data:    data,
-// 				Globals: &context.Globals,
-// 				Text:    "",
-// 			}
-
-// 			_, err = modtemplate.TemplateToString(tmpl, templateData)
-// 			if err != nil {
-// 				log.Warn(fmt.Sprintf("%s: Error executing template with %s: %v", prefix, description, err))
-// 			}
-// 		}
-// 	}
-// }
+func renderPrompt(ctx gocontext.Context, context *Context, root ModuleWrapper) (ModuleWrapperResult, string) {
+	result := root.Execute(ctx, context)
+
+	diagnostics := context.Diagnostics()
+	if len(diagnostics) > len(context.Globals.Diagnostics) && consumesDiagnostics(root) {
+		context.Globals.Diagnostics = diagnostics
+		context.replaying = true
+		result = root.Execute(ctx, context)
+		context.replaying = false
+	}
+
+	return result, processFlexibleSpaces(context.Globals.TerminalWidth, result.Text, context.FlexibleSpaceReplacement)
+}
@@ -1,12 +1,14 @@
 package modules
 
 import (
+	gocontext "context"
 	"testing"
 	"testing/fstest"
 	"time"
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/jwalton/kitsch/internal/fileutils"
+	"github.com/jwalton/kitsch/internal/kitsch/env"
 	"github.com/jwalton/kitsch/internal/kitsch/styling"
 	"github.com/stretchr/testify/assert"
 )
@@ -17,7 +19,7 @@ func TestModuleWrapperExecute(t *testing.T) {
 		text: "test"
 	`))
 
-	result := module.Execute(newTestContext("jwalton"))
+	result := module.Execute(gocontext.Background(), newTestContext("jwalton"))
 
 	assert.Equal(t,
 		ModuleWrapperResult{
@@ -39,7 +41,7 @@ func TestExecuteModuleWrapperWithTemplate(t *testing.T) {
 		template: "--{{.Data.Text}}--"
 	`))
 
-	result := module.Execute(newTestContext("jwalton"))
+	result := module.Execute(gocontext.Background(), newTestContext("jwalton"))
 
 	assert.Equal(t,
 		ModuleWrapperResult{
@@ -68,14 +70,84 @@ func TestExecuteModuleWithConditions(t *testing.T) {
 			Data: []byte("blahblahblah"),
 		},
 	})
-	result := mod.Execute(context)
+	result := mod.Execute(gocontext.Background(), context)
 	assert.Equal(t, "Hello World", result.Text)
 
 	context.Directory = fileutils.NewDirectoryTestFS("/foo/bar", fstest.MapFS{})
-	result2 := mod.Execute(context)
+	result2 := mod.Execute(gocontext.Background(), context)
 	assert.Equal(t, "", result2.Text)
 }
 
+func TestExecuteModuleWithIf(t *testing.T) {
+	mod := moduleWrapperFromYAML(heredoc.Doc(`
+		type: text
+		if: 'or (ne .Globals.Status 0) (eq (env "FORCE") "yes")'
+		text: Hello World
+	`))
+
+	context := newTestContext("jwalton")
+	result := mod.Execute(gocontext.Background(), context)
+	assert.Equal(t, "", result.Text)
+
+	context.Globals.Status = 1
+	result = mod.Execute(gocontext.Background(), context)
+	assert.Equal(t, "Hello World", result.Text)
+
+	// Should use the environment from the current context, even though the
+	// expression has already been compiled.
+	context = newTestContext("jwalton")
+	context.Environment.(*env.DummyEnv).Env["FORCE"] = "yes"
+	result = mod.Execute(gocontext.Background(), context)
+	assert.Equal(t, "Hello World", result.Text)
+	assert.Empty(t, context.Diagnostics())
+}
+
+func TestExecuteModuleWithIfTemplate(t *testing.T) {
+	mod := moduleWrapperFromYAML(heredoc.Doc(`
+		type: text
+		if: '{{ if gt .Globals.Jobs 0 }}true{{ end }}'
+		text: Hello World
+	`))
+
+	context := newTestContext("jwalton")
+	assert.Equal(t, "", mod.Execute(gocontext.Background(), context).Text)
+
+	context.Globals.Jobs = 2
+	assert.Equal(t, "Hello World", mod.Execute(gocontext.Background(), context).Text)
+}
+
+func TestExecuteModuleWithInvalidIf(t *testing.T) {
+	mod := moduleWrapperFromYAML(heredoc.Doc(`
+		type: text
+		if: 'ne .Globals.Status'
+		text: Hello World
+	`))
+
+	context := newTestContext("jwalton")
+	result := mod.Execute(gocontext.Background(), context)
+	assert.Equal(t, "", result.Text)
+
+	diagnostics := context.Diagnostics()
+	if assert.Len(t, diagnostics, 1) {
+		assert.Equal(t, DiagnosticTemplate, diagnostics[0].Kind)
+		assert.Contains(t, diagnostics[0].Message, "Error evaluating if:")
+	}
+}
+
+func TestIfExpressionCompiledOnce(t *testing.T) {
+	mod := moduleWrapperFromYAML(heredoc.Doc(`
+		type: text
+		if: 'eq .Globals.Status 0'
+		text: Hello World
+	`))
+
+	// Copies of the wrapper should share the compiled expression.
+	copy := mod
+	copy.Execute(gocontext.Background(), newTestContext("jwalton"))
+	assert.NotNil(t, mod.ifExpression.compiled)
+	assert.Same(t, mod.ifExpression, copy.ifExpression)
+}
+
 type sleepModule struct {
 	// Type is the type of this module.
 	Type string
@@ -86,7 +158,7 @@ type sleepModule struct {
 }
 
 // Execute the module.
-func (mod sleepModule) Execute(context *Context) ModuleResult {
+func (mod sleepModule) Execute(ctx gocontext.Context, context *Context) ModuleResult {
 	time.Sleep(time.Duration(mod.Duration) * time.Millisecond)
 
 	return ModuleResult{
@@ -109,7 +181,7 @@ func TestExecuteModuleWithTimeout(t *testing.T) {
 	}
 
 	context := newTestContext("jwalton")
-	result := mod.Execute(context)
+	result := mod.Execute(gocontext.Background(), context)
 
 	// Should have no output, because it should have timed out.
 	assert.Equal(t, "", result.Text)
@@ -18,6 +18,8 @@
 package modules
 
 import (
+	"context"
+
 	"github.com/jwalton/kitsch/internal/kitsch/styling"
 	"github.com/jwalton/kitsch/internal/perf"
 )
@@ -32,6 +34,10 @@ type ModuleResult struct {
 	Data interface{}
 	// StyleOverride can be used to override the default style for the module.
 	StyleOverride string
+	// ContinuousGradient, if true, causes any gradient in the module's style to
+	// replace the colors already present in the text, instead of only coloring
+	// text that has no color of its own.
+	ContinuousGradient bool
 	// Performance is an optional collection of performance data for individual
 	// steps within the module.
 	Performance *perf.Performance
@@ -47,8 +53,10 @@ type ModuleResult struct {
 
 // Module represents a module that generates some output to show in the prompt.
 type Module interface {
-	// Execute will execute this module and return a ModuleResult.
-	Execute(context *Context) ModuleResult
+	// Execute will execute this module and return a ModuleResult.  ctx will
+	// be cancelled if the module times out - modules should pass it along to
+	// anything that runs an external process.
+	Execute(ctx context.Context, context *Context) ModuleResult
 }
 
 // defaultString returns value if it is non-empty, or def otherwise.
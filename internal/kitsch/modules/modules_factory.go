@@ -12,6 +12,10 @@ import (
 type registeredModule struct {
 	factory    func(node *yaml.Node) (Module, error)
 	jsonSchema string
+	// data is an example of the `Data` this module returns from Execute, used
+	// to validate templates.  Modules where the type of the data depends on
+	// their configuration should leave this nil and implement exampleDataModule.
+	data interface{}
 }
 
 // registeredModules lists information about each type of module.
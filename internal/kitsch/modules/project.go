@@ -1,6 +1,9 @@
 package modules
 
 import (
+	"context"
+	"sort"
+
 	"github.com/jwalton/kitsch/internal/kitsch/modules/schemas"
 	"github.com/jwalton/kitsch/internal/kitsch/projects"
 	"gopkg.in/yaml.v3"
@@ -56,8 +59,8 @@ type ProjectModule struct {
 }
 
 // Execute the module.
-func (mod ProjectModule) Execute(context *Context) ModuleResult {
-	projectInfo := projects.ResolveProjectType(context.ProjectTypes, context)
+func (mod ProjectModule) Execute(ctx context.Context, context *Context) ModuleResult {
+	projectInfo := projects.ResolveProjectType(context.ProjectTypes, context, context.GetterContext(ctx))
 
 	if projectInfo == nil {
 		return ModuleResult{}
@@ -75,7 +78,7 @@ func (mod ProjectModule) Execute(context *Context) ModuleResult {
 	if projectStyleString == "" {
 		projectStyleString = projectInfo.Style
 	}
-	projectStyle := context.GetStyle(projectStyleString)
+	projectStyle := context.GetStyle(ctx, projectStyleString)
 
 	data := projectModuleData{
 		projectInfo:          *projectInfo,
@@ -94,11 +97,27 @@ func (mod ProjectModule) Execute(context *Context) ModuleResult {
 	return ModuleResult{DefaultText: text, Data: data}
 }
 
+// Validate validates the styles for this module.
+func (mod ProjectModule) Validate(validation *Validation) {
+	validation.Style("defaultProjectStyle", mod.DefaultProjectStyle)
+
+	names := make([]string, 0, len(mod.Projects))
+	for name := range mod.Projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		validation.Style("style for project "+name, mod.Projects[name].Style)
+	}
+}
+
 func init() {
 	registerModule(
 		"project",
 		registeredModule{
 			jsonSchema: schemas.ProjectModuleJSONSchema,
+			data:       projectModuleData{},
 			factory: func(node *yaml.Node) (Module, error) {
 				module := ProjectModule{Type: "project"}
 				err := node.Decode(&module)
@@ -1,6 +1,7 @@
 package modules
 
 import (
+	gocontext "context"
 	"testing"
 	"testing/fstest"
 
@@ -45,7 +46,7 @@ func TestProject(t *testing.T) {
 		template: "{{ .Data.ToolVersion }} / {{ .Data.PackageManagerVersion }} / {{ .Data.PackageVersion }}"
 	`))
 
-	result := mod.Execute(context)
+	result := mod.Execute(gocontext.Background(), context)
 
 	assert.Equal(t, "v1.0.0 / v2.0.0 / v3.0.0", result.Text)
 }
@@ -1,6 +1,8 @@
 package modules
 
 import (
+	"context"
+
 	"github.com/jwalton/kitsch/internal/kitsch/modules/schemas"
 	"gopkg.in/yaml.v3"
 )
@@ -40,7 +42,7 @@ type promptModuleData struct {
 }
 
 // Execute the prompt module.
-func (mod PromptModule) Execute(context *Context) ModuleResult {
+func (mod PromptModule) Execute(ctx context.Context, context *Context) ModuleResult {
 	var text string
 	var style string
 
@@ -71,11 +73,19 @@ func (mod PromptModule) Execute(context *Context) ModuleResult {
 	}
 }
 
+// Validate validates the styles for this module.
+func (mod PromptModule) Validate(validation *Validation) {
+	validation.Style("rootStyle", mod.RootStyle)
+	validation.Style("vicmdStyle", mod.VicmdStyle)
+	validation.Style("errorStyle", mod.ErrorStyle)
+}
+
 func init() {
 	registerModule(
 		"prompt",
 		registeredModule{
 			jsonSchema: schemas.PromptModuleJSONSchema,
+			data:       promptModuleData{},
 			factory: func(node *yaml.Node) (Module, error) {
 				module := PromptModule{
 					Type:        "prompt",
@@ -1,6 +1,7 @@
 package modules
 
 import (
+	gocontext "context"
 	"testing"
 
 	"github.com/MakeNowJust/heredoc"
@@ -12,7 +13,7 @@ func TestPrompt(t *testing.T) {
 
 	context := newTestContext("jwalton")
 
-	result := mod.Execute(context)
+	result := mod.Execute(gocontext.Background(), context)
 
 	assert.Equal(t, ModuleWrapperResult{
 		Text: "$ ",
@@ -31,7 +32,7 @@ func TestRootPrompt(t *testing.T) {
 	context := newTestContext("jwalton")
 	context.Globals.IsRoot = true
 
-	result := mod.Execute(context)
+	result := mod.Execute(gocontext.Background(), context)
 
 	assert.Equal(t, ModuleWrapperResult{
 		Text: "# ",
@@ -53,10 +54,10 @@ func TestStyle(t *testing.T) {
 
 	context := newTestContext("jwalton")
 
-	result := mod.Execute(context)
+	result := mod.Execute(gocontext.Background(), context)
 
 	context.Globals.IsRoot = true
-	rootResult := mod.Execute(context)
+	rootResult := mod.Execute(gocontext.Background(), context)
 
 	assert.Equal(t, "blue", result.StartStyle.FG)
 	assert.Equal(t, "blue", result.EndStyle.FG)
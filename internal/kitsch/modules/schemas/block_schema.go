@@ -8,7 +8,8 @@ var BlockModuleJSONSchema = `{
   "properties": {
     "type": {"type": "string", "description": "Type is the type of this module.", "enum": ["block"]},
     "modules": {"$ref": "#/definitions/ModulesList"},
-    "join": {"type": "string", "description": "Join is a template to use to join together modules.  Defaults to \" \". This will be executed with template data of the form ` + "`" + `{ PrevColors, NextColors, Index }` + "`" + `, where PrevColors is the FG and BG color of last character of the previous module, NextColors is the FG and BG color of the first character of the next module, and Index is the index of the current module in the modules array."}
+    "join": {"type": "string", "description": "Join is a template to use to join together modules.  Defaults to \" \". This will be executed with template data of the form ` + "`" + `{ PrevColors, NextColors, Index }` + "`" + `, where PrevColors is the FG and BG color of last character of the previous module, NextColors is the FG and BG color of the first character of the next module, and Index is the index of the current module in the modules array."},
+    "continuousGradient": {"type": "boolean", "description": "ContinuousGradient, if true, will cause a gradient in this block's style to be applied across the joined text of all child modules, replacing any colors set by the children, so the gradient flows continuously from the first child to the last instead of being interrupted by each child."}
   },
   "required": ["type", "modules"]}`
 
@@ -11,6 +11,9 @@ var CommonConfigJSONSchema = `{
     "style": {"type": "string", "description": "Style is the style to apply to this module."},
     "template": {"type": "string", "description": "Template is a golang template to use to render the output of this module."},
     "conditions": {"$ref": "#/definitions/Conditions"},
-    "timeout": {"type": "integer", "description": "Timeout is the maximum amount of time, in milliseconds, to wait for this module to execute.  If not specified, the default timeout for most modules will be 200ms, but for block modules it will be infinite."}
+    "if": {"type": "string", "description": "If is a golang template expression, like ` + "`ne .Globals.Status 0`" + `.  If the expression is false, the module will not be executed."},
+    "timeout": {"type": "integer", "description": "Timeout is the maximum amount of time, in milliseconds, to wait for this module to execute.  If not specified, the default timeout for most modules will be 200ms, but for block modules it will be infinite."},
+    "async": {"type": "boolean", "description": "Async, if true, will cause this module to be rendered in the background. The prompt will show the last value this module produced in the current directory (or AsyncPlaceholder if there is no such value), and will be redrawn when the module finishes.  Only zsh and powershell support async modules - in other shells async modules are rendered normally."},
+    "asyncPlaceholder": {"type": "string", "description": "AsyncPlaceholder is the text to show for an async module while it is being rendered, if there is no previous value to show."}
   }}`
 
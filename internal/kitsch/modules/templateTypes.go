@@ -0,0 +1,350 @@
+package modules
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// moduleResultsType is the type of `blockModuleResult.Modules`.
+var moduleResultsType = reflect.TypeOf(map[string]ModuleWrapperResult{})
+
+// typeCheckTemplate statically checks every field access in a template against
+// the type of `data`, including field accesses in branches of the template
+// that wouldn't run with `data`.  Returns a description of each problem found.
+//
+// Values in `data` are used to find the type of interface fields - for example
+// if `data` is a TemplateData, then `.Data` will be checked against whatever
+// type is stored in `data.Data`.  If an interface is nil, then anything
+// accessed through it is assumed to be OK.
+func typeCheckTemplate(tree *parse.Tree, data interface{}) []string {
+	checker := templateTypeChecker{tree: tree}
+	root := reflect.ValueOf(data)
+	checker.variables = []templateVariable{{name: "$", value: root}}
+	checker.walkList(tree.Root, root)
+	return checker.problems
+}
+
+type templateVariable struct {
+	name  string
+	value reflect.Value
+}
+
+type templateTypeChecker struct {
+	tree     *parse.Tree
+	problems []string
+	// variables is a stack of variables in scope.
+	variables []templateVariable
+}
+
+func (checker *templateTypeChecker) walkList(list *parse.ListNode, dot reflect.Value) {
+	if list == nil {
+		return
+	}
+	for _, node := range list.Nodes {
+		checker.walk(node, dot)
+	}
+}
+
+func (checker *templateTypeChecker) walk(node parse.Node, dot reflect.Value) {
+	mark := len(checker.variables)
+
+	switch node := node.(type) {
+	case *parse.ActionNode:
+		// Variables declared in an action stay in scope until the end of the
+		// enclosing block.
+		checker.pipe(node.Pipe, dot, true)
+		return
+
+	case *parse.IfNode:
+		checker.pipe(node.Pipe, dot, true)
+		checker.walkList(node.List, dot)
+		checker.walkList(node.ElseList, dot)
+
+	case *parse.WithNode:
+		value := checker.pipe(node.Pipe, dot, true)
+		checker.walkList(node.List, value)
+		checker.walkList(node.ElseList, dot)
+
+	case *parse.RangeNode:
+		value := checker.pipe(node.Pipe, dot, false)
+		key, element := rangeTypes(value)
+		switch len(node.Pipe.Decl) {
+		case 1:
+			checker.declare(node.Pipe.Decl[0], element)
+		case 2:
+			checker.declare(node.Pipe.Decl[0], key)
+			checker.declare(node.Pipe.Decl[1], element)
+		}
+		checker.walkList(node.List, element)
+		checker.walkList(node.ElseList, dot)
+
+	case *parse.TemplateNode:
+		checker.pipe(node.Pipe, dot, false)
+	}
+
+	checker.variables = checker.variables[:mark]
+}
+
+// pipe checks a pipeline, and returns the value the pipeline will produce,
+// or an invalid value if this is not known.  If `declare` is true, any
+// variables declared by the pipeline will be added to the current scope.
+func (checker *templateTypeChecker) pipe(pipe *parse.PipeNode, dot reflect.Value, declare bool) reflect.Value {
+	if pipe == nil {
+		return reflect.Value{}
+	}
+
+	var value reflect.Value
+	for _, command := range pipe.Cmds {
+		value = checker.command(command, dot)
+	}
+
+	if declare {
+		for _, variable := range pipe.Decl {
+			checker.declare(variable, value)
+		}
+	}
+
+	return value
+}
+
+func (checker *templateTypeChecker) declare(variable *parse.VariableNode, value reflect.Value) {
+	checker.variables = append(checker.variables, templateVariable{name: variable.Ident[0], value: value})
+}
+
+// command checks every argument in a command, and returns the value the
+// command will produce if the command is a single field or variable.
+func (checker *templateTypeChecker) command(command *parse.CommandNode, dot reflect.Value) reflect.Value {
+	var value reflect.Value
+	for _, arg := range command.Args {
+		value = checker.arg(arg, dot)
+	}
+
+	if len(command.Args) != 1 {
+		// A function call.
+		return reflect.Value{}
+	}
+	return value
+}
+
+func (checker *templateTypeChecker) arg(node parse.Node, dot reflect.Value) reflect.Value {
+	switch node := node.(type) {
+	case *parse.DotNode:
+		return dot
+	case *parse.FieldNode:
+		return checker.fields(node, dot, node.Ident)
+	case *parse.VariableNode:
+		return checker.fields(node, checker.variable(node.Ident[0]), node.Ident[1:])
+	case *parse.ChainNode:
+		return checker.fields(node, checker.arg(node.Node, dot), node.Field)
+	case *parse.PipeNode:
+		return checker.pipe(node, dot, false)
+	default:
+		return reflect.Value{}
+	}
+}
+
+func (checker *templateTypeChecker) variable(name string) reflect.Value {
+	for index := len(checker.variables) - 1; index >= 0; index-- {
+		if checker.variables[index].name == name {
+			return checker.variables[index].value
+		}
+	}
+	return reflect.Value{}
+}
+
+// fields follows a chain of field names (e.g. ".Data.Modules.git") starting
+// at `value`, and reports a problem if any field in the chain doesn't exist.
+func (checker *templateTypeChecker) fields(node parse.Node, value reflect.Value, names []string) reflect.Value {
+	for _, name := range names {
+		value = indirect(value)
+		if !value.IsValid() {
+			return value
+		}
+
+		if method, ok := findMethod(value.Type(), name); ok {
+			if method.Type.NumOut() == 0 {
+				return reflect.Value{}
+			}
+			value = reflect.Zero(method.Type.Out(0))
+			continue
+		}
+
+		switch value.Kind() {
+		case reflect.Struct:
+			field, ok := value.Type().FieldByName(name)
+			if !ok || field.PkgPath != "" {
+				checker.report(node, fmt.Sprintf(
+					"%s has no field %q%s",
+					value.Type(), name, suggestion(name, fieldNames(value.Type())),
+				))
+				return reflect.Value{}
+			}
+			value = value.FieldByIndex(field.Index)
+
+		case reflect.Map:
+			if value.Type().Key().Kind() != reflect.String {
+				return reflect.Value{}
+			}
+
+			element := value.MapIndex(reflect.ValueOf(name).Convert(value.Type().Key()))
+			if !element.IsValid() {
+				if value.Type() == moduleResultsType {
+					checker.report(node, fmt.Sprintf(
+						"no module with ID %q in block%s",
+						name, suggestion(name, mapKeys(value)),
+					))
+					return reflect.Value{}
+				}
+				element = reflect.Zero(value.Type().Elem())
+			}
+			value = element
+
+		default:
+			checker.report(node, fmt.Sprintf("can't evaluate field %s in type %s", name, value.Type()))
+			return reflect.Value{}
+		}
+	}
+
+	return value
+}
+
+func (checker *templateTypeChecker) report(node parse.Node, message string) {
+	location, context := checker.tree.ErrorContext(node)
+	checker.problems = append(checker.problems, fmt.Sprintf("%s: <%s>: %s", location, context, message))
+}
+
+// indirect follows pointers and interfaces until it finds a concrete value.
+// Returns an invalid value if the value is a nil interface.
+func indirect(value reflect.Value) reflect.Value {
+	for value.IsValid() {
+		switch value.Kind() {
+		case reflect.Ptr:
+			if value.IsNil() {
+				value = reflect.Zero(value.Type().Elem())
+			} else {
+				value = value.Elem()
+			}
+		case reflect.Interface:
+			if value.IsNil() {
+				return reflect.Value{}
+			}
+			value = value.Elem()
+		default:
+			return value
+		}
+	}
+	return value
+}
+
+// rangeTypes returns example values for the key and element when ranging over
+// the given value.
+func rangeTypes(value reflect.Value) (reflect.Value, reflect.Value) {
+	value = indirect(value)
+	if !value.IsValid() {
+		return value, value
+	}
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		return reflect.Zero(reflect.TypeOf(0)), reflect.Zero(value.Type().Elem())
+	case reflect.Map:
+		return reflect.Zero(value.Type().Key()), reflect.Zero(value.Type().Elem())
+	default:
+		return reflect.Value{}, reflect.Value{}
+	}
+}
+
+// findMethod finds an exported method on the given type, or on a pointer to
+// the given type.
+func findMethod(valueType reflect.Type, name string) (reflect.Method, bool) {
+	if method, ok := valueType.MethodByName(name); ok {
+		return method, true
+	}
+	if valueType.Kind() != reflect.Interface {
+		return reflect.PtrTo(valueType).MethodByName(name)
+	}
+	return reflect.Method{}, false
+}
+
+// fieldNames returns the names of all the exported fields and methods that can
+// be used from a template on the given struct type.
+func fieldNames(structType reflect.Type) []string {
+	names := []string{}
+	for index := 0; index < structType.NumField(); index++ {
+		if field := structType.Field(index); field.PkgPath == "" {
+			names = append(names, field.Name)
+		}
+	}
+
+	pointerType := reflect.PtrTo(structType)
+	for index := 0; index < pointerType.NumMethod(); index++ {
+		names = append(names, pointerType.Method(index).Name)
+	}
+
+	return names
+}
+
+func mapKeys(value reflect.Value) []string {
+	keys := make([]string, 0, value.Len())
+	for _, key := range value.MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// suggestion returns a message suggesting the closest name to `name` in
+// `candidates`, or "" if none of them are close.
+func suggestion(name string, candidates []string) string {
+	best := ""
+	// Only suggest names which are reasonably close.
+	bestDistance := len(name)/3 + 2
+	for _, candidate := range candidates {
+		distance := editDistance(strings.ToLower(name), strings.ToLower(candidate))
+		if distance < bestDistance {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %q?)", best)
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+func minInt(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+	return result
+}
@@ -0,0 +1,90 @@
+package modules
+
+import (
+	"testing"
+	"text/template"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func typeCheckTestTemplate(t *testing.T, tmpl string, data interface{}) []string {
+	compiled, err := template.New("test").Parse(tmpl)
+	assert.Nil(t, err)
+	return typeCheckTemplate(compiled.Tree, data)
+}
+
+func TestTypeCheckTemplate(t *testing.T) {
+	data := TemplateData{Data: gitDivergedResult{}, Globals: &Globals{}}
+
+	assert.Empty(t, typeCheckTestTemplate(t,
+		`{{ .Text }}{{ .Data.Ahead }}{{ .Globals.LogicalCWD }}{{ print .Data.Behind }}`,
+		data,
+	))
+
+	// Should find problems in branches that wouldn't execute.
+	assert.Equal(t,
+		[]string{
+			`test:1:28: <.Data.Ahed>: modules.gitDivergedResult has no field "Ahed" (did you mean "Ahead"?)`,
+			`test:1:57: <.Globals.cwd>: modules.Globals has no field "cwd" (did you mean "CWD"?)`,
+		},
+		typeCheckTestTemplate(t,
+			`{{ if .Data.Ahead }}{{ .Data.Ahed }}{{ else }}{{ .Globals.cwd }}{{ end }}`,
+			data,
+		),
+	)
+}
+
+func TestTypeCheckTemplateScopes(t *testing.T) {
+	data := TemplateData{Data: diagnosticsModuleData{}, Globals: &Globals{}}
+
+	assert.Equal(t,
+		[]string{
+			`test:1:32: <.Kindd>: modules.Diagnostic has no field "Kindd" (did you mean "Kind"?)`,
+			`test:1:94: <$d.Mesage>: modules.Diagnostic has no field "Mesage" (did you mean "Message"?)`,
+			`test:1:147: <$.Data.Foo>: modules.diagnosticsModuleData has no field "Foo"`,
+			`test:1:196: <$data.Cont>: modules.diagnosticsModuleData has no field "Cont" (did you mean "Count"?)`,
+			`test:1:230: <.Symbol.Length>: can't evaluate field Length in type string`,
+		},
+		typeCheckTestTemplate(t,
+			`{{ range .Data.Diagnostics }}{{ .Kindd }}{{ end }}`+
+				`{{ range $i, $d := .Data.Diagnostics }}{{ $d.Mesage }}{{ end }}`+
+				`{{ with .Data.Symbol }}{{ . }}{{ $.Data.Foo }}{{ end }}`+
+				`{{ $data := .Data }}{{ $data.Cont }}`+
+				`{{ with .Data }}{{ .Symbol.Length }}{{ end }}`,
+			data,
+		),
+	)
+}
+
+func TestTypeCheckTemplateBlockModules(t *testing.T) {
+	block := moduleWrapperFromYAML(heredoc.Doc(`
+		type: block
+		modules:
+		  - type: git_status
+		  - type: directory
+		    id: dir
+		  - type: custom
+		    command: echo
+		    as: json
+	`))
+	data := TemplateData{Data: exampleData(block), Globals: &Globals{}}
+
+	assert.Equal(t,
+		[]string{
+			`test:1:8: <.Data.Modules.git_stats.Text>: no module with ID "git_stats" in block (did you mean "git_status"?)`,
+			`test:1:42: <.Data.Modules.dir.Data.ReadOnli>: modules.directoryModuleResult has no field "ReadOnli" (did you mean "ReadOnly"?)`,
+		},
+		typeCheckTestTemplate(t,
+			`{{ .Data.Modules.git_stats.Text }}{{ .Data.Modules.dir.Data.ReadOnli }}`+
+				`{{ .Data.Modules.git_status.Data.Index.Added }}{{ .Data.Modules.custom.Data.anything }}`,
+			data,
+		),
+	)
+}
+
+func TestSuggestion(t *testing.T) {
+	assert.Equal(t, ` (did you mean "Ahead"?)`, suggestion("Ahed", []string{"Upstream", "Ahead", "Behind"}))
+	assert.Equal(t, ` (did you mean "Path"?)`, suggestion("path", []string{"Path", "PathSeparator"}))
+	assert.Equal(t, "", suggestion("Foo", []string{"Upstream", "Ahead", "Behind"}))
+}
@@ -1,6 +1,8 @@
 package modules
 
 import (
+	"context"
+
 	"github.com/jwalton/kitsch/internal/kitsch/modules/schemas"
 	"gopkg.in/yaml.v3"
 )
@@ -22,7 +24,7 @@ type textModuleResult struct {
 }
 
 // Execute the module.
-func (mod TextModule) Execute(context *Context) ModuleResult {
+func (mod TextModule) Execute(ctx context.Context, context *Context) ModuleResult {
 	return ModuleResult{
 		DefaultText: mod.Text,
 		Data:        textModuleResult{Text: mod.Text},
@@ -34,6 +36,7 @@ func init() {
 		"text",
 		registeredModule{
 			jsonSchema: schemas.TextModuleJSONSchema,
+			data:       textModuleResult{},
 			factory: func(node *yaml.Node) (Module, error) {
 				module := TextModule{Type: "text"}
 				err := node.Decode(&module)
@@ -1,6 +1,7 @@
 package modules
 
 import (
+	"context"
 	"time"
 
 	"github.com/jwalton/kitsch/internal/kitsch/modules/schemas"
@@ -37,7 +38,7 @@ type timeModuleData struct {
 }
 
 // Execute the time module.
-func (mod TimeModule) Execute(context *Context) ModuleResult {
+func (mod TimeModule) Execute(ctx context.Context, context *Context) ModuleResult {
 	now := time.Now()
 
 	layout := mod.Layout
@@ -62,6 +63,7 @@ func init() {
 		"time",
 		registeredModule{
 			jsonSchema: schemas.TimeModuleJSONSchema,
+			data:       timeModuleData{},
 			factory: func(node *yaml.Node) (Module, error) {
 				module := TimeModule{Type: "time"}
 				err := node.Decode(&module)
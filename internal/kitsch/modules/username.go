@@ -1,6 +1,7 @@
 package modules
 
 import (
+	"context"
 	"os/user"
 
 	"github.com/jwalton/kitsch/internal/kitsch/log"
@@ -65,7 +66,7 @@ func (data usernameModuleData) Username() string {
 }
 
 // Execute the username module.
-func (mod UsernameModule) Execute(context *Context) ModuleResult {
+func (mod UsernameModule) Execute(ctx context.Context, context *Context) ModuleResult {
 	isRoot := context.Globals.IsRoot
 	isSSH := context.Environment.HasSomeEnv("SSH_CLIENT", "SSH_CONNECTION", "SSH_TTY")
 	show := isSSH || isRoot || mod.ShowAlways
@@ -93,11 +94,17 @@ func (mod UsernameModule) Execute(context *Context) ModuleResult {
 	}
 }
 
+// Validate validates the styles for this module.
+func (mod UsernameModule) Validate(validation *Validation) {
+	validation.Style("rootStyle", mod.RootStyle)
+}
+
 func init() {
 	registerModule(
 		"username",
 		registeredModule{
 			jsonSchema: schemas.UsernameModuleJSONSchema,
+			data:       usernameModuleData{},
 			factory: func(node *yaml.Node) (Module, error) {
 				module := UsernameModule{Type: "username"}
 				err := node.Decode(&module)
@@ -1,6 +1,7 @@
 package modules
 
 import (
+	gocontext "context"
 	"testing"
 
 	"github.com/MakeNowJust/heredoc"
@@ -14,7 +15,7 @@ func TestUsernameNoSSH(t *testing.T) {
 	`))
 	context := newTestContext("jwalton")
 
-	result := mod.Execute(context)
+	result := mod.Execute(gocontext.Background(), context)
 	assert.Equal(t, "", result.Text)
 }
 
@@ -25,7 +26,7 @@ func TestUsernameNoSSHWithTemplate(t *testing.T) {
 	`))
 	context := newTestContext("jwalton")
 
-	result := mod.Execute(context)
+	result := mod.Execute(gocontext.Background(), context)
 	assert.Equal(t, "jwalton", result.Text)
 }
 
@@ -43,7 +44,7 @@ func TestUsername(t *testing.T) {
 		},
 	}
 
-	result := mod.Execute(context)
+	result := mod.Execute(gocontext.Background(), context)
 
 	assert.Equal(t, "jwalton", result.Text)
 	assert.Equal(t,
@@ -0,0 +1,213 @@
+package modules
+
+import (
+	"fmt"
+	"strings"
+	"text/template/parse"
+
+	"github.com/jwalton/kitsch/internal/kitsch/modtemplate"
+	"github.com/jwalton/kitsch/internal/kitsch/styling"
+)
+
+// Validation is used to check the configuration of a module for problems,
+// such as invalid styles or templates that fail to compile.  See ValidateModule.
+type Validation struct {
+	// Context is the context to use to compile styles and templates.
+	Context *Context
+	// wrapper is the module being validated.
+	wrapper ModuleWrapper
+	// problems is the list of problems found so far.  This is shared between
+	// a validation and the validations for any child modules.
+	problems *[]Diagnostic
+}
+
+// validatingModule is implemented by modules which have configuration that
+// needs to be validated, in addition to the CommonConfig.
+type validatingModule interface {
+	// Validate checks the configuration of this module, and reports any
+	// problems via `validation.Report()`.
+	Validate(validation *Validation)
+}
+
+// exampleDataModule is implemented by modules where the type of data returned
+// from Execute depends on the module's configuration.
+type exampleDataModule interface {
+	// exampleData returns an example of the `Data` this module returns from
+	// Execute, or nil if the type of the data can't be known ahead of time.
+	exampleData() interface{}
+}
+
+// ValidateModule checks a module and all of its children for configuration
+// problems.  Every style is compiled, and every template is compiled and
+// then executed with example data for the module.  Each problem is returned
+// as a Diagnostic, where the `Module` includes the line and column of the
+// module in the configuration file.
+func ValidateModule(context *Context, wrapper ModuleWrapper) []Diagnostic {
+	problems := []Diagnostic{}
+	validation := &Validation{Context: context, problems: &problems}
+	validation.Module(wrapper)
+	return problems
+}
+
+// Module validates a child module.
+func (validation *Validation) Module(wrapper ModuleWrapper) {
+	if wrapper.Module == nil {
+		return
+	}
+
+	child := &Validation{
+		Context:  validation.Context,
+		wrapper:  wrapper,
+		problems: validation.problems,
+	}
+
+	wrapper.config.Validate(child)
+	if wrapper.config.If != "" {
+		child.Template("if expression", ifExpressionTemplate(wrapper.config.If), ifExpressionData(validation.Context))
+	}
+	child.Template("template", wrapper.config.Template, TemplateData{
+		Data:    exampleData(wrapper),
+		Globals: &validation.Context.Globals,
+	})
+
+	if module, ok := wrapper.Module.(validatingModule); ok {
+		module.Validate(child)
+	}
+}
+
+// Report records a problem with the module being validated.
+func (validation *Validation) Report(kind DiagnosticKind, message string) {
+	module := ""
+	if validation.wrapper.Module != nil {
+		module = validation.wrapper.String()
+		if validation.wrapper.File != "" {
+			module += " in " + validation.wrapper.File
+		}
+	}
+
+	*validation.problems = append(*validation.problems, Diagnostic{
+		Kind:    kind,
+		Module:  module,
+		Message: message,
+	})
+}
+
+// Style reports a problem if the given style string can't be compiled.
+// `name` is the name of the setting the style came from (e.g. "style").
+func (validation *Validation) Style(name string, style string) {
+	if style == "" {
+		return
+	}
+
+	_, err := validation.Context.Styles.Get(style)
+	if err != nil {
+		validation.Report(DiagnosticStyle, fmt.Sprintf("Invalid %s: %v", name, err))
+	}
+}
+
+// Template reports a problem if the given template fails to compile, or
+// fails when executed with the given data.  `name` is used in error messages
+// to describe the template (e.g. "join template").
+func (validation *Validation) Template(name string, tmpl string, data interface{}) {
+	if tmpl == "" {
+		return
+	}
+
+	context := validation.Context
+	compiled, err := modtemplate.CompileTemplate(context.Styles, context.Environment, name, tmpl)
+	if err != nil {
+		validation.Report(DiagnosticTemplate, fmt.Sprintf("Error compiling %s: %v", name, err))
+		return
+	}
+
+	// Styles passed to the "style" template function are ignored at runtime
+	// if they are invalid, so check any styles we can find.
+	walkTemplate(compiled.Tree.Root, func(command *parse.CommandNode) {
+		if len(command.Args) < 2 {
+			return
+		}
+		function, ok := command.Args[0].(*parse.IdentifierNode)
+		if !ok {
+			return
+		}
+		style, ok := command.Args[1].(*parse.StringNode)
+		if !ok {
+			return
+		}
+
+		switch function.Ident {
+		case "style":
+			validation.Style("style in "+name, style.Text)
+		case "fgColor":
+			validation.Style("color in "+name, styling.ToFgColor(style.Text))
+		case "bgColor":
+			validation.Style("color in "+name, styling.ToBgColor(style.Text))
+		}
+	})
+
+	// Check the template against the type of the data.  This finds problems
+	// in branches of the template that a dry run wouldn't execute.
+	if problems := typeCheckTemplate(compiled.Tree, data); len(problems) != 0 {
+		for _, problem := range problems {
+			validation.Report(DiagnosticTemplate, fmt.Sprintf("Error in %s: %s", name, problem))
+		}
+		// Executing the template would just report the same problems.
+		return
+	}
+
+	_, err = modtemplate.TemplateToString(compiled, data)
+	if err != nil && !strings.Contains(err.Error(), "nil pointer evaluating interface {}") {
+		// Errors from accessing fields of a nil interface are ignored, since
+		// this is what happens when a template tries to read data we don't
+		// have an example of (e.g. from a custom module that returns JSON).
+		validation.Report(DiagnosticTemplate, fmt.Sprintf("Error executing %s: %v", name, err))
+	}
+}
+
+// walkTemplate calls `fn` for every command in a parsed template.
+func walkTemplate(node parse.Node, fn func(command *parse.CommandNode)) {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node != nil {
+			for _, child := range node.Nodes {
+				walkTemplate(child, fn)
+			}
+		}
+	case *parse.ActionNode:
+		walkTemplate(node.Pipe, fn)
+	case *parse.PipeNode:
+		if node != nil {
+			for _, command := range node.Cmds {
+				walkTemplate(command, fn)
+			}
+		}
+	case *parse.CommandNode:
+		fn(node)
+		for _, arg := range node.Args {
+			walkTemplate(arg, fn)
+		}
+	case *parse.IfNode:
+		walkBranch(&node.BranchNode, fn)
+	case *parse.RangeNode:
+		walkBranch(&node.BranchNode, fn)
+	case *parse.WithNode:
+		walkBranch(&node.BranchNode, fn)
+	case *parse.TemplateNode:
+		walkTemplate(node.Pipe, fn)
+	}
+}
+
+func walkBranch(node *parse.BranchNode, fn func(command *parse.CommandNode)) {
+	walkTemplate(node.Pipe, fn)
+	walkTemplate(node.List, fn)
+	walkTemplate(node.ElseList, fn)
+}
+
+// exampleData returns an example of the data the given module will return
+// when executed, or nil if this is not known.
+func exampleData(wrapper ModuleWrapper) interface{} {
+	if module, ok := wrapper.Module.(exampleDataModule); ok {
+		return module.exampleData()
+	}
+	return registeredModules[wrapper.config.Type].data
+}
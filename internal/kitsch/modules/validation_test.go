@@ -0,0 +1,104 @@
+package modules
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateModule(t *testing.T) {
+	module := moduleWrapperFromYAML(heredoc.Doc(`
+		type: block
+		join: "{{ .Prev }}"
+		template: "{{ .Data.Modules.git_status.Data.Unmerged }}{{ .Data.Modules.dir.Data.Pth }}"
+		modules:
+		  - type: git_status
+		    unstagedStyle: brigthBlue
+		  - type: directory
+		    id: dir
+		    template: '{{ style "nosuchcolor" .Data.Path }}'
+		  - type: text
+		    text: hello
+		    template: "{{ .Data.Text }"
+		  - type: custom
+		    command: echo
+		    regex: "("
+		  - type: command_duration
+		    minTime: -1
+	`))
+
+	problems := ValidateModule(newTestContext("jwalton"), module)
+
+	assert.Equal(t, []Diagnostic{
+		{
+			Kind:    DiagnosticTemplate,
+			Module:  "block(1:1)",
+			Message: `Error in template: template:1:52: <.Data.Modules.dir.Data.Pth>: modules.directoryModuleResult has no field "Pth" (did you mean "Path"?)`,
+		},
+		{
+			Kind:    DiagnosticTemplate,
+			Module:  "block(1:1)",
+			Message: `Error in join template: join template:1:3: <.Prev>: modules.blockJoinData has no field "Prev"`,
+		},
+		{
+			Kind:    DiagnosticStyle,
+			Module:  "git_status(5:5)",
+			Message: `Invalid unstagedStyle: error compiling style "brigthBlue": unknown style "brigthBlue"`,
+		},
+		{
+			Kind:    DiagnosticStyle,
+			Module:  "directory#dir(7:5)",
+			Message: `Invalid style in template: error compiling style "nosuchcolor": unknown style "nosuchcolor"`,
+		},
+		{
+			Kind:    DiagnosticTemplate,
+			Module:  "text(10:5)",
+			Message: `Error compiling template: template: template:1: unexpected "}" in operand`,
+		},
+		{
+			Kind:    DiagnosticGetter,
+			Module:  "custom(13:5)",
+			Message: "invalid regex: \"(\": error parsing regexp: missing closing ): `(`",
+		},
+		{
+			Kind:    DiagnosticConfig,
+			Module:  "command_duration(16:5)",
+			Message: "Invalid minTime: -1",
+		},
+	}, problems)
+}
+
+func TestValidateModuleWithIf(t *testing.T) {
+	module := moduleWrapperFromYAML(heredoc.Doc(`
+		type: text
+		text: hello
+		if: "ne .Globals.Statsu 0"
+	`))
+
+	problems := ValidateModule(newTestContext("jwalton"), module)
+	assert.Equal(t, []Diagnostic{
+		{
+			Kind:    DiagnosticTemplate,
+			Module:  "text(1:1)",
+			Message: `Error in if expression: if expression:1:14: <.Globals.Statsu>: modules.Globals has no field "Statsu" (did you mean "Status"?)`,
+		},
+	}, problems)
+}
+
+func TestValidateModuleWithUnknownData(t *testing.T) {
+	// We don't know what the data for a JSON custom module will look like, so
+	// we shouldn't complain about it.
+	module := moduleWrapperFromYAML(heredoc.Doc(`
+		type: block
+		template: "{{ .Data.Modules.custom.Data.foo.bar }}"
+		modules:
+		  - type: custom
+		    command: echo
+		    as: json
+		    template: "{{ .Data.foo }}"
+	`))
+
+	problems := ValidateModule(newTestContext("jwalton"), module)
+	assert.Equal(t, []Diagnostic{}, problems)
+}
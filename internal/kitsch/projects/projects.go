@@ -4,6 +4,7 @@ package projects
 
 import (
 	"github.com/MakeNowJust/heredoc"
+	"github.com/jwalton/kitsch/internal/kitsch/condition"
 	"github.com/jwalton/kitsch/internal/kitsch/getters"
 	"github.com/jwalton/kitsch/internal/kitsch/log"
 )
@@ -79,13 +80,15 @@ func getStringValue(getter []getters.Getter, getterContext getters.GetterContext
 }
 
 // ResolveProjectType returns the project type for the specified folder, or nil
-// if the project type cannot be determined.
+// if the project type cannot be determined.  `environment` is used to check
+// the conditions for each project type.
 func ResolveProjectType(
 	projectTypes []ProjectType,
+	environment condition.Environment,
 	getterContext getters.GetterContext,
 ) *ProjectInfo {
 	for _, projectType := range projectTypes {
-		if !projectType.Conditions.Matches(getterContext.GetWorkingDirectory()) {
+		if !projectType.Conditions.Matches(environment) {
 			continue
 		}
 
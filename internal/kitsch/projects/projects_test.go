@@ -1,6 +1,7 @@
 package projects
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"testing/fstest"
@@ -24,12 +25,18 @@ func (g *testGetter) GetValue(getterContext getters.GetterContext) (interface{},
 }
 
 type testGetterContext struct {
+	ctx       context.Context
 	directory fileutils.Directory
 	home      string
 	cache     cache.Cache
 	env       map[string]string
 }
 
+// Context returns the context for the current operation.
+func (context *testGetterContext) Context() context.Context {
+	return context.ctx
+}
+
 // GetWorkingDirectory returns the current working directory.
 func (context *testGetterContext) GetWorkingDirectory() fileutils.Directory {
 	return context.directory
@@ -45,6 +52,15 @@ func (context *testGetterContext) Getenv(key string) string {
 	return context.env[key]
 }
 
+// Environ returns the environment to run external commands in.
+func (context *testGetterContext) Environ() []string {
+	result := []string{}
+	for key, value := range context.env {
+		result = append(result, key+"="+value)
+	}
+	return result
+}
+
 // GetValueCache returns the value cache.
 func (context *testGetterContext) GetValueCache() cache.Cache {
 	return context.cache
@@ -52,6 +68,7 @@ func (context *testGetterContext) GetValueCache() cache.Cache {
 
 func makeTestGetterContext(fsys fstest.MapFS) *testGetterContext {
 	return &testGetterContext{
+		ctx:       context.Background(),
 		directory: fileutils.NewDirectoryTestFS("/foo/bar", fsys),
 		home:      "/users/jwalton",
 		cache:     cache.NewMemoryCache(),
@@ -0,0 +1,368 @@
+package starship
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type nodeKind int
+
+const (
+	// textNode is literal text.
+	textNode nodeKind = iota
+	// variableNode is a variable, like "$path" or "${custom.foo}".
+	variableNode
+	// styledGroupNode is a text group with a style, like "[$path](bold cyan)".
+	styledGroupNode
+	// conditionalGroupNode is a group which is only shown if one of the
+	// variables inside it is non-empty, like "( \($namespace\))".
+	conditionalGroupNode
+)
+
+// formatNode is a node in a parsed starship format string.
+type formatNode struct {
+	kind nodeKind
+	// value is the text for a textNode, or the name of the variable for a
+	// variableNode.
+	value string
+	// style is the style for a styledGroupNode.
+	style string
+	// children are the contents of a styledGroupNode or conditionalGroupNode.
+	children []formatNode
+}
+
+// parseFormat parses a starship format string.
+func parseFormat(format string) ([]formatNode, error) {
+	parser := formatParser{input: []rune(format)}
+	return parser.parse(0)
+}
+
+type formatParser struct {
+	input    []rune
+	position int
+}
+
+// parse reads nodes until the given `end` character is found.  If `end` is 0,
+// reads until the end of the input.
+func (parser *formatParser) parse(end rune) ([]formatNode, error) {
+	nodes := []formatNode{}
+	text := strings.Builder{}
+
+	flushText := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, formatNode{kind: textNode, value: text.String()})
+			text.Reset()
+		}
+	}
+
+	for parser.position < len(parser.input) {
+		c := parser.input[parser.position]
+
+		switch {
+		case c == '\\':
+			// Escaped character.
+			parser.position++
+			if parser.position < len(parser.input) {
+				text.WriteRune(parser.input[parser.position])
+				parser.position++
+			}
+
+		case end != 0 && c == end:
+			parser.position++
+			flushText()
+			return nodes, nil
+
+		case c == '$':
+			name := parser.readVariable()
+			if name == "" {
+				text.WriteRune(c)
+			} else {
+				flushText()
+				nodes = append(nodes, formatNode{kind: variableNode, value: name})
+			}
+
+		case c == '[':
+			parser.position++
+			flushText()
+			children, err := parser.parse(']')
+			if err != nil {
+				return nil, err
+			}
+			style := ""
+			if parser.position < len(parser.input) && parser.input[parser.position] == '(' {
+				style, err = parser.readStyle()
+				if err != nil {
+					return nil, err
+				}
+			}
+			nodes = append(nodes, formatNode{kind: styledGroupNode, style: style, children: children})
+
+		case c == '(':
+			parser.position++
+			flushText()
+			children, err := parser.parse(')')
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, formatNode{kind: conditionalGroupNode, children: children})
+
+		default:
+			text.WriteRune(c)
+			parser.position++
+		}
+	}
+
+	if end != 0 {
+		return nil, fmt.Errorf("missing '%c' in format string %q", end, string(parser.input))
+	}
+
+	flushText()
+	return nodes, nil
+}
+
+// readVariable reads a variable name starting at the current position, which
+// should be a "$".  Returns "" if there is no variable here, in which case the
+// position will be moved past the "$".
+func (parser *formatParser) readVariable() string {
+	parser.position++
+
+	if parser.position < len(parser.input) && parser.input[parser.position] == '{' {
+		start := parser.position + 1
+		for index := start; index < len(parser.input); index++ {
+			if parser.input[index] == '}' {
+				parser.position = index + 1
+				return string(parser.input[start:index])
+			}
+		}
+		return ""
+	}
+
+	start := parser.position
+	for parser.position < len(parser.input) && isVariableCharacter(parser.input[parser.position]) {
+		parser.position++
+	}
+	return string(parser.input[start:parser.position])
+}
+
+func isVariableCharacter(c rune) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// readStyle reads a "(style)" starting at the current position.
+func (parser *formatParser) readStyle() (string, error) {
+	start := parser.position + 1
+	for index := start; index < len(parser.input); index++ {
+		if parser.input[index] == ')' {
+			parser.position = index + 1
+			return string(parser.input[start:index]), nil
+		}
+	}
+	return "", fmt.Errorf("missing ')' in format string %q", string(parser.input))
+}
+
+// variableNames returns the names of all variables in the given nodes.
+func variableNames(nodes []formatNode) []string {
+	result := []string{}
+	for _, node := range nodes {
+		switch node.kind {
+		case variableNode:
+			result = append(result, node.value)
+		case styledGroupNode, conditionalGroupNode:
+			result = append(result, variableNames(node.children)...)
+		}
+	}
+	return result
+}
+
+// templateBuilder converts parsed format strings into kitsch templates.
+type templateBuilder struct {
+	converter *converter
+	// description is used in warnings (e.g. "directory module").
+	description string
+	// variables maps starship variable names to golang template expressions.
+	// Style variables (like "$style") should be expressions that evaluate to
+	// a kitsch style string.  A variable which is always empty should be `""`.
+	variables map[string]string
+	// preamble is added to the start of the template, and can be used to
+	// declare template variables.
+	preamble string
+}
+
+// emptyExpression is the template expression for a variable which is always empty.
+const emptyExpression = `""`
+
+// variable returns the template expression for the given variable.
+func (builder *templateBuilder) variable(name string) (string, bool) {
+	expression, ok := builder.variables[name]
+	if !ok {
+		builder.converter.warn("Unknown variable $%s in %s format", name, builder.description)
+	}
+	return expression, ok
+}
+
+// template converts the given nodes into a kitsch template.
+func (builder *templateBuilder) template(nodes []formatNode) string {
+	result := strings.Builder{}
+
+	for _, node := range nodes {
+		switch node.kind {
+		case textNode:
+			result.WriteString(strings.ReplaceAll(node.value, "{{", `{{ "{{" }}`))
+		case variableNode:
+			if expression, ok := builder.variable(node.value); ok {
+				result.WriteString("{{ " + unwrapExpression(expression) + " }}")
+			}
+		case styledGroupNode:
+			result.WriteString("{{ " + builder.styledGroup(node) + " }}")
+		case conditionalGroupNode:
+			condition := builder.condition(node.children)
+			if condition == "" {
+				result.WriteString(builder.template(node.children))
+			} else if condition != "false" {
+				result.WriteString("{{ if " + condition + " }}" + builder.template(node.children) + "{{ end }}")
+			}
+		}
+	}
+
+	return result.String()
+}
+
+// expression converts the given nodes into a single template expression.
+func (builder *templateBuilder) expression(nodes []formatNode) string {
+	parts := []string{}
+
+	for _, node := range nodes {
+		switch node.kind {
+		case textNode:
+			parts = append(parts, strconv.Quote(node.value))
+		case variableNode:
+			if expression, ok := builder.variable(node.value); ok && expression != emptyExpression {
+				parts = append(parts, expression)
+			}
+		case styledGroupNode:
+			parts = append(parts, "("+builder.styledGroup(node)+")")
+		case conditionalGroupNode:
+			condition := builder.condition(node.children)
+			if condition == "" {
+				parts = append(parts, builder.expression(node.children))
+			} else if condition != "false" {
+				parts = append(parts, "(ternary "+builder.expression(node.children)+` "" `+condition+")")
+			}
+		}
+	}
+
+	switch len(parts) {
+	case 0:
+		return emptyExpression
+	case 1:
+		return parts[0]
+	default:
+		return "(print " + strings.Join(parts, " ") + ")"
+	}
+}
+
+// styledGroup converts a styled group into a template expression.
+func (builder *templateBuilder) styledGroup(node formatNode) string {
+	contents := builder.expression(node.children)
+	style := builder.style(node.style)
+	if style == "" {
+		return contents
+	}
+	return "style " + style + " " + contents
+}
+
+// style converts a starship style string into a template expression that
+// evaluates to a kitsch style string.  Returns "" if the style is empty.
+func (builder *templateBuilder) style(style string) string {
+	tokens := strings.Fields(style)
+
+	// If the style is a single variable, use the variable's expression.
+	if len(tokens) == 1 && strings.HasPrefix(tokens[0], "$") {
+		expression, ok := builder.variable(tokens[0][1:])
+		if !ok {
+			return ""
+		}
+		return expression
+	}
+
+	// Otherwise, substitute in the values of any variables.
+	literalTokens := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if strings.HasPrefix(token, "$") {
+			expression, ok := builder.variable(token[1:])
+			if !ok {
+				continue
+			}
+			value, err := strconv.Unquote(expression)
+			if err != nil {
+				builder.converter.warn("Can't combine $%s with other styles in %s format", token[1:], builder.description)
+				continue
+			}
+			literalTokens = append(literalTokens, value)
+		} else {
+			literalTokens = append(literalTokens, builder.converter.convertStyle(token))
+		}
+	}
+
+	result := strings.TrimSpace(strings.Join(literalTokens, " "))
+	if result == "" {
+		return ""
+	}
+	return strconv.Quote(result)
+}
+
+// condition returns a template expression which is true if any of the
+// variables in the given nodes are non-empty.  Returns "" if there are no
+// variables, or "false" if all the variables are always empty.
+func (builder *templateBuilder) condition(nodes []formatNode) string {
+	names := variableNames(nodes)
+	if len(names) == 0 {
+		return ""
+	}
+
+	expressions := []string{}
+	for _, name := range names {
+		if expression, ok := builder.variables[name]; ok && expression != emptyExpression {
+			expressions = append(expressions, expression)
+		}
+	}
+
+	if len(expressions) == 0 {
+		return "false"
+	}
+	return `(ne (print ` + strings.Join(expressions, " ") + `) "")`
+}
+
+// unwrapExpression removes the parentheses from around an expression like
+// "(print .A .B)", so it can be used on its own in a template action.
+func unwrapExpression(expression string) string {
+	if !strings.HasPrefix(expression, "(") || !strings.HasSuffix(expression, ")") {
+		return expression
+	}
+
+	// Make sure the opening parenthesis is closed by the last character, and
+	// not earlier, as in "(print .A) (print .B)".
+	depth := 0
+	inString := false
+	for index := 0; index < len(expression); index++ {
+		c := expression[index]
+		switch {
+		case inString && c == '\\':
+			index++
+		case c == '"':
+			inString = !inString
+		case inString:
+			// Ignore parentheses inside strings.
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 && index != len(expression)-1 {
+				return expression
+			}
+		}
+	}
+
+	return expression[1 : len(expression)-1]
+}
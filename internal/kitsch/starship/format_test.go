@@ -0,0 +1,63 @@
+package starship
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFormat(t *testing.T) {
+	nodes, err := parseFormat(`[$path]($style)[$read_only]($read_only_style) ( \(${custom.foo}\))$`)
+	assert.Nil(t, err)
+	assert.Equal(t, []formatNode{
+		{kind: styledGroupNode, style: "$style", children: []formatNode{
+			{kind: variableNode, value: "path"},
+		}},
+		{kind: styledGroupNode, style: "$read_only_style", children: []formatNode{
+			{kind: variableNode, value: "read_only"},
+		}},
+		{kind: textNode, value: " "},
+		{kind: conditionalGroupNode, children: []formatNode{
+			{kind: textNode, value: " ("},
+			{kind: variableNode, value: "custom.foo"},
+			{kind: textNode, value: ")"},
+		}},
+		{kind: textNode, value: "$"},
+	}, nodes)
+}
+
+func TestParseFormatUnterminated(t *testing.T) {
+	_, err := parseFormat(`[$path`)
+	assert.EqualError(t, err, `missing ']' in format string "[$path"`)
+
+	_, err = parseFormat(`[$path](bold`)
+	assert.EqualError(t, err, `missing ')' in format string "[$path](bold"`)
+}
+
+func TestTemplateBuilder(t *testing.T) {
+	c := &converter{palette: map[string]string{}}
+	builder := &templateBuilder{
+		converter:   c,
+		description: "test module",
+		variables: map[string]string{
+			"name":  ".Data.Name",
+			"tag":   emptyExpression,
+			"style": `"bold blue"`,
+		},
+	}
+
+	nodes, err := parseFormat(`on [$name( $tag)]($style)( \($name\))$unknown`)
+	assert.Nil(t, err)
+	assert.Equal(t,
+		`on {{ style "bold blue" .Data.Name }}{{ if (ne (print .Data.Name) "") }} ({{ .Data.Name }}){{ end }}`,
+		builder.template(nodes),
+	)
+	assert.Equal(t, []string{"Unknown variable $unknown in test module format"}, c.warnings)
+}
+
+func TestUnwrapExpression(t *testing.T) {
+	assert.Equal(t, `print .A .B`, unwrapExpression(`(print .A .B)`))
+	assert.Equal(t, `print (style "red" .A) ")"`, unwrapExpression(`(print (style "red" .A) ")")`))
+	assert.Equal(t, `(print .A) (print .B)`, unwrapExpression(`(print .A) (print .B)`))
+	assert.Equal(t, `.Data.Path`, unwrapExpression(`.Data.Path`))
+}
@@ -0,0 +1,472 @@
+package starship
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// moduleDefinition describes how to convert a starship module into a kitsch
+// module.
+type moduleDefinition struct {
+	// kitschType is the type of the kitsch module to generate.
+	kitschType string
+	// defaults are the default values for the starship module's options.  If
+	// there's no "format" default, the kitsch module will have no template.
+	defaults map[string]interface{}
+	// convert copies options into the kitsch module, and adds template
+	// variables to the builder.  Returns a template expression which must be
+	// true for the module to be shown.
+	convert func(c *converter, name string, options *options, module yamlMap, builder *templateBuilder) string
+}
+
+// languageModules maps starship language modules to kitsch project types.
+var languageModules = map[string]string{
+	"deno":   "deno",
+	"golang": "go",
+	"helm":   "helm",
+	"java":   "java",
+	"nodejs": "node",
+	"php":    "php",
+	"python": "python",
+	"ruby":   "ruby",
+	"rust":   "rust",
+}
+
+var moduleDefinitions = map[string]moduleDefinition{
+	"username": {
+		kitschType: "username",
+		defaults: map[string]interface{}{
+			"format":     "[$user]($style) in ",
+			"style_user": "yellow bold",
+			"style_root": "red bold",
+		},
+		convert: func(c *converter, name string, options *options, module yamlMap, builder *templateBuilder) string {
+			options.copy("show_always", module, "showAlways")
+			builder.variables["user"] = ".Data.Username"
+			builder.variables["style"] = fmt.Sprintf("(ternary %s %s .Globals.IsRoot)",
+				strconv.Quote(c.convertStyle(options.string("style_root"))),
+				strconv.Quote(c.convertStyle(options.string("style_user"))),
+			)
+			return ".Text"
+		},
+	},
+	"hostname": {
+		kitschType: "hostname",
+		defaults: map[string]interface{}{
+			"format":     "[$ssh_symbol$hostname]($style) in ",
+			"style":      "green dimmed bold",
+			"ssh_only":   true,
+			"ssh_symbol": "🌐 ",
+			"trim_at":    ".",
+		},
+		convert: func(c *converter, name string, options *options, module yamlMap, builder *templateBuilder) string {
+			if options.isSet("ssh_only") {
+				module.set("showAlways", !options.bool("ssh_only"))
+			}
+			builder.variables["ssh_symbol"] = "(ternary " + builder.literal(options, "ssh_symbol") + ` "" .Data.IsSSH)`
+			builder.variables["hostname"] = ".Data.Hostname"
+			if trimAt := options.string("trim_at"); trimAt != "" {
+				builder.variables["hostname"] = "(first (splitList " + strconv.Quote(trimAt) + " .Data.Hostname))"
+			}
+			return ".Text"
+		},
+	},
+	"kubernetes": {
+		kitschType: "kubernetes",
+		defaults: map[string]interface{}{
+			"format":   `[$symbol$context( \($namespace\))]($style) in `,
+			"style":    "cyan bold",
+			"symbol":   "☸ ",
+			"disabled": true,
+		},
+		convert: func(c *converter, name string, options *options, module yamlMap, builder *templateBuilder) string {
+			options.copy("context_aliases", module, "contextAliases")
+			builder.variables["symbol"] = builder.literal(options, "symbol")
+			builder.variables["context"] = ".Data.Context"
+			builder.variables["namespace"] = ".Data.Namespace"
+			return ".Text"
+		},
+	},
+	"directory": {
+		kitschType: "directory",
+		defaults: map[string]interface{}{
+			"format":          "[$path]($style)[$read_only]($read_only_style) ",
+			"style":           "cyan bold",
+			"read_only_style": "red",
+		},
+		convert: func(c *converter, name string, options *options, module yamlMap, builder *templateBuilder) string {
+			options.copy("truncation_length", module, "truncationLength")
+			options.copy("truncate_to_repo", module, "truncateToRepo")
+			options.copy("truncation_symbol", module, "truncationSymbol")
+			options.copy("home_symbol", module, "homeSymbol")
+			options.copy("read_only", module, "readOnlySymbol")
+			builder.variables["path"] = ".Data.Path"
+			builder.variables["read_only"] = `(ternary .Data.ReadOnlySymbol "" .Data.ReadOnly)`
+			return ".Text"
+		},
+	},
+	"git_branch": {
+		kitschType: "git_head",
+		defaults: map[string]interface{}{
+			"format":            "on [$symbol$branch(:$remote_branch)]($style) ",
+			"style":             "bold purple",
+			"symbol":            " ",
+			"truncation_symbol": "…",
+		},
+		convert: func(c *converter, name string, options *options, module yamlMap, builder *templateBuilder) string {
+			builder.variables["symbol"] = builder.literal(options, "symbol")
+			builder.variables["branch"] = ".Data.Description"
+			builder.variables["remote_branch"] = emptyExpression
+			builder.variables["remote_name"] = emptyExpression
+			if options.isSet("truncation_length") {
+				length := options.int("truncation_length")
+				builder.variables["branch"] = fmt.Sprintf(
+					"(print (trunc %d .Data.Description) (ternary %s \"\" (gt (len .Data.Description) %d)))",
+					length,
+					strconv.Quote(options.string("truncation_symbol")),
+					length,
+				)
+			}
+			return ".Text"
+		},
+	},
+	"git_commit": {
+		kitschType: "git_head",
+		defaults: map[string]interface{}{
+			"format":             `[\($hash$tag\)]($style) `,
+			"style":              "green bold",
+			"commit_hash_length": int64(7),
+			"only_detached":      true,
+		},
+		convert: func(c *converter, name string, options *options, module yamlMap, builder *templateBuilder) string {
+			builder.variables["hash"] = fmt.Sprintf("(trunc %d .Data.Hash)", options.int("commit_hash_length"))
+			builder.variables["tag"] = emptyExpression
+			if options.bool("only_detached") {
+				return "(and .Text .Data.Detached)"
+			}
+			return ".Text"
+		},
+	},
+	"git_state": {
+		kitschType: "git_state",
+		defaults: map[string]interface{}{
+			"format": `\([$state( $progress_current/$progress_total)]($style)\) `,
+			"style":  "bold yellow",
+		},
+		convert: func(c *converter, name string, options *options, module yamlMap, builder *templateBuilder) string {
+			if options.isSet("rebase") {
+				options.copy("rebase", module, "rebaseInteractive")
+				options.copy("rebase", module, "rebaseMerging")
+				options.copy("rebase", module, "rebasing")
+			}
+			options.copy("merge", module, "merging")
+			options.copy("revert", module, "reverting")
+			options.copy("cherry_pick", module, "cherryPicking")
+			options.copy("bisect", module, "bisecting")
+			options.copy("am", module, "aming")
+			options.copy("am_or_rebase", module, "rebaseAMing")
+			builder.variables["state"] = ".Data.State"
+			builder.variables["progress_current"] = ".Data.Step"
+			builder.variables["progress_total"] = ".Data.Total"
+			return ".Text"
+		},
+	},
+	"git_status": {
+		kitschType: "block",
+		defaults: map[string]interface{}{
+			"format":     `([\[$all_status$ahead_behind\]]($style) )`,
+			"style":      "red bold",
+			"ahead":      "⇡",
+			"behind":     "⇣",
+			"diverged":   "⇕",
+			"up_to_date": "",
+			"conflicted": "=",
+			"untracked":  "?",
+			"stashed":    `\$`,
+			"modified":   "!",
+			"staged":     "+",
+			"renamed":    "»",
+			"deleted":    "✘",
+		},
+		convert: convertGitStatus,
+	},
+	"cmd_duration": {
+		kitschType: "command_duration",
+		defaults: map[string]interface{}{
+			"format": "took [$duration]($style) ",
+			"style":  "yellow bold",
+		},
+		convert: func(c *converter, name string, options *options, module yamlMap, builder *templateBuilder) string {
+			options.copy("min_time", module, "minTime")
+			options.copy("show_milliseconds", module, "showMilliseconds")
+			builder.variables["duration"] = ".Data.PrettyDuration"
+			return ".Text"
+		},
+	},
+	"line_break": {
+		kitschType: "text",
+		defaults:   map[string]interface{}{},
+		convert: func(c *converter, name string, options *options, module yamlMap, builder *templateBuilder) string {
+			module.set("text", "\n")
+			return ""
+		},
+	},
+	"fill": {
+		kitschType: "flexible_space",
+		defaults:   map[string]interface{}{},
+		convert: func(c *converter, name string, options *options, module yamlMap, builder *templateBuilder) string {
+			if symbol, ok := options.raw("symbol"); ok && symbol != " " {
+				c.warn("fill symbol %q is not supported; fill will use spaces", symbol)
+			}
+			options.raw("style")
+			return ""
+		},
+	},
+	"jobs": {
+		kitschType: "jobs",
+		defaults: map[string]interface{}{
+			"format":           "[$symbol$number]($style) ",
+			"style":            "bold blue",
+			"symbol":           "✦",
+			"number_threshold": int64(2),
+			"symbol_threshold": int64(1),
+		},
+		convert: func(c *converter, name string, options *options, module yamlMap, builder *templateBuilder) string {
+			options.copy("threshold", module, "countThreshold")
+			options.copy("number_threshold", module, "countThreshold")
+			options.copy("symbol_threshold", module, "symbolThreshold")
+			builder.variables["symbol"] = "(ternary " + builder.literal(options, "symbol") + ` "" .Data.ShowSymbol)`
+			builder.variables["number"] = `(ternary (print .Data.Jobs) "" .Data.ShowCount)`
+			return ".Text"
+		},
+	},
+	"time": {
+		kitschType: "time",
+		defaults: map[string]interface{}{
+			"format":   "at [$time]($style) ",
+			"style":    "bold yellow",
+			"disabled": true,
+		},
+		convert: func(c *converter, name string, options *options, module yamlMap, builder *templateBuilder) string {
+			if options.isSet("time_format") {
+				module.set("layout", c.convertTimeFormat(options.string("time_format")))
+			}
+			builder.variables["time"] = ".Data.TimeStr"
+			return ".Text"
+		},
+	},
+	"character": {
+		kitschType: "prompt",
+		defaults: map[string]interface{}{
+			"format":         "$symbol ",
+			"success_symbol": "[❯](bold green)",
+			"error_symbol":   "[❯](bold red)",
+			"vimcmd_symbol":  "[❮](bold green)",
+		},
+		convert: func(c *converter, name string, options *options, module yamlMap, builder *templateBuilder) string {
+			builder.variables["symbol"] = fmt.Sprintf(
+				"(ternary %s (ternary %s %s (eq .Globals.Status 0)) .Data.ViCmdMode)",
+				builder.literal(options, "vimcmd_symbol"),
+				builder.literal(options, "success_symbol"),
+				builder.literal(options, "error_symbol"),
+			)
+			return ".Text"
+		},
+	},
+	"custom": {
+		kitschType: "custom",
+		defaults: map[string]interface{}{
+			"format": "[$symbol($output )]($style)",
+			"style":  "green bold",
+			"symbol": "",
+		},
+		convert: convertCustom,
+	},
+	"env_var": {
+		kitschType: "text",
+		defaults: map[string]interface{}{
+			"format": "with [$env_value]($style) ",
+			"style":  "black bold dimmed",
+		},
+		convert: func(c *converter, name string, options *options, module yamlMap, builder *templateBuilder) string {
+			module.set("text", "")
+
+			variable := options.string("variable")
+			if variable == "" && strings.HasPrefix(name, "env_var.") {
+				variable = name[len("env_var."):]
+			}
+			if variable == "" {
+				// Starship doesn't show the env_var module if there's no variable.
+				return "false"
+			}
+
+			value := "(env " + strconv.Quote(variable) + ")"
+			if options.isSet("default") {
+				value = "(default " + strconv.Quote(options.string("default")) + " " + value + ")"
+			}
+
+			builder.variables["symbol"] = builder.literal(options, "symbol")
+			builder.variables["env_value"] = value
+			return value
+		},
+	},
+}
+
+// convertGitStatus converts the git_status module into a block containing a
+// git_status and git_diverged module.
+func convertGitStatus(c *converter, name string, options *options, module yamlMap, builder *templateBuilder) string {
+	gitStatus := newYAMLMap()
+	gitStatus.set("type", "git_status")
+
+	gitDiverged := newYAMLMap()
+	gitDiverged.set("type", "git_diverged")
+	for _, symbol := range []struct{ option, field string }{
+		{"ahead", "aheadSymbol"},
+		{"behind", "behindSymbol"},
+		{"diverged", "divergedSymbol"},
+		{"up_to_date", "upToDateSymbol"},
+	} {
+		value := options.string(symbol.option)
+		if strings.Contains(value, "$") {
+			c.warn("Variables in git_status %s are not supported", symbol.option)
+			value = strings.NewReplacer("${count}", "", "$count", "", "${ahead_count}", "", "${behind_count}", "").Replace(value)
+		}
+		gitDiverged.set(symbol.field, value)
+	}
+	gitDiverged.set("noUpstreamSymbol", "")
+
+	module.set("join", "")
+	module.set("modules", []yamlMap{gitStatus, gitDiverged})
+
+	builder.preamble = "{{ $status := .Data.Modules.git_status.Data }}"
+	status := "$status"
+	statusSymbol := func(option string, condition string) string {
+		return "(ternary " + builder.literal(options, option) + ` "" ` + condition + ")"
+	}
+
+	builder.variables["conflicted"] = statusSymbol("conflicted", "(gt "+status+".Unmerged 0)")
+	builder.variables["stashed"] = statusSymbol("stashed", "(gt "+status+".StashCount 0)")
+	builder.variables["deleted"] = statusSymbol("deleted", "(or (gt "+status+".Index.Deleted 0) (gt "+status+".Unstaged.Deleted 0))")
+	builder.variables["renamed"] = emptyExpression
+	options.raw("renamed")
+	builder.variables["modified"] = statusSymbol("modified", "(gt "+status+".Unstaged.Modified 0)")
+	builder.variables["staged"] = statusSymbol("staged", "(or (gt "+status+".Index.Added 0) (gt "+status+".Index.Modified 0))")
+	builder.variables["untracked"] = statusSymbol("untracked", "(gt "+status+".Unstaged.Added 0)")
+	builder.variables["ahead_behind"] = ".Data.Modules.git_diverged.Data.Symbol"
+
+	allStatus := []string{}
+	for _, name := range []string{"conflicted", "stashed", "deleted", "renamed", "modified", "staged", "untracked"} {
+		if builder.variables[name] != emptyExpression {
+			allStatus = append(allStatus, builder.variables[name])
+		}
+	}
+	builder.variables["all_status"] = "(print " + strings.Join(allStatus, " ") + ")"
+
+	return ".Text"
+}
+
+// convertCustom converts a starship custom module into a kitsch custom module.
+func convertCustom(c *converter, name string, options *options, module yamlMap, builder *templateBuilder) string {
+	// Starship runs commands in a shell, but kitsch runs them directly.
+	command := options.string("command")
+	module.set("command", "sh -c '"+strings.ReplaceAll(command, "'", `'\''`)+"'")
+	if options.isSet("shell") {
+		options.raw("shell")
+		c.warn("%s: shell is not supported; command will be run with sh", name)
+	}
+
+	if when, ok := options.raw("when"); ok && when != true {
+		c.warn("%s: when is not supported", name)
+	}
+	options.raw("description")
+
+	conditions := newYAMLMap()
+	files := append(stringList(options.value("files")), stringList(options.value("directories"))...)
+	if len(files) > 0 {
+		conditions.set("ifFiles", files)
+	}
+	if extensions := stringList(options.value("extensions")); len(extensions) > 0 {
+		conditions.set("ifExtensions", extensions)
+	}
+	if operatingSystem, ok := options.raw("os"); ok {
+		goos := fmt.Sprint(operatingSystem)
+		if goos == "macos" {
+			goos = "darwin"
+		}
+		conditions.set("onlyIfOS", []string{goos})
+	}
+	if len(conditions.node.Content) > 0 {
+		module.set("conditions", conditions)
+	}
+
+	builder.variables["symbol"] = builder.literal(options, "symbol")
+	builder.variables["output"] = ".Text"
+	return ".Text"
+}
+
+// convertLanguageModule converts a starship language module into a kitsch
+// project module.  All language modules are converted into a single project
+// module.
+func (c *converter) convertLanguageModule(name string, projectType string, implicit bool) (yamlMap, bool) {
+	if c.addedProject {
+		return yamlMap{}, false
+	}
+	c.addedProject = true
+
+	module := newYAMLMap()
+	module.set("type", "project")
+
+	// Copy configuration for every language module into the project module.
+	languages := make([]string, 0, len(languageModules))
+	for language := range languageModules {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	projects := newYAMLMap()
+	for _, language := range languages {
+		table := c.table(language)
+		if table == nil {
+			continue
+		}
+
+		options := newOptions(table, map[string]interface{}{})
+		if options.bool("disabled") {
+			c.warn("Can't disable %s; disable the project type instead", language)
+		}
+
+		project := newYAMLMap()
+		if options.isSet("style") {
+			project.set("style", c.convertStyle(options.string("style")))
+		}
+		options.copy("symbol", project, "toolSymbol")
+		if len(project.node.Content) > 0 {
+			projects.set(languageModules[language], project)
+		}
+
+		for _, optionName := range options.unused() {
+			c.warn("Option %s.%s is not supported", language, optionName)
+		}
+	}
+	if len(projects.node.Content) > 0 {
+		module.set("projects", projects)
+	}
+
+	module.set("template", "{{ if .Text }}via {{ .Text }} {{ end }}")
+
+	return module, true
+}
+
+// stringList converts a TOML array into a list of strings.
+func stringList(value interface{}) []string {
+	list, _ := value.([]interface{})
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		if str, ok := item.(string); ok {
+			result = append(result, str)
+		}
+	}
+	return result
+}
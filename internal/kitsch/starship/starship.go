@@ -0,0 +1,653 @@
+// Package starship converts starship (https://starship.rs) configuration files
+// into kitsch configuration files.
+package starship
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultFormat is the default starship format.
+const defaultFormat = "$all"
+
+// allModules is the list of modules, in order, that starship shows for "$all".
+var allModules = []string{
+	"username", "hostname", "localip", "shlvl", "singularity", "kubernetes",
+	"directory", "vcsh", "git_branch", "git_commit", "git_state", "git_metrics",
+	"git_status", "hg_branch", "docker_context", "package", "c", "cmake",
+	"cobol", "daml", "dart", "deno", "dotnet", "elixir", "elm", "erlang",
+	"golang", "haskell", "helm", "java", "julia", "kotlin", "lua", "nim",
+	"nodejs", "ocaml", "perl", "php", "pulumi", "purescript", "python", "raku",
+	"rlang", "red", "ruby", "rust", "scala", "swift", "terraform", "vlang",
+	"vagrant", "zig", "buf", "nix_shell", "conda", "meson", "spack",
+	"memory_usage", "aws", "gcloud", "openstack", "azure", "env_var", "crystal",
+	"custom", "sudo", "cmd_duration", "line_break", "jobs", "battery", "time",
+	"status", "os", "container", "shell", "character",
+}
+
+// Convert converts a starship TOML configuration file into a kitsch YAML
+// configuration file.  Returns the YAML file, and a list of warnings about
+// anything that could not be converted.
+func Convert(data []byte) ([]byte, []string, error) {
+	var starshipConfig map[string]interface{}
+	_, err := toml.Decode(string(data), &starshipConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c := &converter{
+		config:  starshipConfig,
+		palette: map[string]string{},
+	}
+
+	result, err := c.convert()
+	return result, c.warnings, err
+}
+
+// converter holds state while converting a starship configuration.
+type converter struct {
+	// config is the starship configuration.
+	config map[string]interface{}
+	// palette is the starship palette in use, if any.
+	palette map[string]string
+	// explicitModules is the set of modules explicitly mentioned in a format,
+	// which should not be included in "$all".
+	explicitModules map[string]bool
+	// addedProject is true if we've already added a project module.
+	addedProject bool
+	// warnings is a list of warnings generated during conversion.
+	warnings []string
+}
+
+func (c *converter) warn(format string, args ...interface{}) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, args...))
+}
+
+// escapeRunes replaces every character outside the basic multilingual plane
+// in the scalars in `node` with a placeholder.  The YAML encoder escapes these
+// characters as "\UXXXXXXXX", which makes emoji (which are popular in
+// starship configurations) unreadable, so we put them back with
+// `unescapeRunes()` after encoding.  Returns the prefix used for placeholders,
+// which is chosen so it doesn't appear anywhere else in the document.
+func escapeRunes(node *yaml.Node) string {
+	prefix := "__rune"
+	for nodeContains(node, prefix) {
+		prefix += "_"
+	}
+
+	walkNodes(node, func(node *yaml.Node) {
+		if node.Kind != yaml.ScalarNode {
+			return
+		}
+		var value strings.Builder
+		for _, r := range node.Value {
+			if r > 0xFFFF {
+				fmt.Fprintf(&value, "%s%X__", prefix, r)
+			} else {
+				value.WriteRune(r)
+			}
+		}
+		node.Value = value.String()
+	})
+
+	return prefix
+}
+
+// unescapeRunes replaces the placeholders created by `escapeRunes()` in the
+// encoded YAML `data` with the characters they represent.
+func unescapeRunes(data []byte, prefix string) []byte {
+	placeholder := regexp.MustCompile(regexp.QuoteMeta(prefix) + "([0-9A-F]+)__")
+	return placeholder.ReplaceAllFunc(data, func(match []byte) []byte {
+		value, err := strconv.ParseUint(string(placeholder.FindSubmatch(match)[1]), 16, 32)
+		if err != nil {
+			return match
+		}
+		return []byte(string(rune(value)))
+	})
+}
+
+// nodeContains returns true if `text` appears in the value or comments of
+// `node` or any of its children.
+func nodeContains(node *yaml.Node, text string) bool {
+	found := false
+	walkNodes(node, func(node *yaml.Node) {
+		found = found ||
+			strings.Contains(node.Value, text) ||
+			strings.Contains(node.Tag, text) ||
+			strings.Contains(node.Anchor, text) ||
+			strings.Contains(node.HeadComment, text) ||
+			strings.Contains(node.LineComment, text) ||
+			strings.Contains(node.FootComment, text)
+	})
+	return found
+}
+
+// walkNodes calls `fn` for `node` and every node beneath it.
+func walkNodes(node *yaml.Node, fn func(node *yaml.Node)) {
+	if node == nil {
+		return
+	}
+	fn(node)
+	for _, child := range node.Content {
+		walkNodes(child, fn)
+	}
+}
+
+// table returns the configuration table with the given name (e.g. "directory"
+// or "custom.foo"), or nil if there is no such table.
+func (c *converter) table(name string) map[string]interface{} {
+	var current interface{} = c.config
+	for _, part := range strings.Split(name, ".") {
+		currentMap, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = currentMap[part]
+	}
+
+	result, _ := current.(map[string]interface{})
+	return result
+}
+
+func (c *converter) convert() ([]byte, error) {
+	result := newYAMLMap()
+	result.node.HeadComment = "Converted from a starship configuration by `kitsch import starship`."
+
+	options := newOptions(c.config, map[string]interface{}{
+		"format":       defaultFormat,
+		"right_format": "",
+		"add_newline":  true,
+	})
+
+	if timeout, ok := options.raw("command_timeout"); ok {
+		result.set("timeout", timeout)
+	}
+	if timeout, ok := options.raw("scan_timeout"); ok {
+		result.set("scanTimeout", timeout)
+	}
+	if options.isSet("add_newline") && options.bool("add_newline") {
+		c.warn("add_newline is not supported")
+	}
+	// Ignored options.
+	options.raw("$schema")
+	options.raw("follow_symlinks")
+
+	c.convertPalette(options, result)
+
+	// Work out which modules are explicitly placed in a format string, so
+	// we can exclude them from "$all".
+	format := c.parseFormat(options.string("format"), "prompt")
+	rightFormat := c.parseFormat(options.string("right_format"), "right_format")
+	c.explicitModules = map[string]bool{}
+	for _, name := range append(variableNames(format), variableNames(rightFormat)...) {
+		c.explicitModules[name] = true
+	}
+
+	if prompt, ok := c.convertPrompt(format); ok {
+		result.set("prompt", prompt)
+	}
+	if rightPrompt, ok := c.convertPrompt(rightFormat); ok {
+		result.set("rightPrompt", rightPrompt)
+	}
+	if options.isSet("continuation_prompt") {
+		continuationFormat := c.parseFormat(options.string("continuation_prompt"), "continuation_prompt")
+		if continuationPrompt, ok := c.convertPrompt(continuationFormat); ok {
+			result.set("continuationPrompt", continuationPrompt)
+		}
+	}
+
+	// Any top level values that aren't tables and that we haven't used can't
+	// be converted.
+	for _, name := range options.unused() {
+		if _, isTable := c.config[name].(map[string]interface{}); !isTable {
+			c.warn("Option %s is not supported", name)
+		}
+	}
+
+	prefix := escapeRunes(result.node)
+
+	buffer := bytes.Buffer{}
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	err := encoder.Encode(result.node)
+	if err != nil {
+		return nil, err
+	}
+	err = encoder.Close()
+	return unescapeRunes(buffer.Bytes(), prefix), err
+}
+
+// convertPalette converts the starship palette into kitsch custom colors.
+func (c *converter) convertPalette(options *options, result yamlMap) {
+	paletteName := options.string("palette")
+	palettes, _ := options.value("palettes").(map[string]interface{})
+	if paletteName == "" {
+		return
+	}
+
+	palette, ok := palettes[paletteName].(map[string]interface{})
+	if !ok {
+		c.warn("Palette %s not found", paletteName)
+		return
+	}
+
+	names := make([]string, 0, len(palette))
+	for name, value := range palette {
+		if color, ok := value.(string); ok {
+			c.palette[name] = color
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	colors := newYAMLMap()
+	for _, name := range names {
+		// Palette colors can't refer to other palette colors.
+		color, err := c.convertColor(c.palette[name])
+		if err != nil || strings.HasPrefix(color, "$") {
+			c.warn("Palette color %s: unsupported color %q", name, c.palette[name])
+			continue
+		}
+		colors.set("$"+name, color)
+	}
+	result.set("colors", colors)
+}
+
+// parseFormat parses a format string, and reports a warning if it can't be
+// parsed.
+func (c *converter) parseFormat(format string, description string) []formatNode {
+	nodes, err := parseFormat(format)
+	if err != nil {
+		c.warn("Error parsing %s: %v", description, err)
+		return nil
+	}
+	return nodes
+}
+
+// convertPrompt converts a top-level format into a kitsch block module.
+func (c *converter) convertPrompt(format []formatNode) (yamlMap, bool) {
+	modules := c.convertNodes(format, false)
+	if len(modules) == 0 {
+		return yamlMap{}, false
+	}
+
+	prompt := newYAMLMap()
+	prompt.set("type", "block")
+	prompt.set("join", "")
+	prompt.set("modules", modules)
+	return prompt, true
+}
+
+// convertNodes converts nodes from a top-level format into a list of kitsch
+// modules.  If `assignIDs` is true, then every module generated will have
+// an ID.
+func (c *converter) convertNodes(nodes []formatNode, assignIDs bool) []yamlMap {
+	result := []yamlMap{}
+
+	for _, node := range nodes {
+		switch node.kind {
+		case textNode:
+			module := newYAMLMap()
+			module.set("type", "text")
+			module.set("text", node.value)
+			result = append(result, module)
+
+		case variableNode:
+			result = append(result, c.convertVariable(node.value, assignIDs)...)
+
+		case styledGroupNode:
+			modules := c.convertNodes(node.children, assignIDs)
+			if len(modules) == 0 {
+				continue
+			}
+			builder := templateBuilder{converter: c, description: "prompt"}
+			style := builder.style(node.style)
+
+			block := newYAMLMap()
+			block.set("type", "block")
+			if style != "" {
+				unquoted, _ := strconv.Unquote(style)
+				block.set("style", unquoted)
+			}
+			block.set("join", "")
+			block.set("modules", modules)
+			result = append(result, block)
+
+		case conditionalGroupNode:
+			modules := c.convertNodes(node.children, true)
+			if len(modules) == 0 {
+				continue
+			}
+
+			// Only show the block if one of the non-text modules has output.
+			conditions := []string{}
+			for _, module := range modules {
+				if module.get("type") != "text" {
+					conditions = append(conditions, ".Data.Modules."+module.get("id")+".Text")
+				}
+			}
+
+			block := newYAMLMap()
+			block.set("type", "block")
+			block.set("join", "")
+			block.set("modules", modules)
+			if len(conditions) > 0 {
+				block.set("template", "{{ if or "+strings.Join(conditions, " ")+" }}{{ .Text }}{{ end }}")
+			}
+			result = append(result, block)
+		}
+	}
+
+	if assignIDs {
+		for index, module := range result {
+			if module.get("type") != "text" && module.get("id") == "" {
+				module.setID(fmt.Sprintf("%s_%d", module.get("type"), index))
+			}
+		}
+	}
+
+	return result
+}
+
+// convertVariable converts a variable from a top-level format into kitsch
+// modules.
+func (c *converter) convertVariable(name string, assignIDs bool) []yamlMap {
+	result := []yamlMap{}
+
+	switch {
+	case name == "all":
+		for _, moduleName := range allModules {
+			if c.explicitModules[moduleName] {
+				continue
+			}
+			if moduleName == "custom" || moduleName == "env_var" {
+				result = append(result, c.convertVariable(moduleName, assignIDs)...)
+			} else if module, ok := c.convertModule(moduleName, true); ok {
+				result = append(result, module)
+			}
+		}
+
+	case name == "custom":
+		result = append(result, c.convertSubModules("custom")...)
+
+	case name == "env_var" && c.table("env_var")["variable"] == nil:
+		// "env_var" can either be a single module, or a collection of modules.
+		// If it's neither, starship doesn't show anything.
+		result = append(result, c.convertSubModules("env_var")...)
+
+	default:
+		if module, ok := c.convertModule(name, false); ok {
+			result = append(result, module)
+		}
+	}
+
+	return result
+}
+
+// convertSubModules converts all modules in a table of modules (e.g. all the
+// "custom.*" modules) that aren't explicitly placed in a format.
+func (c *converter) convertSubModules(prefix string) []yamlMap {
+	table := c.table(prefix)
+	names := make([]string, 0, len(table))
+	for name := range table {
+		if !c.explicitModules[prefix+"."+name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	result := []yamlMap{}
+	for _, name := range names {
+		if module, ok := c.convertModule(prefix+"."+name, false); ok {
+			result = append(result, module)
+		}
+	}
+	return result
+}
+
+// convertModule converts a starship module into a kitsch module.  If
+// `implicit` is true, this module was included via "$all", and we'll only
+// warn about it being unsupported if it was explicitly configured.
+func (c *converter) convertModule(name string, implicit bool) (yamlMap, bool) {
+	table := c.table(name)
+
+	definitionName := name
+	if index := strings.Index(name, "."); index != -1 {
+		definitionName = name[0:index]
+	}
+
+	if projectType, isLanguage := languageModules[definitionName]; isLanguage {
+		return c.convertLanguageModule(name, projectType, implicit)
+	}
+
+	definition, ok := moduleDefinitions[definitionName]
+	if !ok {
+		if !implicit || table != nil {
+			c.warn("Module %s is not supported", name)
+		}
+		return yamlMap{}, false
+	}
+
+	options := newOptions(table, definition.defaults)
+	if options.bool("disabled") {
+		return yamlMap{}, false
+	}
+
+	module := newYAMLMap()
+	module.set("type", definition.kitschType)
+
+	builder := &templateBuilder{
+		converter:   c,
+		description: name + " module",
+		variables:   map[string]string{},
+	}
+
+	// Every style option is available as a variable.
+	for optionName := range options.all() {
+		if optionName == "style" || strings.HasSuffix(optionName, "_style") {
+			builder.variables[optionName] = strconv.Quote(c.convertStyle(options.string(optionName)))
+		}
+	}
+
+	guard := ".Text"
+	if definition.convert != nil {
+		guard = definition.convert(c, name, options, module, builder)
+	}
+
+	if guard != "" && options.isKnown("format") {
+		format := c.parseFormat(options.string("format"), name+" format")
+		module.set("template", builder.preamble+"{{ if "+guard+" }}"+builder.template(format)+"{{ end }}")
+	}
+
+	for _, optionName := range options.unused() {
+		c.warn("Option %s.%s is not supported", name, optionName)
+	}
+
+	return module, true
+}
+
+// literal converts the value of an option, which may be a format string (e.g.
+// "[❯](bold green)") into a template expression.
+func (builder *templateBuilder) literal(options *options, name string) string {
+	nodes := builder.converter.parseFormat(options.string(name), name)
+	return builder.expression(nodes)
+}
+
+// yamlMap is a helper for building a YAML mapping node, which preserves the
+// order that keys are added in.
+type yamlMap struct {
+	node *yaml.Node
+}
+
+func newYAMLMap() yamlMap {
+	return yamlMap{node: &yaml.Node{Kind: yaml.MappingNode}}
+}
+
+// valueNode converts a value into a YAML node.
+func valueNode(value interface{}) *yaml.Node {
+	switch v := value.(type) {
+	case yamlMap:
+		return v.node
+	case []yamlMap:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range v {
+			node.Content = append(node.Content, item.node)
+		}
+		return node
+	case string:
+		node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+		if strings.Contains(v, "\n") {
+			// Make newlines visible.
+			node.Style = yaml.DoubleQuotedStyle
+		}
+		return node
+	default:
+		node := &yaml.Node{}
+		err := node.Encode(value)
+		if err != nil {
+			return &yaml.Node{Kind: yaml.ScalarNode, Value: fmt.Sprint(value)}
+		}
+		return node
+	}
+}
+
+// set sets the value for the given key.
+func (m yamlMap) set(key string, value interface{}) {
+	for index := 0; index < len(m.node.Content); index += 2 {
+		if m.node.Content[index].Value == key {
+			m.node.Content[index+1] = valueNode(value)
+			return
+		}
+	}
+	m.node.Content = append(m.node.Content, valueNode(key), valueNode(value))
+}
+
+// setID sets the "id" key for a module, right after the "type".
+func (m yamlMap) setID(id string) {
+	content := []*yaml.Node{}
+	for index := 0; index < len(m.node.Content); index += 2 {
+		content = append(content, m.node.Content[index], m.node.Content[index+1])
+		if m.node.Content[index].Value == "type" {
+			content = append(content, valueNode("id"), valueNode(id))
+		}
+	}
+	m.node.Content = content
+}
+
+// get returns the value of a scalar key, or "" if the key is not set.
+func (m yamlMap) get(key string) string {
+	for index := 0; index < len(m.node.Content); index += 2 {
+		if m.node.Content[index].Value == key {
+			return m.node.Content[index+1].Value
+		}
+	}
+	return ""
+}
+
+// options is a set of options for a starship module, along with the defaults
+// for those options.  options keeps track of which options have been used, so
+// we can warn about options that could not be converted.
+type options struct {
+	values   map[string]interface{}
+	defaults map[string]interface{}
+	used     map[string]bool
+}
+
+func newOptions(values map[string]interface{}, defaults map[string]interface{}) *options {
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	return &options{
+		values:   values,
+		defaults: defaults,
+		used:     map[string]bool{"disabled": true},
+	}
+}
+
+// all returns the names of all options that are set, or have defaults.
+func (o *options) all() map[string]bool {
+	result := map[string]bool{}
+	for name := range o.defaults {
+		result[name] = true
+	}
+	for name := range o.values {
+		result[name] = true
+	}
+	return result
+}
+
+// isSet returns true if the given option was set in the configuration.
+func (o *options) isSet(name string) bool {
+	_, ok := o.values[name]
+	return ok
+}
+
+// isKnown returns true if the given option was set or has a default.
+func (o *options) isKnown(name string) bool {
+	_, hasDefault := o.defaults[name]
+	return hasDefault || o.isSet(name)
+}
+
+// raw returns the value of an option, if it was set in the configuration.
+func (o *options) raw(name string) (interface{}, bool) {
+	o.used[name] = true
+	value, ok := o.values[name]
+	return value, ok
+}
+
+// value returns the value of an option, or the default value.
+func (o *options) value(name string) interface{} {
+	if value, ok := o.raw(name); ok {
+		return value
+	}
+	return o.defaults[name]
+}
+
+func (o *options) string(name string) string {
+	value, _ := o.value(name).(string)
+	return value
+}
+
+func (o *options) bool(name string) bool {
+	value, _ := o.value(name).(bool)
+	return value
+}
+
+func (o *options) int(name string) int64 {
+	switch value := o.value(name).(type) {
+	case int64:
+		return value
+	case int:
+		return int64(value)
+	case float64:
+		return int64(value)
+	}
+	return 0
+}
+
+// copy copies an option into a kitsch module, if it was set.
+func (o *options) copy(name string, module yamlMap, field string) {
+	if value, ok := o.raw(name); ok {
+		module.set(field, value)
+	}
+}
+
+// unused returns a sorted list of options which were set in the configuration,
+// but never used.
+func (o *options) unused() []string {
+	result := []string{}
+	for name := range o.values {
+		if !o.used[name] {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,87 @@
+package starship
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/jwalton/kitsch/internal/kitsch/config"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestConvert(t *testing.T) {
+	starshipConfig := heredoc.Doc(`
+		format = "$directory$git_branch$line_break$character"
+		command_timeout = 1000
+
+		[directory]
+		style = "bold purple"
+		truncation_length = 2
+
+		[git_branch]
+		symbol = "🌱 "
+		only_attached = true
+
+		[character]
+		success_symbol = "[➜](bold green)"
+	`)
+
+	result, warnings, err := Convert([]byte(starshipConfig))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Option git_branch.only_attached is not supported"}, warnings)
+	assert.Equal(t, heredoc.Doc(`
+		# Converted from a starship configuration by `+"`kitsch import starship`"+`.
+		timeout: 1000
+		prompt:
+		  type: block
+		  join: ""
+		  modules:
+		    - type: directory
+		      truncationLength: 2
+		      template: '{{ if .Text }}{{ style "bold magenta" .Data.Path }}{{ style "red" (ternary .Data.ReadOnlySymbol "" .Data.ReadOnly) }} {{ end }}'
+		    - type: git_head
+		      template: '{{ if .Text }}on {{ style "bold magenta" (print "🌱 " .Data.Description) }} {{ end }}'
+		    - type: text
+		      text: "\n"
+		    - type: prompt
+		      template: '{{ if .Text }}{{ ternary (style "bold green" "❮") (ternary (style "bold green" "➜") (style "bold red" "❯") (eq .Globals.Status 0)) .Data.ViCmdMode }} {{ end }}'
+	`), string(result))
+
+	assert.Nil(t, config.ValidateConfiguration(result))
+}
+
+func TestConvertDefaultConfig(t *testing.T) {
+	result, _, err := Convert([]byte(""))
+	assert.Nil(t, err)
+	assert.Nil(t, config.ValidateConfiguration(result))
+
+	var kitschConfig config.Config
+	err = kitschConfig.LoadFromYaml(result, true)
+	assert.Nil(t, err)
+}
+
+func TestConvertInvalidToml(t *testing.T) {
+	_, _, err := Convert([]byte("format = "))
+	assert.NotNil(t, err)
+}
+
+func TestEscapeRunes(t *testing.T) {
+	var node yaml.Node
+	err := yaml.Unmarshal([]byte(heredoc.Doc(`
+		text: "🌱 \\U0001F331"
+		path: C:\Users\U0001F331
+		quoted: 'C:\U0001F331 __rune'
+	`)), &node)
+	assert.Nil(t, err)
+
+	prefix := escapeRunes(&node)
+	assert.Equal(t, "__rune_", prefix)
+
+	result, err := yaml.Marshal(&node)
+	assert.Nil(t, err)
+	assert.Equal(t, heredoc.Doc(`
+		text: "🌱 \\U0001F331"
+		path: C:\Users\U0001F331
+		quoted: 'C:\U0001F331 __rune'
+	`), string(unescapeRunes(result, prefix)))
+}
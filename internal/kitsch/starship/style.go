@@ -0,0 +1,189 @@
+package starship
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// starshipModifiers maps starship style modifiers to kitsch modifiers.
+var starshipModifiers = map[string]string{
+	"bold":          "bold",
+	"italic":        "italic",
+	"underline":     "underline",
+	"dimmed":        "dim",
+	"inverted":      "inverse",
+	"hidden":        "hidden",
+	"strikethrough": "strikethrough",
+}
+
+// starshipColors maps starship color names to kitsch color names.
+var starshipColors = map[string]string{
+	"black":  "black",
+	"red":    "red",
+	"green":  "green",
+	"yellow": "yellow",
+	"blue":   "blue",
+	"purple": "magenta",
+	"cyan":   "cyan",
+	"white":  "white",
+}
+
+// ansiColorNames are the kitsch names for the first 16 ANSI colors.
+var ansiColorNames = []string{
+	"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white",
+	"brightBlack", "brightRed", "brightGreen", "brightYellow", "brightBlue", "brightMagenta", "brightCyan", "brightWhite",
+}
+
+// convertStyle converts a starship style string (e.g. "bold fg:purple bg:#202020")
+// into a kitsch style string (e.g. "bold magenta bg:#202020").
+func (c *converter) convertStyle(style string) string {
+	result := []string{}
+
+	for _, token := range strings.Fields(style) {
+		lowerToken := strings.ToLower(token)
+
+		if modifier, ok := starshipModifiers[lowerToken]; ok {
+			result = append(result, modifier)
+			continue
+		}
+
+		switch lowerToken {
+		case "none":
+			continue
+		case "blink":
+			c.warn("Style \"blink\" is not supported")
+			continue
+		}
+
+		prefix := ""
+		color := token
+		if strings.HasPrefix(lowerToken, "fg:") {
+			color = token[3:]
+		} else if strings.HasPrefix(lowerToken, "bg:") {
+			prefix = "bg:"
+			color = token[3:]
+		}
+
+		if strings.ToLower(color) == "none" {
+			continue
+		}
+
+		converted, err := c.convertColor(color)
+		if err != nil {
+			c.warn("%v in style %q", err, style)
+			continue
+		}
+		result = append(result, prefix+converted)
+	}
+
+	return strings.Join(result, " ")
+}
+
+// convertColor converts a starship color into a kitsch color.
+func (c *converter) convertColor(color string) (string, error) {
+	lowerColor := strings.ToLower(color)
+
+	if _, ok := c.palette[color]; ok {
+		return "$" + color, nil
+	}
+
+	if strings.HasPrefix(color, "#") {
+		return color, nil
+	}
+
+	if converted, ok := starshipColors[lowerColor]; ok {
+		return converted, nil
+	}
+
+	if strings.HasPrefix(lowerColor, "bright-") {
+		if converted, ok := starshipColors[lowerColor[len("bright-"):]]; ok {
+			return "bright" + strings.ToUpper(converted[0:1]) + converted[1:], nil
+		}
+	}
+
+	if index, err := strconv.Atoi(color); err == nil && index >= 0 && index <= 255 {
+		return ansi256ToColor(index), nil
+	}
+
+	return "", fmt.Errorf("unknown color %q", color)
+}
+
+// ansi256ToColor converts an ANSI 256 color index into a kitsch color.
+func ansi256ToColor(index int) string {
+	if index < 16 {
+		return ansiColorNames[index]
+	}
+
+	if index >= 232 {
+		gray := 8 + (index-232)*10
+		return fmt.Sprintf("#%02x%02x%02x", gray, gray, gray)
+	}
+
+	levels := []int{0, 95, 135, 175, 215, 255}
+	index -= 16
+	return fmt.Sprintf("#%02x%02x%02x", levels[index/36], levels[(index/6)%6], levels[index%6])
+}
+
+// strftimeLayouts maps strftime directives to golang time layouts.
+var strftimeLayouts = map[byte]string{
+	'a': "Mon",
+	'A': "Monday",
+	'b': "Jan",
+	'B': "January",
+	'd': "02",
+	'D': "01/02/06",
+	'e': "_2",
+	'F': "2006-01-02",
+	'h': "Jan",
+	'H': "15",
+	'I': "03",
+	'm': "01",
+	'M': "04",
+	'p': "PM",
+	'r': "03:04:05 PM",
+	'R': "15:04",
+	'S': "05",
+	'T': "15:04:05",
+	'y': "06",
+	'Y': "2006",
+	'z': "-0700",
+	'Z': "MST",
+	'%': "%",
+}
+
+// unpaddedStrftimeLayouts maps strftime directives with a "-" flag (e.g. "%-m")
+// to golang time layouts.
+var unpaddedStrftimeLayouts = map[byte]string{
+	'd': "2",
+	'I': "3",
+	'm': "1",
+}
+
+// convertTimeFormat converts a strftime style format (e.g. "%T") into a golang
+// time layout (e.g. "15:04:05").
+func (c *converter) convertTimeFormat(format string) string {
+	result := strings.Builder{}
+
+	for index := 0; index < len(format); index++ {
+		if format[index] != '%' || index == len(format)-1 {
+			result.WriteByte(format[index])
+			continue
+		}
+
+		index++
+		layouts := strftimeLayouts
+		if format[index] == '-' && index < len(format)-1 {
+			index++
+			layouts = unpaddedStrftimeLayouts
+		}
+
+		if layout, ok := layouts[format[index]]; ok {
+			result.WriteString(layout)
+		} else {
+			c.warn("Unsupported time format %q in %q", format[index], format)
+		}
+	}
+
+	return result.String()
+}
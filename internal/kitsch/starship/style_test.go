@@ -0,0 +1,45 @@
+package starship
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertStyle(t *testing.T) {
+	c := &converter{palette: map[string]string{"mustard": "#af8700"}}
+
+	assert.Equal(t, "bold magenta", c.convertStyle("bold purple"))
+	assert.Equal(t, "dim inverse brightRed bg:#202020", c.convertStyle("dimmed inverted fg:bright-red bg:#202020"))
+	assert.Equal(t, "$mustard bg:#00af00", c.convertStyle("mustard bg:34"))
+	assert.Equal(t, "brightBlue", c.convertStyle("12 bg:none"))
+	assert.Equal(t, "", c.convertStyle("none"))
+	assert.Nil(t, c.warnings)
+
+	assert.Equal(t, "bold", c.convertStyle("bold blink fg:chartreuse"))
+	assert.Equal(t, []string{
+		`Style "blink" is not supported`,
+		`unknown color "chartreuse" in style "bold blink fg:chartreuse"`,
+	}, c.warnings)
+}
+
+func TestANSI256ToColor(t *testing.T) {
+	assert.Equal(t, "black", ansi256ToColor(0))
+	assert.Equal(t, "brightWhite", ansi256ToColor(15))
+	assert.Equal(t, "#000000", ansi256ToColor(16))
+	assert.Equal(t, "#ff8700", ansi256ToColor(208))
+	assert.Equal(t, "#080808", ansi256ToColor(232))
+	assert.Equal(t, "#eeeeee", ansi256ToColor(255))
+}
+
+func TestConvertTimeFormat(t *testing.T) {
+	c := &converter{}
+
+	assert.Equal(t, "15:04:05", c.convertTimeFormat("%T"))
+	assert.Equal(t, "2006-01-02 3:04 PM", c.convertTimeFormat("%Y-%m-%d %-I:%M %p"))
+	assert.Equal(t, "100%", c.convertTimeFormat("100%%"))
+	assert.Nil(t, c.warnings)
+
+	assert.Equal(t, "15", c.convertTimeFormat("%H%j"))
+	assert.Equal(t, []string{`Unsupported time format 'j' in "%H%j"`}, c.warnings)
+}
@@ -0,0 +1,166 @@
+package styling
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/jwalton/gchalk"
+	"github.com/jwalton/gchalk/pkg/ansistyles"
+	"github.com/jwalton/kitsch/internal/colortools"
+	"gopkg.in/yaml.v3"
+)
+
+// CustomColor is a custom color, along with optional colors to use instead
+// on terminals which don't support truecolor.  In YAML, a CustomColor can be
+// either a color string, or an object like:
+//
+//	truecolor: "#20a0ff"
+//	ansi256: 39
+//	ansi: brightBlue
+type CustomColor struct {
+	// Truecolor is the color to use on terminals that support truecolor.  This
+	// can be any color, or a linear-gradient.
+	Truecolor string `yaml:"truecolor"`
+	// Ansi256 is the ANSI 256 color code to use on terminals that only support
+	// 256 colors.  If empty, the closest color in the ANSI 256 palette is used.
+	Ansi256 string `yaml:"ansi256"`
+	// Ansi is the name of the basic ANSI color (e.g. "brightBlue") to use on
+	// terminals that only support 16 colors.  If empty, the closest basic ANSI
+	// color is used.
+	Ansi string `yaml:"ansi"`
+}
+
+// UnmarshalYAML unmarshals a CustomColor from a string or an object.
+func (customColor *CustomColor) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*customColor = CustomColor{Truecolor: node.Value}
+		return nil
+	}
+
+	type rawCustomColor CustomColor
+	var raw rawCustomColor
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+	*customColor = CustomColor(raw)
+	return nil
+}
+
+// hasFallbacks returns true if this color has any fallback colors.
+func (customColor CustomColor) hasFallbacks() bool {
+	return customColor.Ansi256 != "" || customColor.Ansi != ""
+}
+
+// ValidateFallbacks returns an error if the `ansi256` or `ansi` fallback
+// colors are invalid.
+func (customColor CustomColor) ValidateFallbacks() error {
+	if customColor.Ansi256 != "" {
+		if _, err := parseAnsi256(customColor.Ansi256); err != nil {
+			return err
+		}
+	}
+	if customColor.Ansi != "" {
+		if _, ok := ansistyles.Color[customColor.Ansi]; !ok {
+			return fmt.Errorf("invalid ansi color %q", customColor.Ansi)
+		}
+	}
+	return nil
+}
+
+func parseAnsi256(code string) (uint8, error) {
+	value, err := strconv.ParseUint(code, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ansi256 color %q", code)
+	}
+	return uint8(value), nil
+}
+
+// ansiStyleName converts an ANSI color name into the name gchalk uses for
+// the color (e.g. "red" or "bgRed").
+func ansiStyleName(name string, background bool) string {
+	if background {
+		return "bg" + strings.ToUpper(name[0:1]) + name[1:]
+	}
+	return name
+}
+
+// hasFallbackFor returns true if this color has a fallback color for the
+// given color level.
+func (customColor CustomColor) hasFallbackFor(level gchalk.ColorLevel) bool {
+	switch level {
+	case gchalk.LevelAnsi256:
+		return customColor.Ansi256 != ""
+	case gchalk.LevelBasic:
+		return customColor.Ansi != ""
+	default:
+		return false
+	}
+}
+
+// withFallback returns a builder which adds the fallback color for the
+// builder's color level.  Returns false if `fallbacks` has no fallback for
+// the builder's color level.
+func withFallback(
+	builder *gchalk.Builder,
+	fallbacks CustomColor,
+	background bool,
+) (*gchalk.Builder, bool, error) {
+	if !fallbacks.hasFallbackFor(builder.GetLevel()) {
+		return builder, false, nil
+	}
+
+	if builder.GetLevel() == gchalk.LevelAnsi256 {
+		code, err := parseAnsi256(fallbacks.Ansi256)
+		if err != nil {
+			return nil, true, err
+		}
+		if background {
+			return builder.WithBgAnsi256(code), true, nil
+		}
+		return builder.WithAnsi256(code), true, nil
+	}
+
+	if _, ok := ansistyles.Color[fallbacks.Ansi]; !ok {
+		return nil, true, fmt.Errorf("invalid ansi color %q", fallbacks.Ansi)
+	}
+	result, err := builder.WithStyle(ansiStyleName(fallbacks.Ansi, background))
+	return result, true, err
+}
+
+// withColor returns a builder which adds the given color.  On terminals that
+// don't support truecolor, this uses the fallback color for the terminal's
+// color level if one is set, or the perceptually closest color otherwise.
+func withColor(
+	builder *gchalk.Builder,
+	c color.RGBA,
+	fallbacks CustomColor,
+	background bool,
+) (*gchalk.Builder, error) {
+	if result, ok, err := withFallback(builder, fallbacks, background); ok {
+		return result, err
+	}
+
+	switch builder.GetLevel() {
+	case gchalk.LevelAnsi256:
+		code := colortools.NearestAnsi256(c)
+		if background {
+			return builder.WithBgAnsi256(code), nil
+		}
+		return builder.WithAnsi256(code), nil
+
+	case gchalk.LevelBasic:
+		code := colortools.NearestAnsi(c)
+		if background {
+			return builder.WithBgAnsi(code), nil
+		}
+		return builder.WithAnsi(code), nil
+
+	default:
+		if background {
+			return builder.WithBgRGB(c.R, c.G, c.B), nil
+		}
+		return builder.WithRGB(c.R, c.G, c.B), nil
+	}
+}
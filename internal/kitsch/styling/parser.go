@@ -8,15 +8,31 @@ import (
 	colortools "github.com/jwalton/kitsch/internal/colortools"
 )
 
-const linearGradientPrefix = "linear-gradient("
+const (
+	linearGradientPrefix          = "linear-gradient("
+	repeatingLinearGradientPrefix = "repeating-linear-gradient("
+	autoFgPrefix                  = "fg:auto"
+)
 
 type styleDescriptor struct {
 	// fg is the foreground color of this style.  This can be any string that
 	// `gchalk.Style()` accepts (e.g. "red", "brightBlack"), a hex string
-	// (e.g. "#2080ff"), or a CSS style linear-gradient.
+	// (e.g. "#2080ff"), or a CSS style linear-gradient or
+	// repeating-linear-gradient.
 	fg string
 	// bg is the background color of this style.
 	bg string
+	// fgCustomColor is the name of the custom color `fg` came from, if any.
+	fgCustomColor string
+	// bgCustomColor is the name of the custom color `bg` came from, if any.
+	bgCustomColor string
+	// autoFg is true if the foreground color should be picked automatically,
+	// to contrast with the background ("fg:auto").
+	autoFg bool
+	// autoFgArgs are the arguments passed to "fg:auto(...)".  Each argument
+	// is either a contrast algorithm ("wcag" or "apca") or a color to pick
+	// from.  Custom colors have already been replaced with their values.
+	autoFgArgs []string
 	// modifiers is an array of modifiers (e.g. "bold").  These can be any
 	// modifier accepted by `gchalk.Style()`.
 	modifiers []string
@@ -78,8 +94,17 @@ func (parser *styleParser) nextToken() (token string, err error) {
 
 		// If there's a "(", read until the matching ")" and include it in the token.
 		if parser.position < len(parser.styleString) && parser.styleString[parser.position] == '(' {
+			depth := 1
 			parser.position++
-			for parser.position < len(parser.styleString) && parser.styleString[parser.position] != ')' {
+			for parser.position < len(parser.styleString) {
+				if parser.styleString[parser.position] == '(' {
+					depth++
+				} else if parser.styleString[parser.position] == ')' {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
 				parser.position++
 			}
 
@@ -92,20 +117,29 @@ func (parser *styleParser) nextToken() (token string, err error) {
 	return parser.styleString[tokenStart:parser.position], nil
 }
 
-// isColor returns true if the given string is a color - eiter .
-func isColor(color string) bool {
+// IsColor returns true if the given string is a color or a linear-gradient.
+// Colors can be ANSI color names, hex colors, CSS color names, or CSS color
+// functions like "hsl(200 80% 50%)".
+func IsColor(color string) bool {
 	_, validAnsiStyle := ansistyles.Color[color]
 	if validAnsiStyle {
 		return true
 	}
 
-	if strings.HasPrefix(color, linearGradientPrefix) {
+	if isGradient(color) {
 		return true
 	}
 
 	return colortools.ValidateColor(color)
 }
 
+// isGradient returns true if the given string is a linear-gradient or a
+// repeating-linear-gradient.
+func isGradient(color string) bool {
+	return strings.HasPrefix(color, linearGradientPrefix) ||
+		strings.HasPrefix(color, repeatingLinearGradientPrefix)
+}
+
 // parseStyleToken adds a token to the style descriptor.  The token can be
 // a color, a modifier, or a linear-gradient.
 func parseStyleToken(
@@ -124,18 +158,29 @@ func parseStyleTokenHelper(
 	token string,
 	isBackground bool,
 ) error {
-	if color, isBg := isBgColor(token); isBg {
+	if strings.HasPrefix(token, autoFgPrefix) && !isBackground {
+		// Handle case where `token` is "fg:auto" or "fg:auto(...)".
+		args, err := parseAutoFgArgs(customColors, token[len(autoFgPrefix):])
+		if err != nil {
+			return err
+		}
+		descriptor.fg = ""
+		descriptor.fgCustomColor = ""
+		descriptor.autoFg = true
+		descriptor.autoFgArgs = args
+	} else if color, isBg := isBgColor(token); isBg {
 		// Handle case where `token` starts with "bg:" or "bg".
 		err := parseStyleTokenHelper(customColors, descriptor, color, true)
 		if err != nil {
 			return fmt.Errorf("unknown style \"%s\"", token)
 		}
-	} else if isColor(token) {
+	} else if IsColor(token) {
 		// Handle case where `token` is a color.
 		if isBackground {
 			descriptor.bg = token
 		} else {
 			descriptor.fg = token
+			descriptor.autoFg = false
 		}
 	} else if _, ok := ansistyles.Modifier[token]; ok {
 		// Handle case where `token` is a modifier.
@@ -144,8 +189,11 @@ func parseStyleTokenHelper(
 		// Handle case where token is a custom color.
 		if isBackground {
 			descriptor.bg = color
+			descriptor.bgCustomColor = token
 		} else {
 			descriptor.fg = color
+			descriptor.fgCustomColor = token
+			descriptor.autoFg = false
 		}
 	} else {
 		return fmt.Errorf("unknown style \"%s\"", token)
@@ -153,3 +201,51 @@ func parseStyleTokenHelper(
 
 	return nil
 }
+
+// parseAutoFgArgs parses the arguments to "fg:auto".  `args` is everything
+// after "fg:auto" in the token (e.g. "(apca, #222, #eee)").
+func parseAutoFgArgs(customColors map[string]string, args string) ([]string, error) {
+	if args == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(args, "(") || !strings.HasSuffix(args, ")") {
+		return nil, fmt.Errorf("unknown style \"%s%s\"", autoFgPrefix, args)
+	}
+
+	result := []string{}
+	for _, arg := range splitArguments(args[1 : len(args)-1]) {
+		arg = strings.TrimSpace(arg)
+		if color, ok := customColors[arg]; ok {
+			arg = color
+		}
+
+		if arg != "wcag" && arg != "apca" && (!IsColor(arg) || isGradient(arg)) {
+			return nil, fmt.Errorf("invalid %s argument \"%s\"", autoFgPrefix, arg)
+		}
+		result = append(result, arg)
+	}
+
+	return result, nil
+}
+
+// splitArguments splits a comma separated list of arguments.  Commas inside
+// parentheses, like those in "rgb(0, 0, 0)", do not split arguments.
+func splitArguments(str string) []string {
+	result := []string{}
+	depth := 0
+	start := 0
+	for index := 0; index < len(str); index++ {
+		switch str[index] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				result = append(result, str[start:index])
+				start = index + 1
+			}
+		}
+	}
+	return append(result, str[start:])
+}
@@ -43,6 +43,21 @@ func TestParseStyle(t *testing.T) {
 	assert.EqualError(t, err, "unknown style \"bg:banana\"")
 }
 
+func TestParseStyleColorFunctions(t *testing.T) {
+	customColors := map[string]string{}
+
+	style, err := parseStyle(customColors, "hsl(200 80% 50%) bg:rgb(0, 0, 0) bold")
+	assert.Nil(t, err, "err should be nil")
+	assert.Equal(t, styleDescriptor{fg: "hsl(200 80% 50%)", bg: "rgb(0, 0, 0)", modifiers: []string{"bold"}}, style)
+
+	style, err = parseStyle(customColors, "linear-gradient(hsl(200 80% 50%), oklch(70% 0.1 250)) bold")
+	assert.Nil(t, err, "err should be nil")
+	assert.Equal(t, styleDescriptor{fg: "linear-gradient(hsl(200 80% 50%), oklch(70% 0.1 250))", modifiers: []string{"bold"}}, style)
+
+	_, err = parseStyle(customColors, "hsl(200 80%)")
+	assert.EqualError(t, err, "unknown style \"hsl(200 80%)\"")
+}
+
 func TestCustomColors(t *testing.T) {
 	customColors := map[string]string{
 		"$foreground": "#fff",
@@ -53,20 +68,40 @@ func TestCustomColors(t *testing.T) {
 
 	style, err := parseStyle(customColors, "$red")
 	assert.Nil(t, err, "err should be nil")
-	assert.Equal(t, styleDescriptor{fg: "blue", bg: "", modifiers: nil}, style)
+	assert.Equal(t, styleDescriptor{fg: "blue", bg: "", fgCustomColor: "$red", modifiers: nil}, style)
 
 	style, err = parseStyle(customColors, "bg:$red")
 	assert.Nil(t, err, "err should be nil")
-	assert.Equal(t, styleDescriptor{fg: "", bg: "blue", modifiers: nil}, style)
+	assert.Equal(t, styleDescriptor{fg: "", bg: "blue", bgCustomColor: "$red", modifiers: nil}, style)
 
 	style, err = parseStyle(customColors, "$foreground bg:$background")
 	assert.Nil(t, err, "err should be nil")
-	assert.Equal(t, styleDescriptor{fg: "#fff", bg: "#000", modifiers: nil}, style)
+	assert.Equal(t, styleDescriptor{
+		fg:            "#fff",
+		bg:            "#000",
+		fgCustomColor: "$foreground",
+		bgCustomColor: "$background",
+	}, style)
 
 	style, err = parseStyle(customColors, "$gradient")
 	assert.Nil(t, err, "err should be nil")
-	assert.Equal(t, styleDescriptor{fg: "linear-gradient(#f00, #00f)", bg: "", modifiers: nil}, style)
+	assert.Equal(t, styleDescriptor{fg: "linear-gradient(#f00, #00f)", bg: "", fgCustomColor: "$gradient", modifiers: nil}, style)
 
 	_, err = parseStyle(customColors, "$banana")
 	assert.EqualError(t, err, "unknown style \"$banana\"")
 }
+
+func TestParseStyleAutoFg(t *testing.T) {
+	customColors := map[string]string{"$light": "#eee"}
+
+	style, err := parseStyle(customColors, "fg:auto bg:blue")
+	assert.Nil(t, err, "err should be nil")
+	assert.Equal(t, styleDescriptor{bg: "blue", autoFg: true}, style)
+
+	style, err = parseStyle(customColors, "fg:auto(apca, rgb(0, 0, 0), $light)")
+	assert.Nil(t, err, "err should be nil")
+	assert.Equal(t, styleDescriptor{autoFg: true, autoFgArgs: []string{"apca", "rgb(0, 0, 0)", "#eee"}}, style)
+
+	_, err = parseStyle(customColors, "fg:autobanana")
+	assert.EqualError(t, err, "unknown style \"fg:autobanana\"")
+}
@@ -3,6 +3,7 @@
 package styling
 
 import (
+	"fmt"
 	"image/color"
 	"strings"
 
@@ -20,6 +21,9 @@ type Style struct {
 	builder    *gchalk.Builder
 	fgGradient ansigradient.Gradient
 	bgGradient ansigradient.Gradient
+	// autoFg picks the foreground color for each character, if this style
+	// uses "fg:auto" and the background isn't a solid color.
+	autoFg *ansigradient.AutoForeground
 }
 
 // CharacterColors represent the color for a single character.
@@ -34,6 +38,8 @@ type CharacterColors struct {
 func compileStyle(
 	baseBuilder *gchalk.Builder,
 	customColors map[string]string,
+	fallbacks map[string]CustomColor,
+	background color.RGBA,
 	styleString string,
 ) (Style, error) {
 	descriptor, err := parseStyle(customColors, styleString)
@@ -46,45 +52,68 @@ func compileStyle(
 	var fgGradient ansigradient.Gradient
 	var bgGradient ansigradient.Gradient
 
-	compileColor := func(token string, background bool) error {
+	compileColor := func(token string, customColor string, background bool) error {
 		var err error
 
 		if token == "" {
 			return nil
 		}
 
+		// If the custom color has a fallback for this terminal, use it as a
+		// solid color, even if the truecolor value is a gradient or an ANSI
+		// color name.
+		var hasFallback bool
+		builder, hasFallback, err = withFallback(builder, fallbacks[customColor], background)
+		if hasFallback {
+			return err
+		}
+
 		if _, validAnsiStyle := ansistyles.Color[token]; validAnsiStyle {
+			builder, err = builder.WithStyle(ansiStyleName(token, background))
+		} else if isGradient(token) {
 			if background {
-				// TODO: Must be a better way!  :/
-				token = "bg" + strings.ToUpper(token[0:1]) + token[1:]
-			}
-			builder, err = builder.WithStyle(token)
-		} else if strings.HasPrefix(token, linearGradientPrefix) {
-			cssGradient := token[len(linearGradientPrefix) : len(token)-1]
-			if background {
-				bgGradient, err = ansigradient.CSSLinearGradientWithMap(customColors, cssGradient)
+				bgGradient, err = compileGradient(customColors, token)
 			} else {
-				fgGradient, err = ansigradient.CSSLinearGradientWithMap(customColors, cssGradient)
+				fgGradient, err = compileGradient(customColors, token)
 			}
 		} else {
 			var c color.RGBA
 			c, err = colortools.ParseColor(token)
-			if background {
-				builder = builder.WithBgRGB(c.R, c.G, c.B)
-			} else {
-				builder = builder.WithRGB(c.R, c.G, c.B)
+			if err == nil {
+				builder, err = withColor(builder, c, fallbacks[customColor], background)
 			}
 		}
 
 		return err
 	}
 
-	err = compileColor(descriptor.bg, true)
+	err = compileColor(descriptor.bg, descriptor.bgCustomColor, true)
 	if err != nil {
 		return Style{}, err
 	}
 
-	err = compileColor(descriptor.fg, false)
+	var autoFg *ansigradient.AutoForeground
+	if descriptor.autoFg {
+		autoFg, err = compileAutoFg(descriptor.autoFgArgs, background)
+		if err != nil {
+			return Style{}, err
+		}
+
+		level := builder.GetLevel()
+		bgFallbacks := fallbacks[descriptor.bgCustomColor]
+		if descriptor.bg != "" && (!isGradient(descriptor.bg) || bgFallbacks.hasFallbackFor(level)) {
+			// The background is a solid color, so we can pick the foreground
+			// color now.
+			bg, err := displayedColor(descriptor.bg, bgFallbacks, level)
+			if err != nil {
+				return Style{}, err
+			}
+			descriptor.fg = colortools.ColorToHex(autoFg.ColorFor(bg, level))
+			autoFg = nil
+		}
+	}
+
+	err = compileColor(descriptor.fg, descriptor.fgCustomColor, false)
 	if err != nil {
 		return Style{}, err
 	}
@@ -101,9 +130,71 @@ func compileStyle(
 		builder:    builder,
 		fgGradient: fgGradient,
 		bgGradient: bgGradient,
+		autoFg:     autoFg,
 	}, nil
 }
 
+// compileAutoFg creates an AutoForeground from the arguments to "fg:auto".
+// `background` is the background color to assume for text which doesn't
+// have one.
+func compileAutoFg(args []string, background color.RGBA) (*ansigradient.AutoForeground, error) {
+	result := &ansigradient.AutoForeground{Background: background}
+
+	for _, arg := range args {
+		switch arg {
+		case "wcag":
+			result.Algorithm = colortools.WCAG
+		case "apca":
+			result.Algorithm = colortools.APCA
+		default:
+			c, err := displayedColor(arg, CustomColor{}, gchalk.LevelAnsi16m)
+			if err != nil {
+				return nil, err
+			}
+			result.Candidates = append(result.Candidates, c)
+		}
+	}
+
+	return result, nil
+}
+
+// displayedColor returns the RGB value of a solid color, as it will be shown
+// on a terminal with the given color level.  ANSI color names are converted
+// using the default xterm colors.
+func displayedColor(token string, fallbacks CustomColor, level gchalk.ColorLevel) (color.RGBA, error) {
+	switch {
+	case level == gchalk.LevelAnsi256 && fallbacks.Ansi256 != "":
+		code, err := parseAnsi256(fallbacks.Ansi256)
+		if err != nil {
+			return color.RGBA{}, err
+		}
+		return colortools.Ansi256ToRGBA(code), nil
+	case level == gchalk.LevelBasic && fallbacks.Ansi != "":
+		token = fallbacks.Ansi
+	}
+
+	if pair, ok := ansistyles.Color[token]; ok {
+		code := strings.TrimSuffix(strings.TrimPrefix(pair.Open, "\u001b["), "m")
+		if c, ok := colortools.ParseSGRColor(code); ok {
+			return c, nil
+		}
+		return color.RGBA{}, fmt.Errorf("unknown color %q", token)
+	}
+
+	return colortools.ParseColor(token)
+}
+
+// compileGradient compiles a linear-gradient or repeating-linear-gradient.
+func compileGradient(customColors map[string]string, token string) (ansigradient.Gradient, error) {
+	if strings.HasPrefix(token, repeatingLinearGradientPrefix) {
+		cssGradient := token[len(repeatingLinearGradientPrefix) : len(token)-1]
+		return ansigradient.CSSRepeatingLinearGradientWithMap(customColors, cssGradient)
+	}
+
+	cssGradient := token[len(linearGradientPrefix) : len(token)-1]
+	return ansigradient.CSSLinearGradientWithMap(customColors, cssGradient)
+}
+
 // Apply applies this style to the given text.
 func (style *Style) Apply(text string) string {
 	result, _, _ := style.ApplyGetColors(text)
@@ -112,6 +203,17 @@ func (style *Style) Apply(text string) string {
 
 // ApplyGetColors applies this style to the given text, and returns the first and last colors of the styled text.
 func (style *Style) ApplyGetColors(text string) (result string, first CharacterColors, last CharacterColors) {
+	return style.applyGetColors(text, false)
+}
+
+// ApplyOverGetColors is like ApplyGetColors, but any gradients in this style
+// will replace the colors already present in the text, so the gradient runs
+// continuously across the whole text.
+func (style *Style) ApplyOverGetColors(text string) (result string, first CharacterColors, last CharacterColors) {
+	return style.applyGetColors(text, true)
+}
+
+func (style *Style) applyGetColors(text string, over bool) (result string, first CharacterColors, last CharacterColors) {
 	if style == nil {
 		return text, first, last
 	}
@@ -121,12 +223,27 @@ func (style *Style) ApplyGetColors(text string) (result string, first CharacterC
 	}
 
 	printWidth := 0
-	if style.fgGradient != nil || style.bgGradient != nil {
+	if style.autoFg != nil {
+		if over {
+			result, printWidth = ansigradient.ApplyAutoForegroundOverRawLen(text, style.autoFg, style.bgGradient, gchalk.GetLevel())
+		} else {
+			result, printWidth = ansigradient.ApplyAutoForegroundRawLen(text, style.autoFg, style.bgGradient, gchalk.GetLevel())
+		}
+	} else if style.fgGradient != nil || style.bgGradient != nil {
 		// TODO: This instance of gchalk is not the same instance as the one from the styleRegistry.
-		result, printWidth = ansigradient.ApplyGradientsRawLen(text, style.fgGradient, style.bgGradient, gchalk.GetLevel())
+		if over {
+			result, printWidth = ansigradient.ApplyGradientsOverRawLen(text, style.fgGradient, style.bgGradient, gchalk.GetLevel())
+		} else {
+			result, printWidth = ansigradient.ApplyGradientsRawLen(text, style.fgGradient, style.bgGradient, gchalk.GetLevel())
+		}
 	}
 
 	first.FG, last.FG = getCharacterColors(style.descriptor.fg, style.fgGradient, printWidth)
+	if style.autoFg != nil && style.bgGradient != nil {
+		level := gchalk.GetLevel()
+		first.FG = colortools.ColorToHex(style.autoFg.ColorFor(style.bgGradient.ColorAt(printWidth, -1), level))
+		last.FG = colortools.ColorToHex(style.autoFg.ColorFor(style.bgGradient.ColorAt(printWidth, printWidth+1), level))
+	}
 	first.BG, last.BG = getCharacterColors(style.descriptor.bg, style.bgGradient, printWidth)
 	if first.BG != "" {
 		first.BG = "bg:" + first.BG
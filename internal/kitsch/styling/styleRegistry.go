@@ -2,6 +2,7 @@ package styling
 
 import (
 	"fmt"
+	"image/color"
 	"strings"
 	"sync"
 
@@ -17,7 +18,14 @@ type Registry struct {
 	// if CustomColors["$foregroud"] = "red", then "$foreground" could be used in
 	// a style string to refer to the color red.  Custom colors must start with
 	// a "$".
-	CustomColors   map[string]string
+	CustomColors map[string]string
+	// Background is the terminal's background color.  "fg:auto" picks a
+	// color which contrasts with this for text that has no background color
+	// of its own.  The zero value is black.
+	Background color.RGBA
+	// fallbacks is a map of custom color names to the colors to use in place
+	// of the custom color on terminals which don't support truecolor.
+	fallbacks      map[string]CustomColor
 	styles         map[string]*Style
 	gchalkInstance *gchalk.Builder
 }
@@ -34,6 +42,37 @@ func (registry *Registry) AddCustomColor(name string, color string) {
 	registry.CustomColors[name] = color
 }
 
+// AddCustomColorWithFallbacks registers a custom color with the registry,
+// along with fallback colors to use on terminals that don't support truecolor.
+// The color name must start with a "$".
+func (registry *Registry) AddCustomColorWithFallbacks(name string, color CustomColor) {
+	registry.AddCustomColor(name, color.Truecolor)
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	if color.hasFallbacks() {
+		if registry.fallbacks == nil {
+			registry.fallbacks = map[string]CustomColor{}
+		}
+		registry.fallbacks[name] = color
+	} else {
+		delete(registry.fallbacks, name)
+	}
+}
+
+// AddCustomColorsWithFallbacks adds a collection of custom colors, with
+// optional fallbacks, to the registry.
+func (registry *Registry) AddCustomColorsWithFallbacks(colors map[string]CustomColor) {
+	for colorName, color := range colors {
+		if !strings.HasPrefix(colorName, "$") {
+			log.Warn("Custom color \"" + colorName + "must start with $")
+		} else {
+			registry.AddCustomColorWithFallbacks(colorName, color)
+		}
+	}
+}
+
 // AddCustomColors adds a collection of custom colors to the registry.
 func (registry *Registry) AddCustomColors(colors map[string]string) {
 	for colorName, color := range colors {
@@ -55,12 +94,18 @@ func (registry *Registry) AddCustomColors(colors map[string]string) {
 //
 // • A hex color code (e.g. "#FFF" or "#320fc9").
 //
-// • A CSS style linear-gradient (e.g. "linear-gradient(#f00, #00f)".
+// • A CSS style linear-gradient (e.g. "linear-gradient(#f00, #00f)", or
+// repeating-linear-gradient (e.g. "repeating-linear-gradient(#f00, #00f 4px)").
 //
-// • A custom color (e.g. "$foreground").
+// • A custom color (e.g. "$foreground").  If the terminal doesn't support
+// truecolor, then the custom color's fallbacks will be used.
 //
 // • Any of the above, but starting with "bg:" to style the background.
 //
+// • "fg:auto", to pick a foreground color for each character which contrasts
+// with that character's background.  This can be given a contrast algorithm
+// and colors to pick from (e.g. "fg:auto(apca, #222, #eee)").
+//
 // • Any modifier accepted by `gchalk.Style()` (e.g. "bold", "dim", "inverse").
 //
 func (registry *Registry) Get(styleString string) (*Style, error) {
@@ -84,7 +129,7 @@ func (registry *Registry) Get(styleString string) (*Style, error) {
 		registry.styles = map[string]*Style{}
 	}
 
-	style, err := compileStyle(registry.gchalkInstance, registry.CustomColors, styleString)
+	style, err := compileStyle(registry.gchalkInstance, registry.CustomColors, registry.fallbacks, registry.Background, styleString)
 	if err != nil {
 		return nil, fmt.Errorf("error compiling style \"%s\": %w", styleString, err)
 	}
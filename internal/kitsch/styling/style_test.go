@@ -1,6 +1,7 @@
 package styling
 
 import (
+	"image/color"
 	"testing"
 
 	"github.com/jwalton/gchalk"
@@ -37,6 +38,10 @@ func TestApply(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "\u001b[38;2;255;69;0mtest\u001b[39m", style.Apply("test"))
 
+	style, err = styles.Get("hsl(120 100% 50%) bg:rgb(0 0 255)")
+	assert.NoError(t, err)
+	assert.Equal(t, "\u001b[48;2;0;0;255m\u001b[38;2;0;255;0mtest\u001b[39m\u001b[49m", style.Apply("test"))
+
 	styles.AddCustomColor("$foreground", "white")
 	style, err = styles.Get("$foreground")
 	assert.NoError(t, err)
@@ -65,6 +70,19 @@ func TestGradient(t *testing.T) {
 		err,
 		`error compiling style "linear-gradient(bananajoe, blue)": invalid color "bananajoe" at 0`,
 	)
+
+	_, err = styles.Get("linear-gradient(in oklch longer hue, $blue, #fff)")
+	assert.NoError(t, err)
+
+	_, err = styles.Get("bg:repeating-linear-gradient($blue, #fff 4px)")
+	assert.NoError(t, err)
+	assert.True(t, IsColor("repeating-linear-gradient(#000, #fff 4px)"))
+
+	_, err = styles.Get("linear-gradient(in lab, $blue, #fff)")
+	assert.EqualError(t,
+		err,
+		`error compiling style "linear-gradient(in lab, $blue, #fff)": unknown color space "lab"`,
+	)
 }
 
 func TestAddCustomColors(t *testing.T) {
@@ -77,3 +95,172 @@ func TestAddCustomColors(t *testing.T) {
 	_, err := styles.Get("$blue")
 	assert.NoError(t, err)
 }
+
+func TestCustomColorFallbacks(t *testing.T) {
+	gchalkInstance := gchalk.New()
+	gchalkInstance.SetLevel(gchalk.LevelAnsi256)
+	styles := &Registry{gchalkInstance: gchalkInstance}
+
+	styles.AddCustomColorWithFallbacks("$git", CustomColor{Truecolor: "#20a0ff", Ansi256: "39", Ansi: "brightBlue"})
+	styles.AddCustomColorWithFallbacks("$nofallback", CustomColor{Truecolor: "#ff0020"})
+
+	style, err := styles.Get("$git")
+	assert.NoError(t, err)
+	assert.Equal(t, "\u001b[38;5;39mtest\u001b[39m", style.Apply("test"))
+
+	style, err = styles.Get("bg:$git")
+	assert.NoError(t, err)
+	assert.Equal(t, "\u001b[48;5;39mtest\u001b[49m", style.Apply("test"))
+
+	// Should use the nearest color when there's no fallback.
+	style, err = styles.Get("$nofallback")
+	assert.NoError(t, err)
+	assert.Equal(t, "\u001b[38;5;196mtest\u001b[39m", style.Apply("test"))
+
+	gchalkInstance = gchalk.New()
+	gchalkInstance.SetLevel(gchalk.LevelBasic)
+	styles = &Registry{gchalkInstance: gchalkInstance}
+	styles.AddCustomColorWithFallbacks("$git", CustomColor{Truecolor: "#20a0ff", Ansi256: "39", Ansi: "brightBlue"})
+	styles.AddCustomColorWithFallbacks("$nofallback", CustomColor{Truecolor: "#ff0020"})
+
+	style, err = styles.Get("bg:$git")
+	assert.NoError(t, err)
+	assert.Equal(t, "\u001b[104mtest\u001b[49m", style.Apply("test"))
+
+	style, err = styles.Get("$nofallback")
+	assert.NoError(t, err)
+	assert.Equal(t, "\u001b[91mtest\u001b[39m", style.Apply("test"))
+
+	// Truecolor terminals should ignore the fallbacks.
+	styles = testStyleRegistry()
+	styles.AddCustomColorWithFallbacks("$git", CustomColor{Truecolor: "#20a0ff", Ansi256: "39", Ansi: "brightBlue"})
+	style, err = styles.Get("$git")
+	assert.NoError(t, err)
+	assert.Equal(t, "\u001b[38;2;32;160;255mtest\u001b[39m", style.Apply("test"))
+}
+
+func TestCustomColorFallbacksForGradientsAndNames(t *testing.T) {
+	gchalkInstance := gchalk.New()
+	gchalkInstance.SetLevel(gchalk.LevelAnsi256)
+	styles := &Registry{gchalkInstance: gchalkInstance}
+
+	styles.AddCustomColorWithFallbacks("$gradient", CustomColor{Truecolor: "linear-gradient(#f00, #00f)", Ansi256: "39", Ansi: "blue"})
+	styles.AddCustomColorWithFallbacks("$named", CustomColor{Truecolor: "red", Ansi256: "196"})
+
+	// Fallbacks should be used as a solid color in place of the gradient.
+	style, err := styles.Get("bg:$gradient")
+	assert.NoError(t, err)
+	assert.Equal(t, "\u001b[48;5;39mtest\u001b[49m", style.Apply("test"))
+
+	style, err = styles.Get("$named")
+	assert.NoError(t, err)
+	assert.Equal(t, "\u001b[38;5;196mtest\u001b[39m", style.Apply("test"))
+
+	// fg:auto should pick a single foreground for the fallback background.
+	style, err = styles.Get("fg:auto bg:$gradient")
+	assert.NoError(t, err)
+	assert.Equal(t, "\u001b[48;5;39m\u001b[38;5;16mtest\u001b[39m\u001b[49m", style.Apply("test"))
+
+	gchalkInstance = gchalk.New()
+	gchalkInstance.SetLevel(gchalk.LevelBasic)
+	styles = &Registry{gchalkInstance: gchalkInstance}
+	styles.AddCustomColorWithFallbacks("$gradient", CustomColor{Truecolor: "linear-gradient(#f00, #00f)", Ansi256: "39", Ansi: "blue"})
+	styles.AddCustomColorWithFallbacks("$named", CustomColor{Truecolor: "red", Ansi256: "196"})
+
+	style, err = styles.Get("$gradient")
+	assert.NoError(t, err)
+	assert.Equal(t, "\u001b[34mtest\u001b[39m", style.Apply("test"))
+
+	// No basic fallback, so the ANSI name is used.
+	style, err = styles.Get("$named")
+	assert.NoError(t, err)
+	assert.Equal(t, "\u001b[31mtest\u001b[39m", style.Apply("test"))
+}
+
+func TestValidateFallbacks(t *testing.T) {
+	assert.NoError(t, CustomColor{Truecolor: "#fff", Ansi256: "255", Ansi: "white"}.ValidateFallbacks())
+	assert.EqualError(t, CustomColor{Truecolor: "#fff", Ansi256: "256"}.ValidateFallbacks(), `invalid ansi256 color "256"`)
+	assert.EqualError(t, CustomColor{Truecolor: "#fff", Ansi: "banana"}.ValidateFallbacks(), `invalid ansi color "banana"`)
+}
+
+func TestAutoFg(t *testing.T) {
+	defer gchalk.SetLevel(gchalk.GetLevel())
+	gchalk.SetLevel(gchalk.LevelAnsi16m)
+
+	styles := testStyleRegistry()
+	styles.AddCustomColor("$dark", "#333")
+	styles.AddCustomColor("$light", "#eee")
+
+	// Solid backgrounds should pick the foreground color up front.
+	style, err := styles.Get("fg:auto bg:#fff")
+	assert.NoError(t, err)
+	assert.Equal(t, "\u001b[48;2;255;255;255m\u001b[38;2;0;0;0mtest\u001b[39m\u001b[49m", style.Apply("test"))
+
+	style, err = styles.Get("bg:blue fg:auto")
+	assert.NoError(t, err)
+	assert.Equal(t, "\u001b[44m\u001b[38;2;255;255;255mtest\u001b[39m\u001b[49m", style.Apply("test"))
+
+	style, err = styles.Get("fg:auto(apca, $dark, $light) bg:#fd0")
+	assert.NoError(t, err)
+	assert.Equal(t, "\u001b[48;2;255;221;0m\u001b[38;2;51;51;51mtest\u001b[39m\u001b[49m", style.Apply("test"))
+
+	// Gradient backgrounds should pick a color for each character.
+	style, err = styles.Get("fg:auto bg:linear-gradient(#000, #fff)")
+	assert.NoError(t, err)
+	result, first, last := style.ApplyGetColors("ABCD")
+	assert.Equal(t,
+		"\u001b[38;2;255;255;255m\u001b[48;2;31;31;31mA\u001b[48;2;95;95;95mB"+
+			"\u001b[38;2;0;0;0m\u001b[48;2;159;159;159mC\u001b[48;2;223;223;223mD\u001b[39m\u001b[49m",
+		result,
+	)
+	assert.Equal(t, CharacterColors{FG: "#ffffff", BG: "bg:#000000"}, first)
+	assert.Equal(t, CharacterColors{FG: "#000000", BG: "bg:#ffffff"}, last)
+
+	// Without a background, should use the background already in the text.
+	bgStyle, err := styles.Get("bg:linear-gradient(#000, #fff)")
+	assert.NoError(t, err)
+	style, err = styles.Get("fg:auto")
+	assert.NoError(t, err)
+	assert.Equal(t,
+		"\u001b[48;2;31;31;31m\u001b[38;2;255;255;255mA\u001b[48;2;95;95;95mB"+
+			"\u001b[48;2;159;159;159m\u001b[38;2;0;0;0mC\u001b[48;2;223;223;223mD\u001b[49m\u001b[39m",
+		style.Apply(bgStyle.Apply("ABCD")),
+	)
+
+	// Or the terminal's background, if the text has no background.
+	assert.Equal(t, "\u001b[38;2;255;255;255mtest\u001b[39m", style.Apply("test"))
+	styles = testStyleRegistry()
+	styles.Background = color.RGBA{255, 255, 255, 255}
+	style, err = styles.Get("fg:auto")
+	assert.NoError(t, err)
+	assert.Equal(t, "\u001b[38;2;0;0;0mtest\u001b[39m", style.Apply("test"))
+
+	// A later foreground color should replace fg:auto.
+	style, err = styles.Get("fg:auto red bg:#fff")
+	assert.NoError(t, err)
+	assert.Equal(t, "\u001b[48;2;255;255;255m\u001b[31mtest\u001b[39m\u001b[49m", style.Apply("test"))
+
+	_, err = styles.Get("fg:auto(banana)")
+	assert.EqualError(t, err, `error compiling style "fg:auto(banana)": invalid fg:auto argument "banana"`)
+
+	_, err = styles.Get("fg:auto(linear-gradient(#000, #fff))")
+	assert.EqualError(t, err,
+		`error compiling style "fg:auto(linear-gradient(#000, #fff))": invalid fg:auto argument "linear-gradient(#000, #fff)"`,
+	)
+
+	_, err = styles.Get("bg:fg:auto")
+	assert.EqualError(t, err, `error compiling style "bg:fg:auto": unknown style "bg:fg:auto"`)
+}
+
+func TestAutoFgFallbacks(t *testing.T) {
+	gchalkInstance := gchalk.New()
+	gchalkInstance.SetLevel(gchalk.LevelBasic)
+	styles := &Registry{gchalkInstance: gchalkInstance}
+
+	// The fallback color is what will be shown, so contrast should be
+	// measured against it.
+	styles.AddCustomColorWithFallbacks("$bg", CustomColor{Truecolor: "#004040", Ansi: "brightWhite"})
+	style, err := styles.Get("fg:auto bg:$bg")
+	assert.NoError(t, err)
+	assert.Equal(t, "\u001b[107m\u001b[30mtest\u001b[39m\u001b[49m", style.Apply("test"))
+}
@@ -52,9 +52,16 @@ func TxtFuncMap(styles *Registry) template.FuncMap {
 		return styled
 	}
 
+	// autoFg gives text a foreground color which contrasts with its
+	// background.
+	autoFg := func(text interface{}) string {
+		return style(autoFgPrefix, text)
+	}
+
 	return template.FuncMap{
 		"style":   style,
 		"fgColor": fgColor,
 		"bgColor": bgColor,
+		"autoFg":  autoFg,
 	}
 }
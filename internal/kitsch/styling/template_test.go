@@ -64,3 +64,13 @@ func TestBgColorFunc(t *testing.T) {
 	tmpl3 := testCompileTemplate("test", `{{ . | bgColor "bg:red"}}`)
 	assert.Equal(t, "\u001B[41mfoo\u001B[49m", testTemplateToString(tmpl3, "foo"))
 }
+
+func TestAutoFgFunc(t *testing.T) {
+	gchalk.SetLevel(gchalk.LevelAnsi16m)
+
+	tmpl := testCompileTemplate("test", `{{ . | bgColor "#fff" | autoFg }}`)
+	assert.Equal(t,
+		"\u001B[48;2;255;255;255m\u001B[38;2;0;0;0mfoo\u001B[49m\u001B[39m",
+		testTemplateToString(tmpl, "foo"),
+	)
+}
@@ -0,0 +1,150 @@
+// Package theme works out whether the terminal has a light or a dark
+// background.
+package theme
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/jwalton/kitsch/internal/colortools"
+)
+
+// Theme is a terminal theme.
+type Theme string
+
+const (
+	// Unknown means we could not work out the terminal's theme.
+	Unknown Theme = ""
+	// Light is a theme with a light background.
+	Light Theme = "light"
+	// Dark is a theme with a dark background.
+	Dark Theme = "dark"
+)
+
+// EnvVar is the environment variable that can be used to set the theme
+// explicitly.
+const EnvVar = "KITSCH_THEME"
+
+// Detect works out the terminal's theme.  In order of preference, this uses:
+//
+// • The KITSCH_THEME environment variable, if it is set to "light" or "dark".
+//
+// • `background`, the terminal's background color.  This is usually the
+// response to an OSC 11 query (e.g. "rgb:1e1e/1e1e/1e1e") made by the shell
+// integration scripts, but can be any color kitsch understands.
+//
+// • The COLORFGBG environment variable, which some terminals set.
+//
+// `getenv` is used to read environment variables.  If the theme can't be
+// worked out, this returns Unknown.
+func Detect(background string, getenv func(string) string) Theme {
+	if theme := Parse(getenv(EnvVar)); theme != Unknown {
+		return theme
+	}
+
+	if theme := FromBackgroundColor(background); theme != Unknown {
+		return theme
+	}
+
+	return FromColorFgBg(getenv("COLORFGBG"))
+}
+
+// Parse converts a string like "light" or "dark" into a theme.  Returns
+// Unknown for any other string.
+func Parse(str string) Theme {
+	switch strings.ToLower(strings.TrimSpace(str)) {
+	case "light":
+		return Light
+	case "dark":
+		return Dark
+	default:
+		return Unknown
+	}
+}
+
+// FromBackgroundColor returns the theme for a terminal with the given
+// background color.  `background` can be in any format understood by
+// `ParseBackgroundColor()`.
+func FromBackgroundColor(background string) Theme {
+	c, ok := ParseBackgroundColor(background)
+	if !ok {
+		return Unknown
+	}
+
+	// Use the perceptual lightness of the color, so that (for example) a
+	// saturated blue background counts as dark.
+	if colortools.ToOKLab(c).L > 0.5 {
+		return Light
+	}
+	return Dark
+}
+
+// ParseBackgroundColor parses the terminal's background color.  `background`
+// can be in the "rgb:RRRR/GGGG/BBBB" format returned by an OSC 11 query, or any
+// color understood by `colortools.ParseColor()`.  Returns false if
+// `background` is empty or can't be parsed.
+func ParseBackgroundColor(background string) (color.RGBA, bool) {
+	background = strings.TrimSpace(background)
+	if background == "" {
+		return color.RGBA{}, false
+	}
+
+	var c color.RGBA
+	var err error
+	if strings.HasPrefix(background, "rgb:") {
+		c, err = parseXColor(background[len("rgb:"):])
+	} else {
+		c, err = colortools.ParseColor(background)
+	}
+	if err != nil {
+		return color.RGBA{}, false
+	}
+	return c, true
+}
+
+// parseXColor parses the "RRRR/GGGG/BBBB" part of an X11 color
+// specification, where each channel is one to four hex digits.
+func parseXColor(str string) (color.RGBA, error) {
+	parts := strings.Split(str, "/")
+	if len(parts) != 3 {
+		return color.RGBA{}, fmt.Errorf("invalid X11 color %q", str)
+	}
+
+	var channels [3]uint8
+	for index, part := range parts {
+		if len(part) < 1 || len(part) > 4 {
+			return color.RGBA{}, fmt.Errorf("invalid X11 color %q", str)
+		}
+		value, err := strconv.ParseUint(part, 16, 16)
+		if err != nil {
+			return color.RGBA{}, fmt.Errorf("invalid X11 color %q", str)
+		}
+		max := uint64(1)<<(4*len(part)) - 1
+		channels[index] = uint8(value * 255 / max)
+	}
+
+	return color.RGBA{R: channels[0], G: channels[1], B: channels[2], A: 255}, nil
+}
+
+// FromColorFgBg returns the theme for the given value of the COLORFGBG
+// environment variable.  COLORFGBG is of the form "fg;bg" or
+// "fg;default;bg", where "bg" is the ANSI color number of the background.
+func FromColorFgBg(colorFgBg string) Theme {
+	if colorFgBg == "" {
+		return Unknown
+	}
+
+	parts := strings.Split(colorFgBg, ";")
+	bg, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil || bg < 0 || bg > 15 {
+		return Unknown
+	}
+
+	// White (7) and the bright colors other than bright black (8) are light.
+	if bg == 7 || bg > 8 {
+		return Light
+	}
+	return Dark
+}
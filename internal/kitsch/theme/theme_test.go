@@ -0,0 +1,69 @@
+package theme
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromBackgroundColor(t *testing.T) {
+	assert.Equal(t, Dark, FromBackgroundColor("rgb:1e1e/1e1e/1e1e"))
+	assert.Equal(t, Light, FromBackgroundColor("rgb:ffff/ffff/ffff"))
+	assert.Equal(t, Light, FromBackgroundColor("rgb:fd/f6/e3"))
+	assert.Equal(t, Dark, FromBackgroundColor("rgb:0/0/8"))
+	assert.Equal(t, Dark, FromBackgroundColor("#002b36"))
+	assert.Equal(t, Light, FromBackgroundColor("#fdf6e3"))
+	assert.Equal(t, Dark, FromBackgroundColor("navy"))
+
+	assert.Equal(t, Unknown, FromBackgroundColor(""))
+	assert.Equal(t, Unknown, FromBackgroundColor("rgb:ffff/ffff"))
+	assert.Equal(t, Unknown, FromBackgroundColor("rgb:fffff/0/0"))
+	assert.Equal(t, Unknown, FromBackgroundColor("rgb:zz/0/0"))
+	assert.Equal(t, Unknown, FromBackgroundColor("banana"))
+}
+
+func TestParseBackgroundColor(t *testing.T) {
+	c, ok := ParseBackgroundColor("rgb:fdfd/f6f6/e3e3")
+	assert.True(t, ok)
+	assert.Equal(t, color.RGBA{R: 0xfd, G: 0xf6, B: 0xe3, A: 255}, c)
+
+	c, ok = ParseBackgroundColor(" #002b36 ")
+	assert.True(t, ok)
+	assert.Equal(t, color.RGBA{R: 0x00, G: 0x2b, B: 0x36, A: 255}, c)
+
+	_, ok = ParseBackgroundColor("")
+	assert.False(t, ok)
+	_, ok = ParseBackgroundColor("rgb:ffff/ffff")
+	assert.False(t, ok)
+}
+
+func TestFromColorFgBg(t *testing.T) {
+	assert.Equal(t, Dark, FromColorFgBg("15;0"))
+	assert.Equal(t, Light, FromColorFgBg("0;15"))
+	assert.Equal(t, Light, FromColorFgBg("0;default;7"))
+	assert.Equal(t, Dark, FromColorFgBg("7;8"))
+	assert.Equal(t, Unknown, FromColorFgBg("0;default"))
+	assert.Equal(t, Unknown, FromColorFgBg(""))
+}
+
+func TestDetect(t *testing.T) {
+	env := map[string]string{}
+	getenv := func(name string) string { return env[name] }
+
+	assert.Equal(t, Unknown, Detect("", getenv))
+
+	env["COLORFGBG"] = "0;15"
+	assert.Equal(t, Light, Detect("", getenv))
+
+	// The background color should take precedence over COLORFGBG.
+	assert.Equal(t, Dark, Detect("rgb:0000/0000/0000", getenv))
+
+	// KITSCH_THEME should take precedence over everything.
+	env["KITSCH_THEME"] = "Dark"
+	assert.Equal(t, Dark, Detect("rgb:ffff/ffff/ffff", getenv))
+
+	// An invalid KITSCH_THEME should be ignored.
+	env["KITSCH_THEME"] = "auto"
+	assert.Equal(t, Light, Detect("", getenv))
+}
@@ -0,0 +1,52 @@
+package shellprompt
+
+import (
+	"strconv"
+
+	"github.com/jwalton/kitsch/internal/ansiwidth"
+)
+
+// SupportsRightPrompt returns true if the given shell can natively display a
+// right prompt (e.g. RPROMPT in zsh).  For other shells, the right prompt must
+// be added to the left prompt with AddRightPrompt.
+func SupportsRightPrompt(shell string) bool {
+	return shell == "zsh" || shell == "fish"
+}
+
+// AddRightPrompt will emulate a right prompt for shells that do not support
+// one natively, by drawing the right prompt right-aligned on the first line
+// of the prompt.  `prompt` should be a prompt that has already had zero-width
+// character escapes added to it by AddZeroWidthCharacterEscapes.
+//
+// The right prompt is drawn by saving the cursor position, moving to the
+// correct column, drawing the right prompt, and then restoring the cursor,
+// so the whole right prompt is treated as zero-width by the shell.
+func AddRightPrompt(shell string, prompt string, rightPrompt string, terminalWidth int) string {
+	if rightPrompt == "" {
+		return prompt
+	}
+
+	var start, end string
+	switch shell {
+	case "bash":
+		start, end = "\\[", "\\]"
+	case "powershell":
+		start, end = "", ""
+	default:
+		return prompt
+	}
+
+	column := terminalWidth - ansiwidth.StringWidth(rightPrompt) + 1
+	if column < 1 {
+		// Not enough room to show the right prompt.
+		return prompt
+	}
+
+	return start +
+		"\x1b7" + // Save cursor position.
+		"\x1b[" + strconv.Itoa(column) + "G" + // Move to column.
+		rightPrompt +
+		"\x1b8" + // Restore cursor position.
+		end +
+		prompt
+}
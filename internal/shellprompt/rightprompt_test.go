@@ -0,0 +1,31 @@
+package shellprompt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddRightPrompt(t *testing.T) {
+	assert.Equal(t,
+		"\\[\x1b7\x1b[16Gright\x1b8\\]$ ",
+		AddRightPrompt("bash", "$ ", "right", 20),
+	)
+
+	assert.Equal(t,
+		"\x1b7\x1b[16Gright\x1b8$ ",
+		AddRightPrompt("powershell", "$ ", "right", 20),
+	)
+
+	// Should ignore escape codes when working out the width.
+	assert.Equal(t,
+		"\x1b7\x1b[16G\x1b[31mright\x1b[39m\x1b8$ ",
+		AddRightPrompt("powershell", "$ ", "\x1b[31mright\x1b[39m", 20),
+	)
+
+	// Should do nothing if the right prompt doesn't fit.
+	assert.Equal(t, "$ ", AddRightPrompt("bash", "$ ", "right", 3))
+
+	// Should do nothing for shells that have a right prompt.
+	assert.Equal(t, "$ ", AddRightPrompt("zsh", "$ ", "right", 20))
+}
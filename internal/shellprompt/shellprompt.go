@@ -16,6 +16,10 @@ func AddZeroWidthCharacterEscapes(shell string, prompt string) string {
 	case "bash":
 		// https://www.gnu.org/software/bash/manual/html_node/Controlling-the-Prompt.html#Controlling-the-Prompt
 		return addZeroWidthCharacterEscapes(prompt, "\\[", "\\]")
+	case "fish":
+		// fish works out the width of the prompt on its own, and knows how to
+		// skip over escape sequences, so no escapes are required.
+		return prompt
 	}
 
 	return prompt
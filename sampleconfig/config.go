@@ -9,3 +9,21 @@ import (
 // DefaultConfig is the default configuration, as YAML data.
 //go:embed default.yaml
 var DefaultConfig []byte
+
+//go:embed powerline.yaml
+var powerlineConfig []byte
+
+//go:embed powerline2.yaml
+var powerline2Config []byte
+
+//go:embed starship.yaml
+var starshipConfig []byte
+
+// Presets is a map of preset names to sample configurations, as YAML data.
+// A configuration file can extend a preset with "extends: preset:<name>".
+var Presets = map[string][]byte{
+	"default":    DefaultConfig,
+	"powerline":  powerlineConfig,
+	"powerline2": powerline2Config,
+	"starship":   starshipConfig,
+}